@@ -29,6 +29,17 @@ func (di *DatasInside) AddPoint(zoneID string, point DataPoint) {
 	*di = append(*di, newDataInside)
 }
 
+// FilterByZone returns items with allowed zoneID.
+func (di DatasInside) FilterByZone(allow func(zoneID string) bool) DatasInside {
+	res := make(DatasInside, 0, len(di))
+	for _, item := range di {
+		if allow(item.ZoneID) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
 type DataPoint struct {
 	Time  time.Time `json:"time"`
 	Value int32     `json:"value"`
@@ -91,6 +102,17 @@ func (di *StoresDataQueue) AddPoint(storeID string, point QueueDataPoint) {
 	*di = append(*di, newStoreDataQueue)
 }
 
+// FilterByStore returns items with allowed storeID.
+func (di StoresDataQueue) FilterByStore(allow func(storeID string) bool) StoresDataQueue {
+	res := make(StoresDataQueue, 0, len(di))
+	for _, item := range di {
+		if allow(item.StoreID) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
 type ZoneDataQueue struct {
 	ZoneID string          `json:"zone_id"`
 	Points QueueDataPoints `json:"points"`
@@ -115,6 +137,17 @@ func (di *ZonesDataQueue) AddPoint(zoneID string, point QueueDataPoint) {
 	*di = append(*di, newZoneDataQueue)
 }
 
+// FilterByZone returns items with allowed zoneID.
+func (di ZonesDataQueue) FilterByZone(allow func(zoneID string) bool) ZonesDataQueue {
+	res := make(ZonesDataQueue, 0, len(di))
+	for _, item := range di {
+		if allow(item.ZoneID) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
 // Attendance
 
 // AttendanceDataPoint simple attendance data unit
@@ -153,6 +186,17 @@ func (di *ZonesAttendance) AddPoint(zoneID string, point AttendanceDataPoint) {
 	*di = append(*di, newDataPoint)
 }
 
+// FilterByZone returns items with allowed zoneID.
+func (di ZonesAttendance) FilterByZone(allow func(zoneID string) bool) ZonesAttendance {
+	res := make(ZonesAttendance, 0, len(di))
+	for _, item := range di {
+		if allow(item.ZoneID) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
 type StoreAttendance struct {
 	StoreID string               `json:"store_id"`
 	Points  AttendanceDataPoints `json:"points"`
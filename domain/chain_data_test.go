@@ -103,3 +103,30 @@ func TestAddPoint(t *testing.T) {
 		t.Errorf("expected dss after add\n%+v\nbut got:\n%+v\n", expDssN, dss)
 	}
 }
+
+func TestFilterByStore(t *testing.T) {
+	sdq := StoresDataQueue{
+		StoreDataQueue{StoreID: "80079091"},
+		StoreDataQueue{StoreID: "82949216"},
+		StoreDataQueue{StoreID: "147298805"},
+	}
+	allowed := map[string]bool{"82949216": true, "147298805": true}
+	got := sdq.FilterByStore(func(storeID string) bool { return allowed[storeID] })
+	exp := StoresDataQueue{sdq[1], sdq[2]}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected filtered\n%+v\nbut got:\n%+v\n", exp, got)
+	}
+	got = sdq.FilterByStore(func(string) bool { return false })
+	if len(got) != 0 {
+		t.Errorf("expected empty, but got:\n%+v\n", got)
+	}
+}
+
+func TestFilterByZone(t *testing.T) {
+	ds := DatasInside{di1, di2, di3}
+	got := ds.FilterByZone(func(zoneID string) bool { return zoneID != "222" })
+	exp := DatasInside{di1, di3}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected filtered\n%+v\nbut got:\n%+v\n", exp, got)
+	}
+}
@@ -844,7 +844,7 @@ func (chs Screenshots) SetZeroValue(fields []string) {
 type eventFields []string
 
 var (
-	allEventFields eventFields = eventFields{"id", "key", "event_time", "kind", "message", "severity", "layout_id", "store_id", "source", "creator", "created_at"}
+	allEventFields eventFields = eventFields{"id", "key", "event_time", "kind", "message", "severity", "layout_id", "store_id", "source", "creator", "created_at", "state"}
 )
 
 func (fs eventFields) allFields() []string {
@@ -897,6 +897,8 @@ func (ch *Event) setZeroByTagName(name string) {
 		ch.Creator = ""
 	case "created_at":
 		ch.CreatedAt = nil
+	case "state":
+		ch.State = nil
 	}
 }
 
@@ -42,3 +42,14 @@ type Track struct {
 
 //easyjson:json
 type Tracks []Track
+
+// FilterByStore returns items with allowed storeID.
+func (ts Tracks) FilterByStore(allow func(storeID string) bool) Tracks {
+	res := make(Tracks, 0, len(ts))
+	for _, item := range ts {
+		if allow(item.StoreID) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
@@ -14,6 +14,43 @@ type Event struct {
 	Source    *Source    `json:"source,omitempty"`
 	Creator   string     `json:"creator,omitempty"`
 	CreatedAt *time.Time `json:"created_at,omitempty"`
+	// State lifecycle of the event, nil for the event without transitions
+	State *EventState `json:"state,omitempty"`
+	// Change transition of the state, set only for messages of the stream about state change
+	Change *EventTransition `json:"change,omitempty"`
+}
+
+// statuses of the event lifecycle
+const (
+	EventStatusNew          string = "new"
+	EventStatusAcknowledged string = "acknowledged"
+	EventStatusAssigned     string = "assigned"
+	EventStatusResolved     string = "resolved"
+)
+
+// EventState lifecycle state of the event with history of transitions
+type EventState struct {
+	Status    string            `json:"status"`
+	Assignee  string            `json:"assignee,omitempty"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	History   []EventTransition `json:"history,omitempty"`
+}
+
+// EventTransition action with the event made by actor: create, acknowledge, assign, comment, resolve
+type EventTransition struct {
+	Action   string    `json:"action"`
+	Actor    string    `json:"actor"`
+	Time     time.Time `json:"time"`
+	Assignee string    `json:"assignee,omitempty"`
+	Comment  string    `json:"comment,omitempty"`
+}
+
+// Status returns status of the event lifecycle, new for the event without transitions.
+func (e Event) Status() string {
+	if e.State == nil {
+		return EventStatusNew
+	}
+	return e.State.Status
 }
 
 type Source struct {
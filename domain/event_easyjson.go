@@ -262,7 +262,222 @@ func (v *Events) UnmarshalJSON(data []byte) error {
 func (v *Events) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain2(l, v)
 }
-func easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(in *jlexer.Lexer, out *Event) {
+func easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(in *jlexer.Lexer, out *EventTransition) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "action":
+			out.Action = string(in.String())
+		case "actor":
+			out.Actor = string(in.String())
+		case "time":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Time).UnmarshalJSON(data))
+			}
+		case "assignee":
+			out.Assignee = string(in.String())
+		case "comment":
+			out.Comment = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonF642ad3eEncodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(out *jwriter.Writer, in EventTransition) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"action\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Action))
+	}
+	{
+		const prefix string = ",\"actor\":"
+		out.RawString(prefix)
+		out.String(string(in.Actor))
+	}
+	{
+		const prefix string = ",\"time\":"
+		out.RawString(prefix)
+		out.Raw((in.Time).MarshalJSON())
+	}
+	if in.Assignee != "" {
+		const prefix string = ",\"assignee\":"
+		out.RawString(prefix)
+		out.String(string(in.Assignee))
+	}
+	if in.Comment != "" {
+		const prefix string = ",\"comment\":"
+		out.RawString(prefix)
+		out.String(string(in.Comment))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v EventTransition) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonF642ad3eEncodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v EventTransition) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonF642ad3eEncodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *EventTransition) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *EventTransition) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(l, v)
+}
+func easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain4(in *jlexer.Lexer, out *EventState) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "status":
+			out.Status = string(in.String())
+		case "assignee":
+			out.Assignee = string(in.String())
+		case "updated_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.UpdatedAt).UnmarshalJSON(data))
+			}
+		case "history":
+			if in.IsNull() {
+				in.Skip()
+				out.History = nil
+			} else {
+				in.Delim('[')
+				if out.History == nil {
+					if !in.IsDelim(']') {
+						out.History = make([]EventTransition, 0, 0)
+					} else {
+						out.History = []EventTransition{}
+					}
+				} else {
+					out.History = (out.History)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v7 EventTransition
+					(v7).UnmarshalEasyJSON(in)
+					out.History = append(out.History, v7)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonF642ad3eEncodeGitCountmaxRuCountmaxLayoutconfigApiDomain4(out *jwriter.Writer, in EventState) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Status))
+	}
+	if in.Assignee != "" {
+		const prefix string = ",\"assignee\":"
+		out.RawString(prefix)
+		out.String(string(in.Assignee))
+	}
+	{
+		const prefix string = ",\"updated_at\":"
+		out.RawString(prefix)
+		out.Raw((in.UpdatedAt).MarshalJSON())
+	}
+	if len(in.History) != 0 {
+		const prefix string = ",\"history\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v8, v9 := range in.History {
+				if v8 > 0 {
+					out.RawByte(',')
+				}
+				(v9).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v EventState) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonF642ad3eEncodeGitCountmaxRuCountmaxLayoutconfigApiDomain4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v EventState) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonF642ad3eEncodeGitCountmaxRuCountmaxLayoutconfigApiDomain4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *EventState) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *EventState) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain4(l, v)
+}
+func easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain5(in *jlexer.Lexer, out *Event) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -323,6 +538,26 @@ func easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(in *jlexe
 					in.AddError((*out.CreatedAt).UnmarshalJSON(data))
 				}
 			}
+		case "state":
+			if in.IsNull() {
+				in.Skip()
+				out.State = nil
+			} else {
+				if out.State == nil {
+					out.State = new(EventState)
+				}
+				(*out.State).UnmarshalEasyJSON(in)
+			}
+		case "change":
+			if in.IsNull() {
+				in.Skip()
+				out.Change = nil
+			} else {
+				if out.Change == nil {
+					out.Change = new(EventTransition)
+				}
+				(*out.Change).UnmarshalEasyJSON(in)
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -333,7 +568,7 @@ func easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(in *jlexe
 		in.Consumed()
 	}
 }
-func easyjsonF642ad3eEncodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(out *jwriter.Writer, in Event) {
+func easyjsonF642ad3eEncodeGitCountmaxRuCountmaxLayoutconfigApiDomain5(out *jwriter.Writer, in Event) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -392,29 +627,39 @@ func easyjsonF642ad3eEncodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(out *jwri
 		out.RawString(prefix)
 		out.Raw((*in.CreatedAt).MarshalJSON())
 	}
+	if in.State != nil {
+		const prefix string = ",\"state\":"
+		out.RawString(prefix)
+		(*in.State).MarshalEasyJSON(out)
+	}
+	if in.Change != nil {
+		const prefix string = ",\"change\":"
+		out.RawString(prefix)
+		(*in.Change).MarshalEasyJSON(out)
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v Event) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonF642ad3eEncodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(&w, v)
+	easyjsonF642ad3eEncodeGitCountmaxRuCountmaxLayoutconfigApiDomain5(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Event) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonF642ad3eEncodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(w, v)
+	easyjsonF642ad3eEncodeGitCountmaxRuCountmaxLayoutconfigApiDomain5(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Event) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(&r, v)
+	easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain5(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Event) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain3(l, v)
+	easyjsonF642ad3eDecodeGitCountmaxRuCountmaxLayoutconfigApiDomain5(l, v)
 }
@@ -53,3 +53,14 @@ func (pqs *PredictionsQueue) AddPoint(storeID string, fillInterval time.Duration
 	}
 	*pqs = append(*pqs, mewPQ)
 }
+
+// FilterByStore returns items with allowed storeID.
+func (pqs PredictionsQueue) FilterByStore(allow func(storeID string) bool) PredictionsQueue {
+	res := make(PredictionsQueue, 0, len(pqs))
+	for _, pq := range pqs {
+		if allow(pq.StoreID) {
+			res = append(res, pq)
+		}
+	}
+	return res
+}
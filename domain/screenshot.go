@@ -44,6 +44,17 @@ func (li *LayoutInfo) SetParamByName(name, value string) {
 //easyjson:json
 type Screenshots []Screenshot
 
+// FilterByStore returns items with allowed storeID.
+func (ss Screenshots) FilterByStore(allow func(storeID string) bool) Screenshots {
+	res := make(Screenshots, 0, len(ss))
+	for _, item := range ss {
+		if allow(item.StoreID) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
 // ParamScreenUpd properties for update state of screenshot
 type ParamScreenUpd struct {
 	DeviceID         string    `json:"device_id"`
@@ -15,3 +15,14 @@ type ZoneDataEvaluation struct {
 
 //easyjson:json
 type ZoneDataEvaluations []ZoneDataEvaluation
+
+// FilterByStore returns items with allowed storeID.
+func (zdes ZoneDataEvaluations) FilterByStore(allow func(storeID string) bool) ZoneDataEvaluations {
+	res := make(ZoneDataEvaluations, 0, len(zdes))
+	for _, item := range zdes {
+		if allow(item.StoreID) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
@@ -19,3 +19,14 @@ type ZoneState struct {
 
 //easyjson:json
 type ZoneStates []ZoneState
+
+// FilterByStore returns items with allowed storeID.
+func (zss ZoneStates) FilterByStore(allow func(storeID string) bool) ZoneStates {
+	res := make(ZoneStates, 0, len(zss))
+	for _, item := range zss {
+		if allow(item.StoreID) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
@@ -53,6 +53,15 @@ func (s *Server) setConfig() {
 			configName = strings.ReplaceAll(configName, path.Ext(configName), "")
 		}
 	})
+	config, err := loadConfig()
+	if err != nil { // Handle errors reading the config file
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
+	s.config = config
+}
+
+// loadConfig reads config file found by flags, values are overridden by environment.
+func loadConfig() (*viper.Viper, error) {
 	config := viper.New()
 	config.SetConfigType(configFormat)
 	config.AddConfigPath(configPath)
@@ -60,12 +69,11 @@ func (s *Server) setConfig() {
 	config.SetEnvPrefix(envPrefix)
 	config.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
-	err := config.ReadInConfig() // Find and read the config file
-	if err != nil {              // Handle errors reading the config file
-		panic(fmt.Errorf("fatal error config file: %s", err))
+	if err := config.ReadInConfig(); err != nil { // Find and read the config file
+		return nil, err
 	}
 	config.AutomaticEnv()
-	s.config = config
+	return config, nil
 }
 
 // consulRegister register self to cinsul server.
@@ -0,0 +1,190 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/commonapiclient"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/connmanager"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// hotKeys config keys applied on reload without restart, changes of the other keys are ignored.
+var hotKeys = []string{
+	"log.level",
+	"permissions.policy",
+	"devicemanager.aliases",
+	"httpd.allow_origins",
+	"countmax.ids",
+}
+
+// watchConfig reloads config on change of the config file and on SIGHUP.
+func (s *Server) watchConfig(ctx context.Context) {
+	debounce := s.config.GetDuration("reload.debounce")
+	trigger := make(chan string, 1)
+	notify := func(reason string) {
+		select {
+		case trigger <- reason:
+		default: // reload already planned
+		}
+	}
+	// separate instance, it is reread by viper on change of the file
+	watcher := viper.New()
+	watcher.SetConfigFile(s.config.ConfigFileUsed())
+	if err := watcher.ReadInConfig(); err != nil {
+		s.log.Errorf("watch config file %s failed, reload by SIGHUP only: %s", s.config.ConfigFileUsed(), err)
+	} else {
+		watcher.OnConfigChange(func(e fsnotify.Event) { notify("change of the " + e.Name) })
+		watcher.WatchConfig()
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	s.log.Infof("watch config %s, reload by change or SIGHUP", s.config.ConfigFileUsed())
+	go func() {
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				notify("SIGHUP")
+			case reason := <-trigger:
+				// editors save file by several writes
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(debounce):
+				}
+				select {
+				case <-trigger:
+				default:
+				}
+				if err := s.reloadConfig(ctx, reason); err != nil {
+					s.log.Errorf("config reload by %s rejected, %s", reason, err)
+				}
+			}
+		}
+	}()
+}
+
+// reloadConfig rereads config and applies hot keys, nothing is applied if config is invalid
+// or sync of the projects by countmax.ids failed, so the next reload retries it.
+func (s *Server) reloadConfig(ctx context.Context, reason string) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("read config error, %s", err)
+	}
+	if err := validateHotKeys(cfg); err != nil {
+		return err
+	}
+	prev := s.applied
+	changed := func(key string) bool {
+		return !reflect.DeepEqual(prev.Get(key), cfg.Get(key))
+	}
+	// everything which can fail is made before apply
+	var dm dmService
+	if s.dm != nil && changed("devicemanager.aliases") {
+		if dm, err = s.newDMRepo(cfg); err != nil {
+			return fmt.Errorf("device.manager connect to DB failed, %s", err)
+		}
+	}
+	s.log.Infof("config reload by %s", reason)
+	// projects are synced first, the rest isn't applied if sync failed
+	if changed("countmax.ids") {
+		added, removed, err := s.repoM.SyncProjects(ctx, cfg.GetString("countmax.ids"))
+		switch {
+		case err == connmanager.ErrNoCommonAPI:
+			s.log.Warnf("countmax.ids ignored, %s", err)
+		case err != nil:
+			if dm != nil {
+				closeDMRepo(dm)
+			}
+			return fmt.Errorf("sync countmax.ids failed, %s", err)
+		default:
+			s.log.Infof("countmax.ids applied, added projects %v, removed projects %v", added, removed)
+		}
+	}
+	if changed("log.level") {
+		level, _ := parseLevel(cfg.GetString("log.level"))
+		s.logLevel.SetLevel(level)
+		s.log.Infof("log level set to %s", level)
+	}
+	if changed("permissions.policy") {
+		s.perm.SetPolicy(policyByName(cfg.GetString("permissions.policy")))
+		s.log.Infof("default policy set to %s", cfg.GetString("permissions.policy"))
+	}
+	if changed("httpd.allow_origins") {
+		s.setOrigins(cfg.GetString("httpd.allow_origins"))
+		s.log.Infof("allowed origins set to %s", cfg.GetString("httpd.allow_origins"))
+	}
+	if dm != nil {
+		old := s.dm.swap(dm)
+		// requests in progress are finished by the previous repo
+		time.AfterFunc(cfg.GetDuration("devicemanager.timeout"), func() { closeDMRepo(old) })
+		s.log.Infof("device.manager aliases set to %v", cfg.GetStringMapStringSlice("devicemanager.aliases"))
+	}
+	// restart required keys are compared with the running config
+	if ignored := changedKeys(s.config, cfg); len(ignored) > 0 {
+		s.log.Warnf("config keys %s require restart, ignored", strings.Join(ignored, ", "))
+	}
+	s.applied = cfg
+	return nil
+}
+
+// validateHotKeys checks values of the hot keys.
+func validateHotKeys(cfg *viper.Viper) error {
+	if level := cfg.GetString("log.level"); level != "" {
+		if _, ok := parseLevel(level); !ok {
+			return fmt.Errorf("log.level %s unknown, allowed debug, info, warn, error", level)
+		}
+	}
+	if policy := cfg.GetString("permissions.policy"); policy != "" && policy != "allow" && policy != "deny" {
+		return fmt.Errorf("permissions.policy %s unknown, allowed allow, deny", policy)
+	}
+	if cfg.GetBool("devicemanager.isuse") {
+		if _, err := dmAliases(cfg); err != nil {
+			return err
+		}
+	}
+	if cfg.GetString("countmax.source") == "api" && len(commonapiclient.ParseIDs(cfg.GetString("countmax.ids"))) == 0 {
+		return fmt.Errorf("countmax.ids has no project ids")
+	}
+	return nil
+}
+
+// changedKeys returns sorted keys except hot ones which values differ.
+func changedKeys(prev, cfg *viper.Viper) []string {
+	keys := make(map[string]struct{})
+	for _, key := range append(prev.AllKeys(), cfg.AllKeys()...) {
+		keys[key] = struct{}{}
+	}
+	res := make([]string, 0)
+	for key := range keys {
+		if isHotKey(key) || reflect.DeepEqual(prev.Get(key), cfg.Get(key)) {
+			continue
+		}
+		res = append(res, key)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func isHotKey(key string) bool {
+	for _, hot := range hotKeys {
+		if key == hot || strings.HasPrefix(key, hot+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package infra
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const testReloadConfig = `
+httpd:
+  allow_origins: "*"
+  main:
+    host_port: ":8001"
+countmax:
+  source: api
+  ids: 1001,1002
+permissions:
+  policy: allow
+devicemanager:
+  isuse: true
+  aliases:
+    src: http://cdn.countmax.ru:9001
+    dest:
+      - https://s3.watcom.ru
+log:
+  level: info
+`
+
+func newTestConfig(t *testing.T, raw string) *viper.Viper {
+	t.Helper()
+	cfg := viper.New()
+	cfg.SetConfigType(configFormat)
+	if err := cfg.ReadConfig(strings.NewReader(raw)); err != nil {
+		t.Fatalf("read config error, %s", err)
+	}
+	return cfg
+}
+
+func TestValidateHotKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		wantErr bool
+	}{
+		{"valid", "", "", false},
+		{"level", "level: info", "level: verbose", true},
+		{"policy", "policy: allow", "policy: allow-all", true},
+		{"aliases", "src: http://cdn.countmax.ru:9001", "src: ''", true},
+		{"ids", "ids: 1001,1002", "ids: ','", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t, strings.Replace(testReloadConfig, tt.old, tt.new, 1))
+			if err := validateHotKeys(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("validateHotKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChangedKeys(t *testing.T) {
+	prev := newTestConfig(t, testReloadConfig)
+	raw := strings.NewReplacer(
+		`host_port: ":8001"`, `host_port: ":9001"`,
+		"ids: 1001,1002", "ids: 1001",
+		"level: info", "level: debug",
+		"- https://s3.watcom.ru", "- https://s3.countmax.ru",
+	).Replace(testReloadConfig)
+	got := changedKeys(prev, newTestConfig(t, raw+"events:\n  isuse: false\n"))
+	want := []string{"events.isuse", "httpd.main.host_port"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changedKeys() = %v, want %v", got, want)
+	}
+}
+
+func TestServer_allowOrigin(t *testing.T) {
+	s := &Server{}
+	if ok, _ := s.allowOrigin("https://layout.watcom.ru"); ok {
+		t.Error("origin must not be allowed before origins set")
+	}
+	s.setOrigins("https://*.watcom.ru, http://localhost:8080")
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://layout.watcom.ru", true},
+		{"http://localhost:8080", true},
+		{"https://layout.countmax.ru", false},
+		{"http://localhost:8081", false},
+	}
+	for _, tt := range tests {
+		if got, _ := s.allowOrigin(tt.origin); got != tt.want {
+			t.Errorf("allowOrigin(%s) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+	s.setOrigins("*")
+	if ok, _ := s.allowOrigin("https://layout.countmax.ru"); !ok {
+		t.Error("any origin must be allowed by *")
+	}
+}
@@ -0,0 +1,126 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/connmanager"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/repodecor/instrument"
+	"git.countmax.ru/countmax/layoutconfig.api/repos"
+
+	"github.com/spf13/viper"
+)
+
+// dmService repo of the device.manager database with health check.
+type dmService interface {
+	domain.IScreenRepo
+	connmanager.ExtServiceInterface
+}
+
+// swapScreenRepo device.manager repo which is replaced on reload of the aliases,
+// requests in progress are finished by the previous repo.
+type swapScreenRepo struct {
+	mu   sync.RWMutex
+	svc  dmService
+	repo domain.IScreenRepo // instrumented svc
+}
+
+var (
+	_ domain.IScreenRepo              = (*swapScreenRepo)(nil)
+	_ connmanager.ExtServiceInterface = (*swapScreenRepo)(nil)
+)
+
+func newSwapScreenRepo(svc dmService) *swapScreenRepo {
+	r := &swapScreenRepo{}
+	r.swap(svc)
+	return r
+}
+
+// swap replaces repo, returns previous one.
+func (r *swapScreenRepo) swap(svc dmService) dmService {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev := r.svc
+	r.svc, r.repo = svc, instrument.NewScreenRepo(svc, svc.Dest())
+	return prev
+}
+
+func (r *swapScreenRepo) current() (dmService, domain.IScreenRepo) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.svc, r.repo
+}
+
+// FindScreens implements domain.IScreenRepo.
+func (r *swapScreenRepo) FindScreens(layoutID, storeID, status string, deviceID []string, from, to time.Time,
+	limit, offset int64) (domain.Screenshots, int64, error) {
+	_, repo := r.current()
+	return repo.FindScreens(layoutID, storeID, status, deviceID, from, to, limit, offset)
+}
+
+// FindScreensAtTime implements domain.IScreenRepo.
+func (r *swapScreenRepo) FindScreensAtTime(layoutID, storeID string, deviceID []string, t time.Time) (domain.Screenshots, error) {
+	_, repo := r.current()
+	return repo.FindScreensAtTime(layoutID, storeID, deviceID, t)
+}
+
+// UpdStatusManyScreens implements domain.IScreenRepo.
+func (r *swapScreenRepo) UpdStatusManyScreens(p domain.ParamsScreenUpd) (int64, error) {
+	_, repo := r.current()
+	return repo.UpdStatusManyScreens(p)
+}
+
+// Dest implements connmanager.ExtServiceInterface.
+func (r *swapScreenRepo) Dest() string {
+	svc, _ := r.current()
+	return svc.Dest()
+}
+
+// Scope implements connmanager.ExtServiceInterface.
+func (r *swapScreenRepo) Scope() string {
+	svc, _ := r.current()
+	return svc.Scope()
+}
+
+// Health implements connmanager.ExtServiceInterface.
+func (r *swapScreenRepo) Health(ctx context.Context) error {
+	svc, _ := r.current()
+	return svc.Health(ctx)
+}
+
+// newDMRepo connects to the device.manager database with aliases of the screenshots hosts by config.
+func (s *Server) newDMRepo(cfg *viper.Viper) (dmService, error) {
+	aliases, err := dmAliases(cfg)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := repos.NewDMDB(aliases, cfg.GetString("devicemanager.url"), cfg.GetDuration("devicemanager.timeout"), s.log)
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// dmAliases returns hosts of the screenshots replaced by devicemanager.aliases.
+func dmAliases(cfg *viper.Viper) (map[string][]string, error) {
+	aliasesSRC := cfg.GetStringMapStringSlice("devicemanager.aliases")
+	if len(aliasesSRC["src"]) == 0 || aliasesSRC["src"][0] == "" {
+		return nil, fmt.Errorf("devicemanager.aliases.src is empty")
+	}
+	aliases := make(map[string][]string)
+	aliases[aliasesSRC["src"][0]] = aliasesSRC["dest"]
+	return aliases, nil
+}
+
+// closeDMRepo closes replaced repo.
+func closeDMRepo(svc dmService) {
+	switch c := svc.(type) {
+	case interface{ Close() error }:
+		_ = c.Close()
+	case interface{ Close() }:
+		c.Close()
+	}
+}
@@ -10,4 +10,6 @@ type RequestEvent struct {
 	LayoutID string     `json:"layout_id,omitempty"`
 	StoreID  string     `json:"store_id,omitempty"`
 	From     *time.Time `json:"from,omitempty"`
+	// SubscriptionID id of the durable subscription, resumed after the last acknowledged event
+	SubscriptionID string `json:"subscription_id,omitempty"`
 }
@@ -59,6 +59,8 @@ func easyjson75c74234DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra(in *jlexer.
 					in.AddError((*out.From).UnmarshalJSON(data))
 				}
 			}
+		case "subscription_id":
+			out.SubscriptionID = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -129,6 +131,16 @@ func easyjson75c74234EncodeGitCountmaxRuCountmaxLayoutconfigApiInfra(out *jwrite
 		}
 		out.Raw((*in.From).MarshalJSON())
 	}
+	if in.SubscriptionID != "" {
+		const prefix string = ",\"subscription_id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.SubscriptionID))
+	}
 	out.RawByte('}')
 }
 
@@ -0,0 +1,187 @@
+package infra
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/apikey"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	errAPIKeysDisabled error = errors.New("api keys disabled")
+	errAPIKeyScope     error = errors.New("layout out of scope of the api key")
+	errAPIKeyExpire    error = errors.New("expire_at must be in the future")
+	errAPIKeyAdmin     error = errors.New("admin permissions for api keys disabled, apikeys.allow_admin is false")
+)
+
+// NewAPIKey is parameter for new api key
+type NewAPIKey struct {
+	Title       string                 `json:"title"`
+	Permissions permission.Permissions `json:"permissions"`
+	Layouts     []string               `json:"layouts"`
+	ExpireAt    *time.Time             `json:"expire_at,omitempty"`
+}
+
+// CreatedAPIKey response with token of the new api key, token shown only once
+type CreatedAPIKey struct {
+	apikey.Key
+	Token string `json:"token"`
+}
+
+// APIKeysResponse http wrapper for the list of api keys
+type APIKeysResponse struct {
+	Data []apikey.Key `json:"data"`
+}
+
+// apiAdminCreateAPIKey docs
+// @Summary Create api key for machine-to-machine client
+// @Description create api key bound to permissions, layouts scope and expiration,
+// @Description token of the key returned only once, pass it in the header Authorization: Bearer <token>
+// @Description empty layouts or * - key allowed for all layouts, else layout_id must be passed in every request
+// @Description permissions are validated, permissions to <namespace>:data.counting:admin are allowed only with apikeys.allow_admin
+// @Description allowed only for users with permission to resource <namespace>:data.counting:admin
+// @Accept  json
+// @Produce  json
+// @Tags admin
+// @Param key body infra.NewAPIKey true "api key properties"
+// @Success 201 {object} infra.CreatedAPIKey
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/admin/apikeys [post]
+func (s *Server) apiAdminCreateAPIKey(c echo.Context) error {
+	if s.keys == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errAPIKeysDisabled))
+	}
+	nk := &NewAPIKey{}
+	if err := c.Bind(nk); err != nil {
+		s.log.Errorf("apiAdminCreateAPIKey, bad request error %v", err)
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	}
+	if nk.ExpireAt != nil && !nk.ExpireAt.After(time.Now()) {
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(errAPIKeyExpire))
+	}
+	if err := nk.Permissions.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	}
+	if nk.Permissions.GrantsAdmin() && !s.config.GetBool("apikeys.allow_admin") {
+		s.log.Warnf("api key with admin permissions rejected, created by %s", c.Request().Header.Get(permission.XUserID))
+		return c.JSON(http.StatusForbidden, ErrForbidden(errAPIKeyAdmin))
+	}
+	key, token, err := apikey.New(nk.Title, nk.Permissions, nk.Layouts, nk.ExpireAt,
+		c.Request().Header.Get(permission.XUserID))
+	if err != nil {
+		s.log.Errorf("apikey.New error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+	}
+	if err := s.keys.Add(c.Request().Context(), key); err != nil {
+		s.log.Errorf("keys.Add error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+	}
+	s.log.Infof("api key %s created by %s", key.ID, key.Creator)
+	return c.JSON(http.StatusCreated, CreatedAPIKey{Key: key.Public(), Token: token})
+}
+
+// apiAdminAPIKeys docs
+// @Summary Get api keys
+// @Description get all api keys without secrets
+// @Description allowed only for users with permission to resource <namespace>:data.counting:admin
+// @Produce  json
+// @Tags admin
+// @Success 200 {object} infra.APIKeysResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/admin/apikeys [get]
+func (s *Server) apiAdminAPIKeys(c echo.Context) error {
+	if s.keys == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errAPIKeysDisabled))
+	}
+	keys, err := s.keys.List(c.Request().Context())
+	if err != nil {
+		s.log.Errorf("keys.List error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+	}
+	res := APIKeysResponse{Data: make([]apikey.Key, 0, len(keys))}
+	for _, k := range keys {
+		res.Data = append(res.Data, k.Public())
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// apiAdminAPIKeyByID docs
+// @Summary Get api key by id
+// @Description get api key without secret
+// @Description allowed only for users with permission to resource <namespace>:data.counting:admin
+// @Produce  json
+// @Tags admin
+// @Param key_id path string true "api key id"
+// @Success 200 {object} apikey.Key
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/admin/apikeys/{key_id} [get]
+func (s *Server) apiAdminAPIKeyByID(c echo.Context) error {
+	if s.keys == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errAPIKeysDisabled))
+	}
+	keyID := c.Param("key_id")
+	if keyID == "" {
+		s.log.Errorf("bad request apiAdminAPIKeyByID, %v", errEmptyID)
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(errEmptyID))
+	}
+	key, err := s.keys.Get(c.Request().Context(), keyID)
+	if err != nil {
+		if errors.Is(err, apikey.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrNotFound(err))
+		}
+		s.log.Errorf("keys.Get error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+	}
+	return c.JSON(http.StatusOK, key.Public())
+}
+
+// apiAdminDeleteAPIKey docs
+// @Summary Revoke api key
+// @Description delete api key and flush cached permissions of the key
+// @Description allowed only for users with permission to resource <namespace>:data.counting:admin
+// @Produce  json
+// @Tags admin
+// @Param key_id path string true "api key id"
+// @Success 200 {object} infra.SuccessResponse
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/admin/apikeys/{key_id} [delete]
+func (s *Server) apiAdminDeleteAPIKey(c echo.Context) error {
+	if s.keys == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errAPIKeysDisabled))
+	}
+	keyID := c.Param("key_id")
+	if keyID == "" {
+		s.log.Errorf("bad request apiAdminDeleteAPIKey, %v", errEmptyID)
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(errEmptyID))
+	}
+	if err := s.keys.Del(c.Request().Context(), keyID); err != nil {
+		if errors.Is(err, apikey.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrNotFound(err))
+		}
+		s.log.Errorf("keys.Del error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+	}
+	key := apikey.Key{ID: keyID}
+	if err := s.perm.InvalidateUser(c.Request().Context(), key.UserID()); err != nil {
+		s.log.Errorf("perm.InvalidateUser error, %s", err)
+	}
+	s.log.Infof("api key %s revoked", keyID)
+	return c.JSON(http.StatusOK, OkStatus(fmt.Sprintf("revoked api key %s", keyID)))
+}
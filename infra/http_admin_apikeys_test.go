@@ -0,0 +1,53 @@
+package infra
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/apikey/mem"
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+func TestServer_apiAdminCreateAPIKey(t *testing.T) {
+	const (
+		read  = `{"resources":["watcom.ru:data.counting:layouts:10"],"actions":["read"],"effect":"allow"}`
+		admin = `{"resources":["watcom.ru:data.counting:admin"],"actions":["*"],"effect":"allow"}`
+	)
+	allowAdmin := viper.New()
+	allowAdmin.Set("apikeys.allow_admin", true)
+	enabled := &Server{log: zap.NewNop().Sugar(), config: viper.New(), keys: mem.New()}
+	tests := []struct {
+		name string
+		s    *Server
+		body string
+		want int
+	}{
+		{"disabled", &Server{log: zap.NewNop().Sugar(), config: viper.New()}, `{"permissions":[` + read + `]}`,
+			http.StatusServiceUnavailable},
+		{"read", enabled, `{"title":"reports","permissions":[` + read + `]}`, http.StatusCreated},
+		{"no permissions", enabled, `{"title":"reports"}`, http.StatusBadRequest},
+		{"unknown action", enabled, `{"permissions":[{"resources":["watcom.ru:data.counting:layouts:10"],"actions":["write"],"effect":"allow"}]}`,
+			http.StatusBadRequest},
+		{"admin", enabled, `{"permissions":[` + read + `,` + admin + `]}`, http.StatusForbidden},
+		{"allowed admin", &Server{log: zap.NewNop().Sugar(), config: allowAdmin, keys: mem.New()},
+			`{"permissions":[` + admin + `]}`, http.StatusCreated},
+	}
+	e := echo.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v2/admin/apikeys", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			if err := tt.s.apiAdminCreateAPIKey(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package infra
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// apiAdminFlushUserACL docs
+// @Summary Flush cached permissions of the user
+// @Description remove cached ACL of the user, it will be rebuilt on the next request of the user
+// @Description allowed only for users with permission to resource <namespace>:data.counting:admin
+// @Produce  json
+// @Tags admin
+// @Param user_id path string true "user id, value of the X-User-ID header"
+// @Success 200 {object} infra.SuccessResponse
+// @Failure 400 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Router /v2/admin/permissions/cache/users/{user_id} [delete]
+func (s *Server) apiAdminFlushUserACL(c echo.Context) error {
+	userID := c.Param("user_id")
+	if userID == "" {
+		s.log.Errorf("bad request apiAdminFlushUserACL, %v", errEmptyID)
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(errEmptyID))
+	}
+	if err := s.perm.InvalidateUser(c.Request().Context(), userID); err != nil {
+		s.log.Errorf("perm.InvalidateUser error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+	}
+	return c.JSON(http.StatusOK, OkStatus(fmt.Sprintf("flushed user %s", userID)))
+}
+
+// apiAdminFlushLayoutACL docs
+// @Summary Flush cached permissions of the users for the layout
+// @Description remove cached ACLs of the all users which have rules for the layout,
+// @Description layout_id=* flushes all cached users
+// @Description allowed only for users with permission to resource <namespace>:data.counting:admin
+// @Produce  json
+// @Tags admin
+// @Param layout_id path string true "layout id or *"
+// @Success 200 {object} infra.SuccessResponse
+// @Failure 400 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Router /v2/admin/permissions/cache/layouts/{layout_id} [delete]
+func (s *Server) apiAdminFlushLayoutACL(c echo.Context) error {
+	layoutID := c.Param("layout_id")
+	if layoutID == "" {
+		s.log.Errorf("bad request apiAdminFlushLayoutACL, %v", errEmptyID)
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(errEmptyID))
+	}
+	cnt, err := s.perm.InvalidateLayout(c.Request().Context(), layoutID)
+	if err != nil {
+		s.log.Errorf("perm.InvalidateLayout error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+	}
+	return c.JSON(http.StatusOK, OkStatus(fmt.Sprintf("flushed %d user(s)", cnt)))
+}
+
+// invalidateLayoutACL drops cached ACLs affected by changes of the layout structure,
+// errors are logged only, the cache expires anyway.
+func (s *Server) invalidateLayoutACL(c echo.Context, layoutID string) {
+	cnt, err := s.perm.InvalidateLayout(c.Request().Context(), layoutID)
+	if err != nil {
+		s.log.Errorf("perm.InvalidateLayout for layout_id=%s error, %s", layoutID, err)
+		return
+	}
+	s.log.Debugf("invalidated cached ACLs of %d user(s) for layout_id=%s", cnt, layoutID)
+}
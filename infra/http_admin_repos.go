@@ -0,0 +1,209 @@
+package infra
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/connmanager"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	errRepoSource error = errors.New("one of project_id or dsn required")
+)
+
+// NewRepo is parameter for new repo, project_id of the commonapi or dsn of the database
+type NewRepo struct {
+	ProjectID string `json:"project_id,omitempty"`
+	DSN       string `json:"dsn,omitempty"`
+}
+
+// ReposResponse registered repos, states of the databases connections
+// and layouts claimed by several repos
+type ReposResponse struct {
+	Data      []connmanager.RepoInfo       `json:"data"`
+	Databases []connmanager.DBStatus       `json:"databases"`
+	Conflicts []connmanager.LayoutConflict `json:"conflicts"`
+}
+
+// apiAdminRepos docs
+// @Summary Get registered repos
+// @Description get repos of the countmax databases with layouts they serve,
+// @Description states of the databases connections, lost databases are retried with backoff,
+// @Description and layouts claimed by several repos, requests are routed to the owner
+// @Description allowed only for users with permission to resource <namespace>:data.counting:admin
+// @Produce  json
+// @Tags admin
+// @Success 200 {object} infra.ReposResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Router /v2/admin/repos [get]
+func (s *Server) apiAdminRepos(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.reposResponse(s.repoM.ReposInfo()))
+}
+
+// apiAdminRepoByID docs
+// @Summary Get registered repo by id
+// @Description allowed only for users with permission to resource <namespace>:data.counting:admin
+// @Produce  json
+// @Tags admin
+// @Param repo_id path string true "repo id"
+// @Success 200 {object} connmanager.RepoInfo
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Router /v2/admin/repos/{repo_id} [get]
+func (s *Server) apiAdminRepoByID(c echo.Context) error {
+	repoID := c.Param("repo_id")
+	if repoID == "" {
+		s.log.Errorf("bad request apiAdminRepoByID, %v", errEmptyID)
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(errEmptyID))
+	}
+	info, err := s.repoM.RepoInfo(repoID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, ErrNotFound(err))
+	}
+	return c.JSON(http.StatusOK, info)
+}
+
+// apiAdminAddRepo docs
+// @Summary Add repo
+// @Description add repo of the countmax database by project_id of the commonapi or by dsn,
+// @Description layouts of the database are registered immediately without restart
+// @Description allowed only for users with permission to resource <namespace>:data.counting:admin
+// @Accept  json
+// @Produce  json
+// @Tags admin
+// @Param repo body infra.NewRepo true "project_id or dsn"
+// @Success 201 {object} connmanager.RepoInfo
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 409 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Router /v2/admin/repos [post]
+func (s *Server) apiAdminAddRepo(c echo.Context) error {
+	nr := &NewRepo{}
+	if err := c.Bind(nr); err != nil {
+		s.log.Errorf("apiAdminAddRepo, bad request error %v", err)
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	}
+	if (nr.ProjectID == "") == (nr.DSN == "") {
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(errRepoSource))
+	}
+	var (
+		info connmanager.RepoInfo
+		err  error
+	)
+	if nr.ProjectID != "" {
+		info, err = s.repoM.AddRepoByProject(c.Request().Context(), nr.ProjectID)
+	} else {
+		info, err = s.repoM.AddRepoByDSN(c.Request().Context(), nr.DSN)
+	}
+	switch {
+	case errors.Is(err, connmanager.ErrRepoExists):
+		return c.JSON(http.StatusConflict, ErrConflict(err))
+	case errors.Is(err, connmanager.ErrNoCommonAPI):
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	case err != nil:
+		s.log.Errorf("repoM.AddRepo error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+	}
+	s.log.Infof("repo %s added with %d layout(s)", info.Dest, len(info.Layouts))
+	for _, layoutID := range info.Layouts {
+		s.invalidateLayoutACL(c, layoutID)
+	}
+	return c.JSON(http.StatusCreated, info)
+}
+
+// apiAdminDeleteRepo docs
+// @Summary Remove repo
+// @Description unregister repo and all layouts it serves, connections of the repo are closed
+// @Description after countmax.timeout, so requests in progress are finished
+// @Description allowed only for users with permission to resource <namespace>:data.counting:admin
+// @Produce  json
+// @Tags admin
+// @Param repo_id path string true "repo id"
+// @Success 200 {object} infra.SuccessResponse
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Router /v2/admin/repos/{repo_id} [delete]
+func (s *Server) apiAdminDeleteRepo(c echo.Context) error {
+	repoID := c.Param("repo_id")
+	if repoID == "" {
+		s.log.Errorf("bad request apiAdminDeleteRepo, %v", errEmptyID)
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(errEmptyID))
+	}
+	info, err := s.repoM.RemoveRepo(repoID)
+	if err != nil {
+		if errors.Is(err, connmanager.ErrRepoNotFound) {
+			return c.JSON(http.StatusNotFound, ErrNotFound(err))
+		}
+		s.log.Errorf("repoM.RemoveRepo error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+	}
+	s.log.Infof("repo %s removed", info.Dest)
+	for _, layoutID := range info.Layouts {
+		s.invalidateLayoutACL(c, layoutID)
+	}
+	return c.JSON(http.StatusOK, OkStatus(fmt.Sprintf("removed repo %s with %d layout(s)", repoID, len(info.Layouts))))
+}
+
+// apiAdminRebuildRepo docs
+// @Summary Rebuild repo
+// @Description reconnect to the database of the repo and reread its layouts
+// @Description allowed only for users with permission to resource <namespace>:data.counting:admin
+// @Produce  json
+// @Tags admin
+// @Param repo_id path string true "repo id"
+// @Success 200 {object} connmanager.RepoInfo
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Router /v2/admin/repos/{repo_id}/rebuild [post]
+func (s *Server) apiAdminRebuildRepo(c echo.Context) error {
+	repoID := c.Param("repo_id")
+	if repoID == "" {
+		s.log.Errorf("bad request apiAdminRebuildRepo, %v", errEmptyID)
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(errEmptyID))
+	}
+	info, err := s.repoM.RebuildRepo(c.Request().Context(), repoID)
+	if err != nil {
+		if errors.Is(err, connmanager.ErrRepoNotFound) {
+			return c.JSON(http.StatusNotFound, ErrNotFound(err))
+		}
+		s.log.Errorf("repoM.RebuildRepo error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+	}
+	for _, layoutID := range info.Layouts {
+		s.invalidateLayoutACL(c, layoutID)
+	}
+	return c.JSON(http.StatusOK, info)
+}
+
+// apiAdminReregisterRepos docs
+// @Summary Reregister layouts of the all repos
+// @Description reread layouts of the all registered repos and retry connection to the lost databases,
+// @Description cached permissions of the all users are flushed
+// @Description allowed only for users with permission to resource <namespace>:data.counting:admin
+// @Produce  json
+// @Tags admin
+// @Success 200 {object} infra.ReposResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Router /v2/admin/repos/reregister [post]
+func (s *Server) apiAdminReregisterRepos(c echo.Context) error {
+	infos, err := s.repoM.Reregister(c.Request().Context())
+	s.invalidateLayoutACL(c, "*")
+	if err != nil {
+		s.log.Errorf("repoM.Reregister error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+	}
+	return c.JSON(http.StatusOK, s.reposResponse(infos))
+}
+
+func (s *Server) reposResponse(infos []connmanager.RepoInfo) ReposResponse {
+	return ReposResponse{Data: infos, Databases: s.repoM.Statuses(), Conflicts: s.repoM.Conflicts()}
+}
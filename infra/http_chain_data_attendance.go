@@ -109,6 +109,7 @@ func (s *Server) apiChainStoresDataAttendance(c echo.Context) error {
 // @Success 200 {object} domain.ZonesAttendance
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 413 {object} infra.ErrResponse
@@ -139,8 +140,16 @@ func (s *Server) apiChainZonesDataAttendance(c echo.Context) error {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: add get filtered list zones
-	data, err := repo.FindChainZonesDataAttendance(c.Request().Context(), from, to, groupBy, layoutID, useRawData, strings.Join(zoneIDs, ","))
+	zones, err := s.readableZones(c, repo, layoutID)
+	if err != nil {
+		s.log.Errorf("readableZones error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	filteredList := zones.filterList(zoneIDs)
+	if len(filteredList) == 0 {
+		return c.JSON(http.StatusOK, domain.ZonesAttendance{})
+	}
+	data, err := repo.FindChainZonesDataAttendance(c.Request().Context(), from, to, groupBy, layoutID, useRawData, strings.Join(filteredList, ","))
 	if err != nil {
 		if err == repos.ErrNotAllowedDataRange {
 			return c.JSON(http.StatusRequestEntityTooLarge, ErrPayloadTooLarge(err))
@@ -148,7 +157,7 @@ func (s *Server) apiChainZonesDataAttendance(c echo.Context) error {
 		s.log.Errorf("repo.FindChainZonesDataAttendance error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
-	return c.JSON(http.StatusOK, data)
+	return c.JSON(http.StatusOK, data.FilterByZone(zones.allow))
 }
 
 // apiChainEntrancesDataAttendance docs
@@ -33,6 +33,7 @@ const (
 // @Success 200 {object} domain.StoresDataQueue
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 413 {object} infra.ErrResponse
@@ -53,12 +54,20 @@ func (s *Server) apiChainStoresDataQueue(c echo.Context) error {
 		s.log.Warnf("getFromToParams error, %s", err)
 		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
 	}
-	repo, _, err := s.getRepo(c)
+	repo, layoutID, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: add filtered list of the stores
+	stores, err := s.readableStores(c, layoutID)
+	if err != nil {
+		s.log.Errorf("readableStores error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !storeParamAllowed(stores, pStoreID) {
+		s.log.Warnf("not permitted read for layout %s and store %s", layoutID, pStoreID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	data, err := repo.FindChainStoresDataQueue(c.Request().Context(), from, to, &pStoreID, groupBy, groupFunc, defaultWindow)
 	if err != nil {
 		if err == repos.ErrNotAllowedDataRange {
@@ -67,7 +76,7 @@ func (s *Server) apiChainStoresDataQueue(c echo.Context) error {
 		s.log.Errorf("repo.FindChainStoresDataQueue error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
-	return c.JSON(http.StatusOK, data)
+	return c.JSON(http.StatusOK, data.FilterByStore(stores.Allow))
 }
 
 // apiChainStoresDataQueue docs
@@ -82,24 +91,33 @@ func (s *Server) apiChainStoresDataQueue(c echo.Context) error {
 // @Success 200 {object} domain.StoresDataQueue
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 500 {object} infra.ErrResponse
 // @Router /v2/data/queue/length/stores/live [get]
 func (s *Server) apiChainStoresDataQueueNow(c echo.Context) error {
 	pStoreID := c.QueryParam("store_id") // TODO: add list of the ids stores as params
-	repo, _, err := s.getRepo(c)
+	repo, layoutID, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: add filtered list of the stores
+	stores, err := s.readableStores(c, layoutID)
+	if err != nil {
+		s.log.Errorf("readableStores error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !storeParamAllowed(stores, pStoreID) {
+		s.log.Warnf("not permitted read for layout %s and store %s", layoutID, pStoreID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	data, err := repo.FindChainStoresDataQueueNow(c.Request().Context(), &pStoreID)
 	if err != nil {
 		s.log.Errorf("repo.FindChainStoresDataQueueNow error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
-	return c.JSON(http.StatusOK, data)
+	return c.JSON(http.StatusOK, data.FilterByStore(stores.Allow))
 }
 
 // apiChainZonesDataQueue docs
@@ -119,6 +137,7 @@ func (s *Server) apiChainStoresDataQueueNow(c echo.Context) error {
 // @Success 200 {object} domain.ZonesDataQueue
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 413 {object} infra.ErrResponse
@@ -132,12 +151,24 @@ func (s *Server) apiChainZonesDataQueue(c echo.Context) error {
 		s.log.Warnf("getFromToParams error, %s", err)
 		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
 	}
-	repo, _, err := s.getRepo(c)
+	repo, layoutID, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: get filtered list of the zones/detections
+	zones, err := s.readableZones(c, repo, layoutID)
+	if err != nil {
+		s.log.Errorf("readableZones error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !isAnyParam(zoneID) && !zones.allow(zoneID) {
+		s.log.Warnf("not permitted read for layout %s and zone %s", layoutID, zoneID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
+	if !storeParamAllowed(zones.stores, storeID) {
+		s.log.Warnf("not permitted read for layout %s and store %s", layoutID, storeID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	data, err := repo.FindChainZonesDataQueue(c.Request().Context(), from, to, storeID, zoneID) // TODO: add param list zones
 	if err != nil {
 		if err == repos.ErrNotAllowedDataRange {
@@ -146,7 +177,7 @@ func (s *Server) apiChainZonesDataQueue(c echo.Context) error {
 		s.log.Errorf("repo.FindChainZonesDataQueue error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
-	return c.JSON(http.StatusOK, data)
+	return c.JSON(http.StatusOK, data.FilterByZone(zones.allow))
 }
 
 // apiChainZonesDataQueueNow docs
@@ -161,24 +192,33 @@ func (s *Server) apiChainZonesDataQueue(c echo.Context) error {
 // @Success 200 {object} domain.ZonesDataQueue
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 500 {object} infra.ErrResponse
 // @Router /v2/data/queue/length/zones/live [get]
 func (s *Server) apiChainZonesDataQueueNow(c echo.Context) error {
 	zoneID := c.QueryParam("zone_id") // TODO: add list of the ids zones as params
-	repo, _, err := s.getRepo(c)
+	repo, layoutID, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: get filtered list of the zones/detections
+	zones, err := s.readableZones(c, repo, layoutID)
+	if err != nil {
+		s.log.Errorf("readableZones error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !isAnyParam(zoneID) && !zones.allow(zoneID) {
+		s.log.Warnf("not permitted read for layout %s and zone %s", layoutID, zoneID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	data, err := repo.FindChainZonesDataQueueNow(c.Request().Context(), &zoneID) // TODO: add param list zones
 	if err != nil {
 		s.log.Errorf("repo.FindChainZonesDataQueueNow error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
-	return c.JSON(http.StatusOK, data)
+	return c.JSON(http.StatusOK, data.FilterByZone(zones.allow))
 }
 
 // apiChainRecommendationsQueue docs
@@ -200,6 +240,7 @@ func (s *Server) apiChainZonesDataQueueNow(c echo.Context) error {
 // @Success 200 {object} domain.PredictionsQueue
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 500 {object} infra.ErrResponse
@@ -222,16 +263,24 @@ func (s *Server) apiChainRecommendationsQueue(c echo.Context) error {
 		s.log.Warnf("getFromToParams error, %s", err)
 		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
 	}
-	repo, _, err := s.getRepo(c)
+	repo, layoutID, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: add perm CheckStore
+	stores, err := s.readableStores(c, layoutID)
+	if err != nil {
+		s.log.Errorf("readableStores error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !storeParamAllowed(stores, storeID) {
+		s.log.Warnf("not permitted read for layout %s and store %s", layoutID, storeID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	data, err := repo.FindChainPredictionQueue(c.Request().Context(), from, to, &storeID, splitInterval)
 	if err != nil {
 		s.log.Errorf("repo.FindChainPredictionQueue error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
-	return c.JSON(http.StatusOK, data)
+	return c.JSON(http.StatusOK, data.FilterByStore(stores.Allow))
 }
@@ -116,6 +116,7 @@ func (s *Server) apiCreateChainDevice(c echo.Context) error {
 		s.log.Errorf("repo.AddChainDevice error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
+	c.Set(ctxCreatedID, id)
 	href := `{"href":"/v2/chains/devices/` + id + `"}`
 	return c.JSON(http.StatusCreated, CreatedStatus(href))
 }
@@ -471,6 +472,7 @@ type ChainDeviceTracksResponse struct {
 // @Success 200 {object} infra.ChainDeviceTracksResponse
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 500 {object} infra.ErrResponse
@@ -488,11 +490,21 @@ func (s *Server) apiChainDeviceTracks(c echo.Context) error { // TODO: remove me
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
+	stores, err := s.readableStores(c, layoutID)
+	if err != nil {
+		s.log.Errorf("readableStores error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !storeParamAllowed(stores, store) {
+		s.log.Warnf("not permitted read for layout %s and store %s", layoutID, store)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	tracks, count, err := repo.FindChainDeviceTracks(c.Request().Context(), layoutID, store, device, from, to, offset, limit)
 	if err != nil {
 		s.log.Errorf("repo.FindChainDeviceTracks failed: %v", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
+	tracks = tracks.FilterByStore(stores.Allow)
 	response := ChainDeviceTracksResponse{
 		Data: tracks,
 		Metadata: Metadata{
@@ -521,6 +533,7 @@ func (s *Server) apiChainDeviceTracks(c echo.Context) error { // TODO: remove me
 // @Success 200 {object} domain.Tracks
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 500 {object} infra.ErrResponse
@@ -555,10 +568,19 @@ func (s *Server) apiChainDeviceTracksAt(c echo.Context) error { // TODO: remove
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
+	stores, err := s.readableStores(c, layoutID)
+	if err != nil {
+		s.log.Errorf("readableStores error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !storeParamAllowed(stores, store) {
+		s.log.Warnf("not permitted read for layout %s and store %s", layoutID, store)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	tracks, err := repo.FindChainDeviceTracksAt(c.Request().Context(), layoutID, store, device, at, acc)
 	if err != nil {
 		s.log.Errorf("repo.FindChainDeviceTracksAt failed, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
-	return c.JSON(http.StatusOK, tracks)
+	return c.JSON(http.StatusOK, tracks.FilterByStore(stores.Allow))
 }
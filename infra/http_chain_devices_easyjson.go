@@ -5,6 +5,7 @@ package infra
 import (
 	json "encoding/json"
 	domain "git.countmax.ru/countmax/layoutconfig.api/domain"
+	connmanager "git.countmax.ru/countmax/layoutconfig.api/internal/connmanager"
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
@@ -627,6 +628,29 @@ func easyjsonC3ba8e86DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra2(in *jlexer
 			}
 		case "result_set":
 			easyjsonC3ba8e86DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra3(in, &out.ResultSet)
+		case "errors":
+			if in.IsNull() {
+				in.Skip()
+				out.Errors = nil
+			} else {
+				in.Delim('[')
+				if out.Errors == nil {
+					if !in.IsDelim(']') {
+						out.Errors = make(RepoErrors, 0, 2)
+					} else {
+						out.Errors = RepoErrors{}
+					}
+				} else {
+					out.Errors = (out.Errors)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v2 connmanager.RepoError
+					easyjsonC3ba8e86DecodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(in, &v2)
+					out.Errors = append(out.Errors, v2)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -648,11 +672,11 @@ func easyjsonC3ba8e86EncodeGitCountmaxRuCountmaxLayoutconfigApiInfra2(out *jwrit
 			out.RawString("null")
 		} else {
 			out.RawByte('[')
-			for v2, v3 := range in.Data {
-				if v2 > 0 {
+			for v3, v4 := range in.Data {
+				if v3 > 0 {
 					out.RawByte(',')
 				}
-				(v3).MarshalEasyJSON(out)
+				(v4).MarshalEasyJSON(out)
 			}
 			out.RawByte(']')
 		}
@@ -662,6 +686,20 @@ func easyjsonC3ba8e86EncodeGitCountmaxRuCountmaxLayoutconfigApiInfra2(out *jwrit
 		out.RawString(prefix)
 		easyjsonC3ba8e86EncodeGitCountmaxRuCountmaxLayoutconfigApiInfra3(out, in.ResultSet)
 	}
+	if len(in.Errors) != 0 {
+		const prefix string = ",\"errors\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v5, v6 := range in.Errors {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				easyjsonC3ba8e86EncodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(out, v6)
+			}
+			out.RawByte(']')
+		}
+	}
 	out.RawByte('}')
 }
 
@@ -688,6 +726,55 @@ func (v *ChainDevicesResponse) UnmarshalJSON(data []byte) error {
 func (v *ChainDevicesResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonC3ba8e86DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra2(l, v)
 }
+func easyjsonC3ba8e86DecodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(in *jlexer.Lexer, out *connmanager.RepoError) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "dest":
+			out.Dest = string(in.String())
+		case "error":
+			out.Error = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC3ba8e86EncodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(out *jwriter.Writer, in connmanager.RepoError) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"dest\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Dest))
+	}
+	{
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
+	out.RawByte('}')
+}
 func easyjsonC3ba8e86DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra3(in *jlexer.Lexer, out *ResultSet) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
@@ -774,6 +861,29 @@ func easyjsonC3ba8e86DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra4(in *jlexer
 			(out.Data).UnmarshalEasyJSON(in)
 		case "result_set":
 			easyjsonC3ba8e86DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra3(in, &out.ResultSet)
+		case "errors":
+			if in.IsNull() {
+				in.Skip()
+				out.Errors = nil
+			} else {
+				in.Delim('[')
+				if out.Errors == nil {
+					if !in.IsDelim(']') {
+						out.Errors = make(RepoErrors, 0, 2)
+					} else {
+						out.Errors = RepoErrors{}
+					}
+				} else {
+					out.Errors = (out.Errors)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v7 connmanager.RepoError
+					easyjsonC3ba8e86DecodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(in, &v7)
+					out.Errors = append(out.Errors, v7)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -798,6 +908,20 @@ func easyjsonC3ba8e86EncodeGitCountmaxRuCountmaxLayoutconfigApiInfra4(out *jwrit
 		out.RawString(prefix)
 		easyjsonC3ba8e86EncodeGitCountmaxRuCountmaxLayoutconfigApiInfra3(out, in.ResultSet)
 	}
+	if len(in.Errors) != 0 {
+		const prefix string = ",\"errors\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v8, v9 := range in.Errors {
+				if v8 > 0 {
+					out.RawByte(',')
+				}
+				easyjsonC3ba8e86EncodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(out, v9)
+			}
+			out.RawByte(']')
+		}
+	}
 	out.RawByte('}')
 }
 
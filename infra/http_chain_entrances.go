@@ -84,6 +84,7 @@ func (s *Server) apiCreateChainEntrance(c echo.Context) error {
 		s.log.Errorf("repo.AddChainEntrance failed, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
+	c.Set(ctxCreatedID, id)
 	href := `{"href":"/v2/chains/entrances/` + id + `"}`
 	return c.JSON(http.StatusCreated, CreatedStatus(href))
 }
@@ -475,7 +476,7 @@ func (s *Server) apiCreateChainBindEntranceStore(c echo.Context) error {
 		s.log.Errorf("apiCreateChainBindEntranceStore, bad request error %v", err)
 		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
 	}
-	repo, _, err := s.getRepo(c)
+	repo, layoutID, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
@@ -485,6 +486,7 @@ func (s *Server) apiCreateChainBindEntranceStore(c echo.Context) error {
 		s.log.Errorf("repo.BindChainEntranceStore, error %v", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
+	s.invalidateLayoutACL(c, layoutID)
 	return c.JSON(http.StatusCreated, CreatedStatus("binded"))
 }
 
@@ -551,7 +553,7 @@ func (s *Server) apiUpdChainBindEntranceStore(c echo.Context) error {
 	if bind.NewStoreID == "" {
 		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(fmt.Errorf("empty new_store_id not allowed")))
 	}
-	repo, _, err := s.getRepo(c)
+	repo, layoutID, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
@@ -562,6 +564,7 @@ func (s *Server) apiUpdChainBindEntranceStore(c echo.Context) error {
 		s.log.Errorf("repo.UpdBindChainEntranceStore, error %v", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
+	s.invalidateLayoutACL(c, layoutID)
 	return c.JSON(http.StatusOK, OkStatus("bind updated"))
 }
 
@@ -585,7 +588,7 @@ func (s *Server) apiUpdChainBindEntranceStore(c echo.Context) error {
 func (s *Server) apiDeleteChainBindEntranceStore(c echo.Context) error {
 	entrance_id := c.QueryParam("entrance_id")
 	store_id := c.QueryParam("store_id")
-	repo, _, err := s.getRepo(c)
+	repo, layoutID, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
@@ -595,6 +598,7 @@ func (s *Server) apiDeleteChainBindEntranceStore(c echo.Context) error {
 		s.log.Errorf("repo.DelChainBindEntranceStore, error %v", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
+	s.invalidateLayoutACL(c, layoutID)
 	return c.JSON(http.StatusOK, OkStatus(fmt.Sprintf("deleted %d bind(s)", cnt)))
 }
 
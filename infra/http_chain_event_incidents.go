@@ -0,0 +1,116 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/correlate"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	errCorrelationDisabled error = errors.New("events correlation disabled, events.correlation.isuse is false")
+)
+
+// ChainEventIncidentsResponse http wrapper with metadata
+type ChainEventIncidentsResponse struct {
+	Data []*correlate.Incident `json:"data"`
+	Metadata
+}
+
+// apiChainEventIncidents docs
+// @Summary Get incidents of the repeated events
+// @Description get events of the from - to datetime range grouped into incidents, the last seen first:
+// @Description events with the same key, layout_id, store_id, source kind and params of the source from events.correlation.params
+// @Description following each other within events.correlation.window make the single incident with count of the occurrences,
+// @Description first and last seen times and the first event as representative
+// @Description only events of the stores allowed to the user are grouped, events without store - for the users without store restrictions
+// @Description from/to can be: YYYY-MM-DDTHH:mm:ss+07:00 or naive YYYY-MM-DD HH:mm:ss then the server's local timezone is applied
+// @Produce json
+// @Tags chains/events
+// @Param layout_id query string false "default=*"
+// @Param store_id query string false "default=*"
+// @Param key query string false "default=*"
+// @Param kind query string false "default=*"
+// @Param severity query string false "default=*"
+// @Param fingerprint query string false "fingerprint of the incidents"
+// @Param from query string false "ISO8601 datetime, default begin of day"
+// @Param to query string false "ISO8601 datetime, dafault current time"
+// @Param offset query integer false "default=0"
+// @Param limit query integer false "default=20"
+// @Success 200 {object} infra.ChainEventIncidentsResponse
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/chains/events/incidents [get]
+func (s *Server) apiChainEventIncidents(c echo.Context) error {
+	if s.corr == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errCorrelationDisabled))
+	}
+	offset, limit := s.getPageParams(c)
+	from, to, err := s.getFromToParams(c)
+	if err != nil {
+		s.log.Warnf("getFromToParams error, %s", err)
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	}
+	layoutID, storeID := c.QueryParam("layout_id"), c.QueryParam("store_id")
+	if !isAnyParam(layoutID) {
+		stores, err := s.readableStores(c, layoutID)
+		if err != nil {
+			s.log.Errorf("readableStores error, %s", err)
+			return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+		}
+		if stores.Empty() || !storeParamAllowed(stores, storeID) {
+			s.log.Warnf("not permitted read for layout %s and store %s", layoutID, storeID)
+			return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+		}
+	}
+	events, err := s.findAllChainEvents(func(limit, offset int64) (domain.Events, int64, error) {
+		return s.evRepo.FindChainEvents(from, to, layoutID, storeID,
+			c.QueryParam("key"), c.QueryParam("kind"), c.QueryParam("severity"), limit, offset)
+	})
+	if errors.Is(err, errTooManyRows) {
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	}
+	if err != nil {
+		s.log.Errorf("evRepo.FindChainEvents error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+	}
+	events, err = s.readableEvents(c, events)
+	if err != nil {
+		s.log.Errorf("readableEvents error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	incidents := correlate.Group(events, *s.corr)
+	if fp := c.QueryParam("fingerprint"); fp != "" {
+		matched := incidents[:0]
+		for _, inc := range incidents {
+			if inc.Fingerprint == fp {
+				matched = append(matched, inc)
+			}
+		}
+		incidents = matched
+	}
+	total := int64(len(incidents))
+	page := make([]*correlate.Incident, 0)
+	if offset < total {
+		end := offset + limit
+		if end > total {
+			end = total
+		}
+		page = incidents[offset:end]
+	}
+	return c.JSON(http.StatusOK, ChainEventIncidentsResponse{
+		Data: page,
+		Metadata: Metadata{
+			ResultSet: ResultSet{
+				Count:  int64(len(page)),
+				Offset: offset,
+				Limit:  limit,
+				Total:  total,
+			},
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package infra
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/correlate"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+func TestServer_apiChainEventIncidents(t *testing.T) {
+	t1 := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	flap := func(id string, d time.Duration) domain.Event {
+		return domain.Event{ID: id, Key: "door.open", EventTime: t1.Add(d), LayoutID: "10", StoreID: "s1"}
+	}
+	repo := &fakeEventRepo{events: domain.Events{flap("1", 0), flap("2", time.Minute), flap("3", 2*time.Minute),
+		{ID: "4", Key: "queue.threshold.exceeded", EventTime: t1, LayoutID: "10", StoreID: "s1"},
+		{ID: "5", Key: "door.open", EventTime: t1, LayoutID: "10", StoreID: "s2"}}}
+	s := &Server{log: zap.NewNop().Sugar(), evRepo: repo, corr: &correlate.Config{Window: 5 * time.Minute},
+		perm: newStoresPerm(t, "byStore", "10", "s1")}
+	e := echo.New()
+	call := func(s *Server, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		setStoresPerm(req, "byStore", "10", "s1")
+		rec := httptest.NewRecorder()
+		if err := s.apiChainEventIncidents(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		return rec
+	}
+
+	rec := call(s, "/v2/chains/events/incidents?from=2021-06-01T00:00:00Z&to=2021-06-02T00:00:00Z")
+	res := ChainEventIncidentsResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("unmarshal error, %s, %s", err, rec.Body.String())
+	}
+	if rec.Code != http.StatusOK || res.ResultSet.Total != 2 || res.Data[0].Count != 3 || res.Data[0].Event.ID != "1" ||
+		res.Data[0].LastEventID != "3" {
+		t.Fatalf("incidents status = %d, %+v, want 2 incidents, the last seen of 3 events first", rec.Code, res)
+	}
+	rec = call(s, "/v2/chains/events/incidents?fingerprint="+res.Data[1].Fingerprint)
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil || res.ResultSet.Total != 1 || res.Data[0].Key != "queue.threshold.exceeded" {
+		t.Errorf("incidents by fingerprint %+v, %v, want single incident", res, err)
+	}
+	if rec = call(s, "/?layout_id=10&store_id=s2"); rec.Code != http.StatusForbidden {
+		t.Errorf("not permitted store status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if rec = call(&Server{log: zap.NewNop().Sugar()}, "/"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("disabled correlation status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
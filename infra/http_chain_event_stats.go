@@ -0,0 +1,150 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstats"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission"
+	"github.com/labstack/echo/v4"
+)
+
+// ChainEventStatsResponse http wrapper with metadata
+type ChainEventStatsResponse struct {
+	Data []*eventstats.Group `json:"data"`
+	Metadata
+}
+
+// nolint:lll
+// apiChainEventStats docs
+// @Summary Get statistics of the events
+// @Description get aggregates of the events of the from - to datetime range grouped by dimensions: count, first and last event_time,
+// @Description count of the resolved events and their mean time to resolve in seconds (mttr_seconds, needs events.lifecycle.url),
+// @Description group_by - comma separated dimensions: key, kind, severity, layout, store, time, param:<name of the source param>; empty - all events,
+// @Description bucket of the time dimension: hour, day (default), week (begins on monday), month in the time zone tz,
+// @Description sort: count (default, descending), group (ascending), mttr (descending),
+// @Description only events of the stores allowed to the user are counted, events without store - for the users without store restrictions
+// @Description e.g. queue alarms per store per week: key=queue.threshold.exceeded&severity=alarm&group_by=store,time&bucket=week
+// @Description top noisy devices: group_by=param:device_id&limit=10
+// @Produce json
+// @Tags chains/events
+// @Param layout_id query string false "default=*"
+// @Param store_id query string false "default=*"
+// @Param key query string false "default=*"
+// @Param kind query string false "default=*"
+// @Param severity query string false "default=*"
+// @Param group_by query string false "key,kind,severity,layout,store,time,param:<name>"
+// @Param bucket query string false "hour, day, week, month; default=day"
+// @Param tz query string false "IANA time zone of the buckets, default server's local"
+// @Param sort query string false "count, group, mttr; default=count"
+// @Param from query string false "ISO8601 datetime, default begin of day"
+// @Param to query string false "ISO8601 datetime, dafault current time"
+// @Param offset query integer false "default=0"
+// @Param limit query integer false "default=20"
+// @Success 200 {object} infra.ChainEventStatsResponse
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/chains/events/stats [get]
+func (s *Server) apiChainEventStats(c echo.Context) error {
+	if s.evRepo == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errEventsDisabled))
+	}
+	q := eventstats.Query{Bucket: c.QueryParam("bucket"), Sort: c.QueryParam("sort"), Loc: time.Local}
+	if groupBy := c.QueryParam("group_by"); groupBy != "" {
+		q.GroupBy = strings.Split(groupBy, ",")
+	}
+	if tz := c.QueryParam("tz"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+		}
+		q.Loc = loc
+	}
+	if err := q.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	}
+	offset, limit := s.getPageParams(c)
+	from, to, err := s.getFromToParams(c)
+	if err != nil {
+		s.log.Warnf("getFromToParams error, %s", err)
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	}
+	layoutID, storeID := c.QueryParam("layout_id"), c.QueryParam("store_id")
+	if !isAnyParam(layoutID) {
+		stores, err := s.readableStores(c, layoutID)
+		if err != nil {
+			s.log.Errorf("readableStores error, %s", err)
+			return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+		}
+		if stores.Empty() || !storeParamAllowed(stores, storeID) {
+			s.log.Warnf("not permitted read for layout %s and store %s", layoutID, storeID)
+			return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+		}
+	}
+	events, err := s.findAllChainEvents(func(limit, offset int64) (domain.Events, int64, error) {
+		return s.evRepo.FindChainEvents(from, to, layoutID, storeID, c.QueryParam("key"),
+			c.QueryParam("kind"), c.QueryParam("severity"), limit, offset)
+	})
+	if errors.Is(err, errTooManyRows) {
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	}
+	if err != nil {
+		s.log.Errorf("evRepo.FindChainEvents error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+	}
+	events, err = s.readableEvents(c, events)
+	if err != nil {
+		s.log.Errorf("readableEvents error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	groups, err := eventstats.Aggregate(events, q)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	}
+	total := int64(len(groups))
+	page := make([]*eventstats.Group, 0)
+	if offset < total {
+		end := offset + limit
+		if end > total {
+			end = total
+		}
+		page = groups[offset:end]
+	}
+	return c.JSON(http.StatusOK, ChainEventStatsResponse{
+		Data: page,
+		Metadata: Metadata{
+			ResultSet: ResultSet{
+				Count:  int64(len(page)),
+				Offset: offset,
+				Limit:  limit,
+				Total:  total,
+			},
+		},
+	})
+}
+
+// readableEvents keeps events of the stores allowed to read by the request user,
+// events without store are kept in the layouts without store restrictions of the user.
+func (s *Server) readableEvents(c echo.Context, events domain.Events) (domain.Events, error) {
+	filters := make(map[string]permission.StoresFilter)
+	res := events[:0]
+	for _, e := range events {
+		f, ok := filters[e.LayoutID]
+		if !ok {
+			var err error
+			if f, err = s.readableStores(c, e.LayoutID); err != nil {
+				return nil, err
+			}
+			filters[e.LayoutID] = f
+		}
+		if f.All || (e.StoreID != "" && f.Allow(e.StoreID)) {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,36 @@
+package infra
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+func TestServer_apiChainEventStats_invalid(t *testing.T) {
+	e := echo.New()
+	tests := []struct {
+		name   string
+		s      *Server
+		target string
+		want   int
+	}{
+		{"disabled events", &Server{log: zap.NewNop().Sugar()}, "/", http.StatusServiceUnavailable},
+		{"unknown dimension", &Server{log: zap.NewNop().Sugar(), evRepo: &fakeEventRepo{}}, "/?group_by=store,device", http.StatusBadRequest},
+		{"unknown bucket", &Server{log: zap.NewNop().Sugar(), evRepo: &fakeEventRepo{}}, "/?group_by=time&bucket=year", http.StatusBadRequest},
+		{"unknown time zone", &Server{log: zap.NewNop().Sugar(), evRepo: &fakeEventRepo{}}, "/?tz=Mars/Olympus", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if err := tt.s.apiChainEventStats(e.NewContext(httptest.NewRequest(http.MethodGet, tt.target, nil), rec)); err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/acl"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/subscription"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	eventAckType string = "ack"
+)
+
+var (
+	errSubscriptionsDisabled error = errors.New("event subscriptions disabled, events.subscriptions.url is empty")
+	errSubscriptionForbidden error = errors.New("subscription of the other user or not permitted layout")
+)
+
+// EventAck acknowledgement of the event received by subscription,
+// sent by POST /v2/chains/events/subscriptions/{subscription_id}/ack or as ack command of the websocket client
+type EventAck struct {
+	ID        string    `json:"id"`
+	EventTime time.Time `json:"event_time"`
+}
+
+// ChainEventSubscriptionsResponse http wrapper for the list of subscriptions
+type ChainEventSubscriptionsResponse struct {
+	Data []*subscription.Subscription `json:"data"`
+}
+
+// apiChainEventSubscriptions docs
+// @Summary Get subscriptions to the events
+// @Description get durable subscriptions of the request user (all for admin) with positions of the last acknowledged and delivered events
+// @Produce json
+// @Tags chains/events
+// @Success 200 {object} infra.ChainEventSubscriptionsResponse
+// @Failure 401 {object} infra.HTTPError
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/chains/events/subscriptions [get]
+func (s *Server) apiChainEventSubscriptions(c echo.Context) error {
+	if s.subs == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errSubscriptionsDisabled))
+	}
+	subs, err := s.subs.List(c.Request().Context())
+	if err != nil {
+		s.log.Errorf("subs.List error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+	}
+	if !s.perm.CheckAdmin(c.Request(), acl.ActionRead) {
+		owner, own := s.actor(c), make([]*subscription.Subscription, 0, len(subs))
+		for _, sub := range subs {
+			if sub.Owner == owner {
+				own = append(own, sub)
+			}
+		}
+		subs = own
+	}
+	return c.JSON(http.StatusOK, ChainEventSubscriptionsResponse{Data: subs})
+}
+
+// apiChainEventSubscriptionByID docs
+// @Summary Get subscription to the events
+// @Description get durable subscription with positions of the last acknowledged and delivered events
+// @Produce json
+// @Tags chains/events
+// @Param subscription_id path string true "subscription id"
+// @Success 200 {object} subscription.Subscription
+// @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/chains/events/subscriptions/{subscription_id} [get]
+func (s *Server) apiChainEventSubscriptionByID(c echo.Context) error {
+	if s.subs == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errSubscriptionsDisabled))
+	}
+	sub, err := s.permittedSubscription(c, c.Param("subscription_id"), acl.ActionRead)
+	if err != nil {
+		return s.subscriptionError(c, err)
+	}
+	return c.JSON(http.StatusOK, sub)
+}
+
+// apiDeleteChainEventSubscription docs
+// @Summary Delete subscription to the events
+// @Description delete durable subscription, next connection with its id starts new subscription
+// @Produce json
+// @Tags chains/events
+// @Param subscription_id path string true "subscription id"
+// @Success 200 {object} infra.SuccessResponse
+// @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/chains/events/subscriptions/{subscription_id} [delete]
+func (s *Server) apiDeleteChainEventSubscription(c echo.Context) error {
+	if s.subs == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errSubscriptionsDisabled))
+	}
+	id := c.Param("subscription_id")
+	if _, err := s.permittedSubscription(c, id, acl.ActionDelete); err != nil {
+		return s.subscriptionError(c, err)
+	}
+	if err := s.subs.Del(c.Request().Context(), id); err != nil {
+		return s.subscriptionError(c, err)
+	}
+	s.log.Infof("event subscription %s deleted", id)
+	return c.JSON(http.StatusOK, OkStatus(fmt.Sprintf("deleted subscription %s", id)))
+}
+
+// apiAckChainEventSubscription docs
+// @Summary Acknowledge event of the subscription
+// @Description acknowledge event processed by the client, reconnected subscription is replayed after the last acknowledged event,
+// @Description acknowledgement of the event older than acknowledged already is ignored
+// @Accept json
+// @Produce json
+// @Tags chains/events
+// @Param subscription_id path string true "subscription id"
+// @Param ack body infra.EventAck true "id and event_time of the event"
+// @Success 200 {object} infra.SuccessResponse
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/chains/events/subscriptions/{subscription_id}/ack [post]
+func (s *Server) apiAckChainEventSubscription(c echo.Context) error {
+	if s.subs == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errSubscriptionsDisabled))
+	}
+	ack := &EventAck{}
+	if err := c.Bind(ack); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	}
+	if ack.ID == "" || ack.EventTime.IsZero() {
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(errors.New("id and event_time of the event required")))
+	}
+	id := c.Param("subscription_id")
+	if _, err := s.permittedSubscription(c, id, acl.ActionUpdate); err != nil {
+		return s.subscriptionError(c, err)
+	}
+	err := subscription.Ack(c.Request().Context(), s.subs, id, subscription.Cursor{Time: ack.EventTime, ID: ack.ID})
+	if err != nil {
+		return s.subscriptionError(c, err)
+	}
+	return c.JSON(http.StatusOK, OkStatus(fmt.Sprintf("acknowledged event %s of subscription %s", ack.ID, id)))
+}
+
+// subscriptionError responses by error of the subscriptions.
+func (s *Server) subscriptionError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, subscription.ErrInvalidID):
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	case errors.Is(err, subscription.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrNotFound(err))
+	case errors.Is(err, errSubscriptionForbidden):
+		return c.JSON(http.StatusForbidden, ErrForbidden(err))
+	case errors.Is(err, errSubscriptionsDisabled):
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(err))
+	}
+	s.log.Errorf("subscription error, %s", err)
+	return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+}
+
+// permittedSubscription returns subscription by id if it's permitted to the request user.
+func (s *Server) permittedSubscription(c echo.Context, id string, action acl.Action) (*subscription.Subscription, error) {
+	sub, err := s.subs.Get(c.Request().Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.subscriptionPermitted(c, sub, action); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// subscriptionPermitted checks access of the request user to subscription:
+// admin is permitted to any, owner - if the layout and store of the filter are readable.
+func (s *Server) subscriptionPermitted(c echo.Context, sub *subscription.Subscription, action acl.Action) error {
+	if s.perm.CheckAdmin(c.Request(), action) {
+		return nil
+	}
+	if sub.Owner != s.actor(c) {
+		s.log.Warnf("subscription %s of the user %s used by %s", sub.ID, sub.Owner, s.actor(c))
+		return errSubscriptionForbidden
+	}
+	if !s.eventPermitted(c, domain.Event{LayoutID: sub.Filter.LayoutID, StoreID: sub.Filter.StoreID}, acl.ActionRead) {
+		return errSubscriptionForbidden
+	}
+	return nil
+}
+
+// subscribeEvents opens durable subscription of the request user and returns channel with missed events
+// after the last acknowledged one, then the live ones.
+func (s *Server) subscribeEvents(ctx context.Context, c echo.Context, id, subscriber string, f subscription.Filter,
+	from time.Time) (<-chan domain.Event, error) {
+	//
+	if s.subs == nil {
+		return nil, errSubscriptionsDisabled
+	}
+	// existing subscription keeps its filter, so access is checked by the stored one
+	owner := s.actor(c)
+	sub, err := s.subs.Get(ctx, id)
+	switch {
+	case errors.Is(err, subscription.ErrNotFound):
+		sub = &subscription.Subscription{ID: id, Owner: owner, Filter: f}
+	case err != nil:
+		return nil, err
+	}
+	if err := s.subscriptionPermitted(c, sub, acl.ActionRead); err != nil {
+		return nil, err
+	}
+	if sub, err = subscription.Open(ctx, s.subs, id, owner, f, from); err != nil {
+		return nil, err
+	}
+	after := sub.Resume()
+	ch, truncated, err := subscription.Stream(s.evRepo, subscriber, sub.Filter, after,
+		s.config.GetInt64("events.subscriptions.replay_limit"), ctx.Done())
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		s.log.Warnf("missed events of subscription %s after %s truncated by events.subscriptions.replay_limit",
+			id, after.Time.Format(time.RFC3339))
+	}
+	return ch, nil
+}
+
+// saveDelivered remembers position of the last event sent by subscription.
+func (s *Server) saveDelivered(id string, last subscription.Cursor) {
+	if last.IsZero() {
+		return
+	}
+	if err := subscription.Delivered(context.Background(), s.subs, id, last); err != nil {
+		s.log.Errorf("save delivered event of subscription %s error, %s", id, err)
+	}
+}
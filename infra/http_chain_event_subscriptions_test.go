@@ -0,0 +1,155 @@
+package infra
+
+import (
+	"context"
+	b64 "encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/subscription"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/subscription/mem"
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+func TestServer_chainEventSubscription(t *testing.T) {
+	t1 := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	e1 := domain.Event{ID: "1", Key: "queue.threshold.exceeded", EventTime: t1, LayoutID: "10"}
+	e2 := domain.Event{ID: "2", Key: "queue.threshold.exceeded", EventTime: t1.Add(time.Second), LayoutID: "10"}
+	s := &Server{log: zap.NewNop().Sugar(), config: viper.New(), mWS: api_websocket_connections,
+		evRepo: &fakeEventRepo{events: domain.Events{e1, e2}}, subs: mem.New(),
+		perm: permission.NewManager(nil, nil, permission.DefaultAllow, time.Hour)}
+	e := echo.New()
+	call := func(method, target, body string, handler echo.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(permission.XUserID, "notifier-svc")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("subscription_id")
+		c.SetParamValues("notifier")
+		if err := handler(c); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		return rec
+	}
+	stream := "/v2/chains/events/stream?subscription_id=notifier&layout_id=10&from=" + t1.Format(time.RFC3339)
+
+	rec := call(http.MethodGet, stream, "", s.serveChainEventsStream)
+	if ids := streamIDs(rec.Body.String()); len(ids) != 2 {
+		t.Fatalf("first stream ids = %v, want 2 events", ids)
+	}
+	rec = call(http.MethodPost, "/", `{"id":"1","event_time":"`+t1.Format(time.RFC3339)+`"}`,
+		s.apiAckChainEventSubscription)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ack status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	// unacknowledged event is replayed again
+	rec = call(http.MethodGet, stream, "", s.serveChainEventsStream)
+	if ids := streamIDs(rec.Body.String()); len(ids) != 1 || ids[0] != subscription.CursorOf(e2).String() {
+		t.Fatalf("resumed stream ids = %v, want %s", ids, subscription.CursorOf(e2))
+	}
+	rec = call(http.MethodGet, "/", "", s.apiChainEventSubscriptionByID)
+	sub := subscription.Subscription{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &sub); err != nil {
+		t.Fatalf("unmarshal subscription error, %s", err)
+	}
+	if sub.Acked != subscription.CursorOf(e1).String() || sub.Delivered != subscription.CursorOf(e2).String() ||
+		sub.Filter.LayoutID != "10" {
+		t.Errorf("subscription = %+v, want acked %s, delivered %s", sub, subscription.CursorOf(e1), subscription.CursorOf(e2))
+	}
+	if rec = call(http.MethodDelete, "/", "", s.apiDeleteChainEventSubscription); rec.Code != http.StatusOK {
+		t.Errorf("delete status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec = call(http.MethodGet, "/", "", s.apiChainEventSubscriptionByID); rec.Code != http.StatusNotFound {
+		t.Errorf("status of deleted = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestServer_chainEventSubscription_forbidden(t *testing.T) {
+	t1 := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := mem.New()
+	s := &Server{log: zap.NewNop().Sugar(), config: viper.New(), mWS: api_websocket_connections,
+		evRepo: &fakeEventRepo{}, subs: repo, perm: permission.NewManager(nil, nil, permission.DefaultAllow, time.Hour)}
+	for _, sub := range []*subscription.Subscription{
+		{ID: "owned", Owner: "operator", Filter: subscription.Filter{LayoutID: "10"}, From: t1},
+		{ID: "other", Owner: "notifier-svc", Filter: subscription.Filter{LayoutID: "20"}, From: t1},
+		{ID: "moved", Owner: "operator", Filter: subscription.Filter{LayoutID: "20"}, From: t1},
+	} {
+		if err := repo.Put(context.Background(), sub); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	layout10 := `[{"resources":["watcom.ru:data.counting:layouts:10"],"actions":["*"],"effect":"allow"}]`
+	admin := `[{"resources":["watcom.ru:data.counting:admin"],"actions":["*"],"effect":"allow"}]`
+	e := echo.New()
+	call := func(user, perm, method, target, id, body string, handler echo.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(permission.XUserID, user)
+		if perm != "" {
+			req.Header.Set(permission.XUserPermission, b64.StdEncoding.EncodeToString([]byte(perm)))
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("subscription_id")
+		c.SetParamValues(id)
+		if err := handler(c); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		return rec
+	}
+	ack := `{"id":"1","event_time":"` + t1.Format(time.RFC3339) + `"}`
+	tests := []struct {
+		name    string
+		perm    string
+		method  string
+		target  string
+		id      string
+		handler func(s *Server) echo.HandlerFunc
+		want    int
+	}{
+		{"get owned", layout10, http.MethodGet, "/", "owned",
+			func(s *Server) echo.HandlerFunc { return s.apiChainEventSubscriptionByID }, http.StatusOK},
+		{"get other", layout10, http.MethodGet, "/", "other",
+			func(s *Server) echo.HandlerFunc { return s.apiChainEventSubscriptionByID }, http.StatusForbidden},
+		{"get not permitted layout", layout10, http.MethodGet, "/", "moved",
+			func(s *Server) echo.HandlerFunc { return s.apiChainEventSubscriptionByID }, http.StatusForbidden},
+		{"ack other", layout10, http.MethodPost, "/", "other",
+			func(s *Server) echo.HandlerFunc { return s.apiAckChainEventSubscription }, http.StatusForbidden},
+		{"delete other", layout10, http.MethodDelete, "/", "other",
+			func(s *Server) echo.HandlerFunc { return s.apiDeleteChainEventSubscription }, http.StatusForbidden},
+		{"resume other", layout10, http.MethodGet, "/v2/chains/events/stream?subscription_id=other&layout_id=10", "",
+			func(s *Server) echo.HandlerFunc { return s.serveChainEventsStream }, http.StatusForbidden},
+		{"get other by admin", admin, http.MethodGet, "/", "other",
+			func(s *Server) echo.HandlerFunc { return s.apiChainEventSubscriptionByID }, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := call("operator", tt.perm, tt.method, tt.target, tt.id, ack, tt.handler(s)); rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		perm string
+		want int
+	}{{layout10, 2}, {admin, 3}} {
+		rec := call("operator", tt.perm, http.MethodGet, "/", "", "", s.apiChainEventSubscriptions)
+		list := ChainEventSubscriptionsResponse{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+			t.Fatalf("unmarshal subscriptions error, %s", err)
+		}
+		if len(list.Data) != tt.want {
+			t.Errorf("listed %d subscriptions, want %d", len(list.Data), tt.want)
+		}
+	}
+}
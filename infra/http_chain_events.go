@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventhub"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/subscription"
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
@@ -27,6 +29,7 @@ type ChainEventsResponse struct {
 // @Description key can be: queue.threshold.exceeded, ...
 // @Description kind can be: business, system, user
 // @Description severity can be: info, warn, alarm
+// @Description status - comma separated statuses of the lifecycle: new, acknowledged, assigned, resolved, filter with new scans events of the range
 // @Description from/to can be: YYYY-MM-DDTHH:mm:ss+07:00 or naive YYYY-MM-DD HH:mm:ss then the server's local timezone is applied
 // @Description fields - comma separated values of field names, can be id,key,event_time,kind,message,severity,layout_id,store_id,source,creator,created_at... all of them described at the model
 // @Produce json
@@ -36,11 +39,12 @@ type ChainEventsResponse struct {
 // @Param key query string false "default=*"
 // @Param kind query string false "default=*"
 // @Param severity query string false "default=*"
+// @Param status query string false "default=*"
 // @Param from query string false "ISO8601 datetime, default begin of day"
 // @Param to query string false "ISO8601 datetime, dafault current time"
 // @Param offset query integer false "default=0"
 // @Param limit query integer false "default=20"
-// @Param fields query string false "id,key,event_time,kind... default=id,key,event_time,message,layout_id,store_id"
+// @Param fields query string false "id,key,event_time,kind... default=id,key,event_time,message,layout_id,store_id,state"
 // @Success 200 {object} infra.ChainEventsResponse
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
@@ -60,10 +64,25 @@ func (s *Server) apiChainEvents(c echo.Context) error { // TODO: remove me to se
 		s.log.Warnf("getFromToParams error, %s", err)
 		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
 	}
-	events, count, err := s.evRepo.FindChainEvents(from, to, layoutID, storeID, key, kind, severity, limit, offset)
-	if err != nil {
+	var (
+		events domain.Events
+		count  int64
+	)
+	if status := c.QueryParam("status"); status != "" {
+		if s.events == nil {
+			return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errLifecycleDisabled))
+		}
+		events, count, err = s.events.FindChainEventsByStatus(from, to, layoutID, storeID, key, kind, severity,
+			strings.Split(status, ","), limit, offset)
+		if err != nil {
+			return s.lifecycleError(c, err)
+		}
+	} else {
+		events, count, err = s.evRepo.FindChainEvents(from, to, layoutID, storeID, key, kind, severity, limit, offset)
+		if err != nil {
 
-		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+			return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+		}
 	}
 	if len(events) == 0 {
 		response := ChainEventsResponse{
@@ -81,7 +100,7 @@ func (s *Server) apiChainEvents(c echo.Context) error { // TODO: remove me to se
 	}
 	fields := strings.Split(c.QueryParam("fields"), ",")
 	if c.QueryParam("fields") == "" {
-		fields = []string{"id", "key", "event_time", "message", "layout_id", "store_id"}
+		fields = []string{"id", "key", "event_time", "message", "layout_id", "store_id", "state"}
 	}
 	events.SetZeroValue(fields)
 	response := ChainEventsResponse{
@@ -98,6 +117,12 @@ func (s *Server) apiChainEvents(c echo.Context) error { // TODO: remove me to se
 	return c.JSON(http.StatusOK, response)
 }
 
+// serveChainEventsWS websocket of the events, the first text message is parameters (RequestEvent),
+// they make the filter with id default, then client can send commands (EventCommand):
+// subscribe, update-filter, unsubscribe of the filters with lists of values and ack of the subscription events,
+// every command is replied by ack or error frame (EventFrame); events matching any of the filters are sent as is,
+// with events.hub.isuse connections without from and subscription_id share the consumer of the hub
+// by the topic of their filters, changed filters move the connection to the consumer of the new topic
 func (s *Server) serveChainEventsWS(c echo.Context) error {
 	subscriber := c.Request().RemoteAddr
 	query := c.Request().URL.Path
@@ -128,7 +153,7 @@ LOOPPARAMS:
 		if err != nil {
 			s.log.Warnf("got wrong format message %s, need RequestEvent format", string(msg))
 			// write error to ws
-			err := ws.WriteMessage(websocket.TextMessage, []byte("wrong format"))
+			err := ws.WriteJSON(EventFrame{Type: wsFrameError, Error: fmt.Sprintf("wrong format, %s", err)})
 			if err != nil {
 				log.Errorf("ws write error, %v abotring...", err)
 				return c.JSON(http.StatusInternalServerError,
@@ -149,31 +174,60 @@ LOOPPARAMS:
 	if p.From != nil {
 		t = *p.From
 	}
-	chEvents := s.evRepo.FindConsumerChainEvents(subscriber,
-		p.LayoutID, p.StoreID, p.Key, p.Kind, p.Severity, t, ctx.Done())
+	conn := newEventsConn(ws)
+	var chEvents <-chan domain.Event
+	if p.SubscriptionID != "" {
+		f := subscription.Filter{LayoutID: p.LayoutID, StoreID: p.StoreID, Key: p.Key, Kind: p.Kind, Severity: p.Severity}
+		chEvents, err = s.subscribeEvents(ctx, c, p.SubscriptionID, subscriber, f, t)
+		if err != nil {
+			s.log.Warnf("subscription %s error, %s", p.SubscriptionID, err)
+			_ = ws.WriteMessage(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()))
+			return nil
+		}
+		conn.subID = p.SubscriptionID
+		defer func() { s.saveDelivered(conn.subID, conn.sent) }()
+	} else {
+		// filters can be changed by commands, so events are filtered here
+		conn.filters[defaultFilterID] = filterOf(p)
+		if s.hub != nil && p.From == nil {
+			// live connections with the same filters share the consumer of the hub
+			conn.topic = topicOf(conn.filters)
+			conn.sub = s.hub.Subscribe(conn.topic)
+			conn.resubscribe = make(chan *eventhub.Subscriber)
+			defer func() { conn.sub.Close() }()
+			chEvents = conn.sub.Events()
+		} else {
+			chEvents = s.evRepo.FindConsumerChainEvents(subscriber, "", "", "", "", "", t, ctx.Done())
+		}
+	}
+	// reader cancels writer when client disconnects
+	go s.readEventCommands(ctx, conn, cancel)
 
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
-	go s.writeToChainEventWS(chEvents, ws, wg, ctx.Done())
+	go s.writeToChainEventWS(chEvents, conn, wg, ctx.Done())
 
 	wg.Wait()
 	return nil
 }
 
+// writeToChainEventWS writes events matching filters of the connection and replies to commands.
 func (s *Server) writeToChainEventWS(in <-chan domain.Event,
-	ws *websocket.Conn, wg *sync.WaitGroup, cancel <-chan struct{}) {
+	conn *eventsConn, wg *sync.WaitGroup, cancel <-chan struct{}) {
 	//
 	log.Debug("start read event channel and write to ws")
 	defer wg.Done()
 	defer log.Debug("stop read event channel and write to ws")
-	tick := time.NewTicker(5 * time.Second)
+	tick := time.NewTicker(periodEventsPing)
 	defer tick.Stop()
 	for {
 		select {
 		case <-cancel:
-			err := ws.WriteMessage(websocket.CloseMessage, []byte("cancelled"))
+			// client can be disconnected already
+			err := conn.ws.WriteMessage(websocket.CloseMessage, []byte("cancelled"))
 			if err != nil {
-				s.log.Errorf("write ws message error, %s", err)
+				s.log.Debugf("write ws message error, %s", err)
 			}
 			return
 		case e, more := <-in:
@@ -181,13 +235,27 @@ func (s *Server) writeToChainEventWS(in <-chan domain.Event,
 				log.Warn("event channel closed, aborting...")
 				return
 			}
-			err := ws.WriteJSON(e)
+			if !conn.match(e) {
+				continue
+			}
+			err := conn.ws.WriteJSON(e)
 			if err != nil {
 				log.Errorf("ws error, %v, aborted...", err)
 				return
 			}
+			if e.Change == nil {
+				conn.sent = subscription.CursorOf(e)
+			}
+		case sub := <-conn.resubscribe:
+			conn.sub.Close()
+			conn.sub, in = sub, sub.Events()
+		case f := <-conn.frames:
+			if err := conn.ws.WriteJSON(f); err != nil {
+				log.Errorf("ws error, %v, aborted...", err)
+				return
+			}
 		case t := <-tick.C:
-			err := ws.WriteMessage(websocket.PingMessage, []byte(t.String()))
+			err := conn.ws.WriteMessage(websocket.PingMessage, []byte(t.String()))
 			if err != nil {
 				log.Errorf("ws error, %v, aborted...", err)
 				return
@@ -196,3 +264,18 @@ func (s *Server) writeToChainEventWS(in <-chan domain.Event,
 
 	}
 }
+
+// findAllChainEvents requests events page by page by find with limit and offset,
+// fails with errTooManyRows instead of truncating the events.
+func (s *Server) findAllChainEvents(find func(limit, offset int64) (domain.Events, int64, error)) (domain.Events, error) {
+	res := make(domain.Events, 0)
+	err := readPages(func(offset, limit int64) (int, int64, error) {
+		events, total, err := find(limit, offset)
+		res = append(res, events...)
+		return len(events), total, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,251 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/acl"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstate"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/queuerule"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	anonymousActor string = "anonymous"
+)
+
+var (
+	errLifecycleDisabled error = errors.New("events lifecycle disabled, events.lifecycle.url is empty")
+	errReservedActor     error = errors.New("user id reserved for the service")
+)
+
+// reservedActors actors of the events made by the service itself, users can't act on their behalf
+var reservedActors = []string{queuerule.Actor}
+
+// EventTransitionRequest parameters of the transition of the event,
+// event_time is required for the event without transitions made by API
+type EventTransitionRequest struct {
+	EventTime *time.Time `json:"event_time,omitempty"`
+	Assignee  string     `json:"assignee,omitempty"`
+	Comment   string     `json:"comment,omitempty"`
+}
+
+// apiCreateChainEvent docs
+// @Summary Create event for the retail schema
+// @Description create user or system event, id is generated if empty, event_time is current time if empty,
+// @Description severity can be: info (default), warn, alarm; creator is the user of the request,
+// @Description event is sent to the consumers of the events stream with change create,
+// @Description user needs create permission for the layout and store of the event
+// @Accept json
+// @Produce json
+// @Tags chains/events
+// @Param event body domain.Event true "key, kind, severity, message, layout_id, store_id, source"
+// @Success 201 {object} domain.Event
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 409 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/chains/events [post]
+func (s *Server) apiCreateChainEvent(c echo.Context) error {
+	if s.events == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errLifecycleDisabled))
+	}
+	e := domain.Event{}
+	if err := c.Bind(&e); err != nil {
+		s.log.Errorf("apiCreateChainEvent, bad request error %v", err)
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	}
+	actor, err := s.eventActor(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrUnAuthorized(err))
+	}
+	if !s.eventPermitted(c, e, acl.ActionCreate) {
+		s.log.Warnf("not permitted create of the event for layout %s and store %s", e.LayoutID, e.StoreID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
+	e, err = s.events.Create(c.Request().Context(), e, actor)
+	if err != nil {
+		return s.lifecycleError(c, err)
+	}
+	s.log.Infof("event %s %s created by %s", e.ID, e.Key, e.Creator)
+	return c.JSON(http.StatusCreated, e)
+}
+
+// apiAcknowledgeChainEvent docs
+// @Summary Acknowledge event
+// @Description acknowledge new event, actor is the user of the request
+// @Accept json
+// @Produce json
+// @Tags chains/events
+// @Param event_id path string true "event id"
+// @Param transition body infra.EventTransitionRequest false "event_time of the event without transitions"
+// @Success 200 {object} domain.Event
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 409 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/chains/events/{event_id}/acknowledge [post]
+func (s *Server) apiAcknowledgeChainEvent(c echo.Context) error {
+	return s.transitChainEvent(c, eventstate.ActionAcknowledge)
+}
+
+// apiAssignChainEvent docs
+// @Summary Assign event
+// @Description assign not resolved event to assignee, actor is the user of the request
+// @Accept json
+// @Produce json
+// @Tags chains/events
+// @Param event_id path string true "event id"
+// @Param transition body infra.EventTransitionRequest true "assignee and event_time of the event without transitions"
+// @Success 200 {object} domain.Event
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 409 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/chains/events/{event_id}/assign [post]
+func (s *Server) apiAssignChainEvent(c echo.Context) error {
+	return s.transitChainEvent(c, eventstate.ActionAssign)
+}
+
+// apiCommentChainEvent docs
+// @Summary Comment event
+// @Description add comment to the event in any status, actor is the user of the request
+// @Accept json
+// @Produce json
+// @Tags chains/events
+// @Param event_id path string true "event id"
+// @Param transition body infra.EventTransitionRequest true "comment and event_time of the event without transitions"
+// @Success 200 {object} domain.Event
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/chains/events/{event_id}/comment [post]
+func (s *Server) apiCommentChainEvent(c echo.Context) error {
+	return s.transitChainEvent(c, eventstate.ActionComment)
+}
+
+// apiResolveChainEvent docs
+// @Summary Resolve event
+// @Description resolve not resolved event, comment is optional, actor is the user of the request
+// @Accept json
+// @Produce json
+// @Tags chains/events
+// @Param event_id path string true "event id"
+// @Param transition body infra.EventTransitionRequest false "comment and event_time of the event without transitions"
+// @Success 200 {object} domain.Event
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 409 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/chains/events/{event_id}/resolve [post]
+func (s *Server) apiResolveChainEvent(c echo.Context) error {
+	return s.transitChainEvent(c, eventstate.ActionResolve)
+}
+
+// transitChainEvent applies transition by action to the event of the request,
+// user needs update permission for the layout and store of the event.
+func (s *Server) transitChainEvent(c echo.Context, action string) error {
+	if s.events == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errLifecycleDisabled))
+	}
+	id := c.Param("event_id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(errEmptyID))
+	}
+	p := &EventTransitionRequest{}
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(p); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+		}
+	}
+	actor, err := s.eventActor(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrUnAuthorized(err))
+	}
+	at := time.Time{}
+	if p.EventTime != nil {
+		at = *p.EventTime
+	}
+	e, err := s.events.Find(c.Request().Context(), id, at)
+	if err != nil {
+		return s.lifecycleError(c, err)
+	}
+	if !s.eventPermitted(c, e, acl.ActionUpdate) {
+		s.log.Warnf("not permitted %s of the event %s for layout %s and store %s", action, id, e.LayoutID, e.StoreID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
+	t := domain.EventTransition{Action: action, Actor: actor, Assignee: p.Assignee, Comment: p.Comment}
+	e, err = s.events.Transition(c.Request().Context(), id, at, t)
+	if err != nil {
+		return s.lifecycleError(c, err)
+	}
+	s.log.Infof("event %s %s by %s, status %s", id, action, t.Actor, e.Status())
+	return c.JSON(http.StatusOK, e)
+}
+
+// lifecycleError responses by error of the events lifecycle.
+func (s *Server) lifecycleError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, eventstate.ErrInvalid), errors.Is(err, eventstate.ErrScanLimit):
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	case errors.Is(err, eventstate.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrNotFound(err))
+	case errors.Is(err, eventstate.ErrExists), errors.Is(err, eventstate.ErrTransition):
+		return c.JSON(http.StatusConflict, ErrConflict(err))
+	}
+	s.log.Errorf("events lifecycle error, %s", err)
+	return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+}
+
+// actor returns user of the request for the history of the events.
+func (s *Server) actor(c echo.Context) string {
+	uid, email := s.getUserInfo(c)
+	switch {
+	case uid != "":
+		return uid
+	case email != "":
+		return email
+	}
+	return anonymousActor
+}
+
+// eventActor returns user of the request acting on the events, user ids of the service itself are rejected.
+func (s *Server) eventActor(c echo.Context) (string, error) {
+	actor := s.actor(c)
+	for _, reserved := range reservedActors {
+		if actor == reserved {
+			s.log.Warnf("user id %s reserved for the service", actor)
+			return "", errReservedActor
+		}
+	}
+	return actor, nil
+}
+
+// eventPermitted reports whether action is permitted for the layout and store of the event,
+// event without layout needs permission for the all layouts.
+func (s *Server) eventPermitted(c echo.Context, e domain.Event, action acl.Action) bool {
+	layoutID := e.LayoutID
+	if layoutID == "" {
+		layoutID = "*"
+	}
+	if e.StoreID == "" {
+		return s.perm.CheckLayout(c.Request(), layoutID, action)
+	}
+	return s.perm.CheckStore(c.Request(), layoutID, e.StoreID, action)
+}
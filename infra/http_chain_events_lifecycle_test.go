@@ -0,0 +1,152 @@
+package infra
+
+import (
+	"context"
+	b64 "encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstate"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstate/mem"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+func TestServer_chainEventLifecycle(t *testing.T) {
+	t1 := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	alarm := domain.Event{ID: "1", Key: "queue.threshold.exceeded", Kind: "business", Severity: "alarm",
+		EventTime: t1, LayoutID: "10"}
+	events := eventstate.New(&fakeEventRepo{events: domain.Events{alarm}}, mem.New())
+	s := &Server{log: zap.NewNop().Sugar(), evRepo: events, events: events,
+		perm: permission.NewManager(nil, nil, permission.DefaultAllow, time.Hour)}
+	e := echo.New()
+	call := func(method, target, body, id string, handler echo.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(permission.XUserID, "operator")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("event_id")
+		c.SetParamValues(id)
+		if err := handler(c); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		return rec
+	}
+	event := func(rec *httptest.ResponseRecorder) domain.Event {
+		ev := domain.Event{}
+		if err := ev.UnmarshalJSON(rec.Body.Bytes()); err != nil {
+			t.Fatalf("unmarshal event error, %s", err)
+		}
+		return ev
+	}
+
+	rec := call(http.MethodPost, "/v2/chains/events", `{"key":"door.open","kind":"user","layout_id":"10",
+		"event_time":"2021-06-01T12:30:00Z"}`, "", s.apiCreateChainEvent)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d, %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if created := event(rec); created.ID == "" || created.Creator != "operator" || created.Status() != domain.EventStatusNew {
+		t.Errorf("created event = %+v, want new event of operator", created)
+	}
+	if rec = call(http.MethodPost, "/", `{"key":"door.open","kind":"business"}`, "", s.apiCreateChainEvent); rec.Code != http.StatusBadRequest {
+		t.Errorf("create of business event status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	// the database event is found by event_time on the first transition
+	rec = call(http.MethodPost, "/", `{"event_time":"2021-06-01T12:00:00Z"}`, "1", s.apiAcknowledgeChainEvent)
+	if rec.Code != http.StatusOK || event(rec).Status() != domain.EventStatusAcknowledged {
+		t.Fatalf("acknowledge status = %d, %s, want acknowledged", rec.Code, rec.Body.String())
+	}
+	if rec = call(http.MethodPost, "/", "", "1", s.apiAcknowledgeChainEvent); rec.Code != http.StatusConflict {
+		t.Errorf("acknowledge twice status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec = call(http.MethodPost, "/", `{"comment":"checked"}`, "1", s.apiCommentChainEvent); rec.Code != http.StatusOK {
+		t.Errorf("comment status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	rec = call(http.MethodPost, "/", "", "1", s.apiResolveChainEvent)
+	resolved := event(rec)
+	if rec.Code != http.StatusOK || resolved.Status() != domain.EventStatusResolved || len(resolved.State.History) != 3 ||
+		resolved.State.History[2].Actor != "operator" {
+		t.Errorf("resolve status = %d, state %+v, want resolved by operator after 3 transitions", rec.Code, resolved.State)
+	}
+	if rec = call(http.MethodPost, "/", "", "unknown", s.apiResolveChainEvent); rec.Code != http.StatusNotFound {
+		t.Errorf("resolve of unknown status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	for status, want := range map[string]string{"resolved": `"id":"1"`, "new": `"key":"door.open"`, "closed": ""} {
+		rec = call(http.MethodGet, "/v2/chains/events?status="+status+"&from=2021-06-01T00:00:00Z&to=2021-06-02T00:00:00Z",
+			"", "", s.apiChainEvents)
+		if want == "" {
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("events with status %s code = %d, want %d", status, rec.Code, http.StatusBadRequest)
+			}
+			continue
+		}
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), want) || !strings.Contains(rec.Body.String(), `"total":1`) {
+			t.Errorf("events with status %s = %s, want one with %s", status, rec.Body.String(), want)
+		}
+	}
+}
+
+func TestServer_chainEventLifecycle_forbidden(t *testing.T) {
+	t1 := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	alarm := domain.Event{ID: "1", Key: "queue.threshold.exceeded", Kind: "business", Severity: "alarm",
+		EventTime: t1, LayoutID: "10"}
+	events := eventstate.New(&fakeEventRepo{events: domain.Events{alarm}}, mem.New())
+	s := &Server{log: zap.NewNop().Sugar(), evRepo: events, events: events,
+		perm: permission.NewManager(nil, nil, permission.DefaultAllow, time.Hour)}
+	layout20 := `[{"resources":["watcom.ru:data.counting:layouts:20"],"actions":["*"],"effect":"allow"}]`
+	tests := []struct {
+		name    string
+		user    string
+		perm    string
+		body    string
+		id      string
+		handler func(s *Server) echo.HandlerFunc
+		want    int
+	}{
+		{"create reserved", "queuerule", "", `{"key":"door.open","kind":"user","layout_id":"10"}`, "",
+			func(s *Server) echo.HandlerFunc { return s.apiCreateChainEvent }, http.StatusUnauthorized},
+		{"create other layout", "operator", layout20, `{"key":"door.open","kind":"user","layout_id":"10"}`, "",
+			func(s *Server) echo.HandlerFunc { return s.apiCreateChainEvent }, http.StatusForbidden},
+		{"create without layout", "operator", layout20, `{"key":"door.open","kind":"user"}`, "",
+			func(s *Server) echo.HandlerFunc { return s.apiCreateChainEvent }, http.StatusForbidden},
+		{"resolve reserved", "queuerule", "", `{"event_time":"2021-06-01T12:00:00Z"}`, "1",
+			func(s *Server) echo.HandlerFunc { return s.apiResolveChainEvent }, http.StatusUnauthorized},
+		{"resolve other layout", "operator", layout20, `{"event_time":"2021-06-01T12:00:00Z"}`, "1",
+			func(s *Server) echo.HandlerFunc { return s.apiResolveChainEvent }, http.StatusForbidden},
+		{"create permitted", "operator", layout20, `{"key":"door.open","kind":"user","layout_id":"20"}`, "",
+			func(s *Server) echo.HandlerFunc { return s.apiCreateChainEvent }, http.StatusCreated},
+	}
+	e := echo.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(permission.XUserID, tt.user)
+			if tt.perm != "" {
+				req.Header.Set(permission.XUserPermission, b64.StdEncoding.EncodeToString([]byte(tt.perm)))
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("event_id")
+			c.SetParamValues(tt.id)
+			if err := tt.handler(s)(c); err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+	// denied transition is not applied
+	if got, err := events.Find(context.Background(), "1", t1); err != nil || got.Status() != domain.EventStatusNew {
+		t.Errorf("event after denied transitions = %+v, %v, want new", got, err)
+	}
+}
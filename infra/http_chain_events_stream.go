@@ -0,0 +1,168 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/subscription"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerLastEventID string        = "Last-Event-ID"
+	periodEventsPing  time.Duration = 5 * time.Second
+	sseRetry          time.Duration = 3 * time.Second
+)
+
+var (
+	errEventsDisabled error = errors.New("events are disabled, events.isuse is false")
+)
+
+// serveChainEventsStream docs
+// @Summary Stream of the events for the retail schema
+// @Description server-sent events (text/event-stream) of the events with layout_id, store_id, key, kind, severity filters, same as websocket /v2/chains/events/ws
+// @Description every event is sent with id, reconnected client passes the last received id in the Last-Event-ID header and the stream is resumed after that event,
+// @Description events with the same event_time as the last received one can be repeated,
+// @Description changes of the event states are sent without id with the field change
+// @Description with subscription_id the stream is durable subscription, it is resumed after the last acknowledged event of the subscription
+// @Description (POST /v2/chains/events/subscriptions/{subscription_id}/ack), filters of the existing subscription are kept
+// @Description with events.hub.isuse streams without from, Last-Event-ID and subscription_id share the consumer of the hub by the filters
+// @Description heartbeat comments are sent every 5 seconds
+// @Produce text/event-stream
+// @Tags chains/events
+// @Param layout_id query string false "default=*"
+// @Param store_id query string false "default=*"
+// @Param key query string false "default=*"
+// @Param kind query string false "default=*"
+// @Param severity query string false "default=*"
+// @Param from query string false "ISO8601 datetime, default current time, ignored with Last-Event-ID"
+// @Param subscription_id query string false "id of the durable subscription, only owner and admin can resume it"
+// @Param Last-Event-ID header string false "id of the last received event, ignored with subscription_id"
+// @Success 200 {object} domain.Event "data of the every message"
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/chains/events/stream [get]
+func (s *Server) serveChainEventsStream(c echo.Context) error {
+	if s.evRepo == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errEventsDisabled))
+	}
+	from := time.Now()
+	if c.QueryParam("from") != "" {
+		var err error
+		if from, _, err = s.getFromToParams(c); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+		}
+	}
+	after := subscription.Cursor{Time: from}
+	if lastID := c.Request().Header.Get(headerLastEventID); lastID != "" {
+		var err error
+		if after, err = subscription.ParseCursor(lastID); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+		}
+	}
+	subscriber := c.Request().RemoteAddr
+	query := c.Request().URL.Path
+	s.log.Debugf("connected new stream client, remoteAddr: %s, agent: %s, from: %s",
+		subscriber, c.Request().UserAgent(), after.Time.Format(time.RFC3339Nano))
+	// stream is cancelled when client disconnects
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+	var chEvents <-chan domain.Event
+	subID := c.QueryParam("subscription_id")
+	if subID != "" {
+		f := subscription.Filter{LayoutID: c.QueryParam("layout_id"), StoreID: c.QueryParam("store_id"),
+			Key: c.QueryParam("key"), Kind: c.QueryParam("kind"), Severity: c.QueryParam("severity")}
+		var err error
+		if chEvents, err = s.subscribeEvents(ctx, c, subID, subscriber, f, from); err != nil {
+			return s.subscriptionError(c, err)
+		}
+		// subscription skips received events itself
+		after = subscription.Cursor{}
+	} else if s.hub != nil && c.QueryParam("from") == "" && c.Request().Header.Get(headerLastEventID) == "" {
+		// live streams with the same filters share the consumer of the hub
+		sub := s.hub.Subscribe(topicOf(map[string]EventFilter{defaultFilterID: filterOf(&RequestEvent{
+			LayoutID: c.QueryParam("layout_id"), StoreID: c.QueryParam("store_id"), Key: c.QueryParam("key"),
+			Kind: c.QueryParam("kind"), Severity: c.QueryParam("severity")})}))
+		defer sub.Close()
+		chEvents = sub.Events()
+		// hub sends events from the time of subscription
+		after = subscription.Cursor{}
+	} else {
+		chEvents = s.evRepo.FindConsumerChainEvents(subscriber,
+			c.QueryParam("layout_id"), c.QueryParam("store_id"), c.QueryParam("key"), c.QueryParam("kind"),
+			c.QueryParam("severity"), after.Time, ctx.Done())
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no") // disable buffering by nginx
+	res.WriteHeader(http.StatusOK)
+	s.mWS.WithLabelValues(query).Inc()
+	defer s.mWS.WithLabelValues(query).Dec()
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", sseRetry.Milliseconds()); err != nil {
+		return nil
+	}
+	res.Flush()
+
+	var sent subscription.Cursor
+	if subID != "" {
+		defer func() { s.saveDelivered(subID, sent) }()
+	}
+	tick := time.NewTicker(periodEventsPing)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Debugf("stream client %s disconnected", subscriber)
+			return nil
+		case e, more := <-chEvents:
+			if !more {
+				s.log.Warn("event channel closed, aborting...")
+				return nil
+			}
+			// already received by the resumed client
+			// changes of the states are sent for the received events too
+			if e.Change == nil && after.Covers(e) {
+				continue
+			}
+			if err := writeSSEEvent(res, e); err != nil {
+				s.log.Errorf("write event to stream %s error, %v, aborted...", subscriber, err)
+				return nil
+			}
+			res.Flush()
+			if e.Change == nil {
+				sent = subscription.CursorOf(e)
+			}
+		case t := <-tick.C:
+			if _, err := fmt.Fprintf(res, ": ping %s\n\n", t.Format(time.RFC3339)); err != nil {
+				s.log.Errorf("write heartbeat to stream %s error, %v, aborted...", subscriber, err)
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// writeSSEEvent writes event as message of the text/event-stream.
+func writeSSEEvent(w io.Writer, e domain.Event) error {
+	data, err := e.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	// change of the state doesn't move position of the stream
+	if e.Change != nil {
+		_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\ndata: %s\n\n", subscription.CursorOf(e), data)
+	return err
+}
@@ -0,0 +1,140 @@
+package infra
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventhub"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/subscription"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// fakeEventRepo sends events from the time of subscription and closes channel.
+type fakeEventRepo struct {
+	events     domain.Events
+	from       time.Time
+	layout     string
+	subscriber string
+}
+
+func (r *fakeEventRepo) FindChainEvents(from, to time.Time, layoutID, storeID, key, kind, severity string,
+	limit, offset int64) (domain.Events, int64, error) {
+	return r.events, int64(len(r.events)), nil
+}
+
+func (r *fakeEventRepo) FindConsumerChainEvents(subscriberID, layoutID, storeID, key, kind, severity string,
+	from time.Time, cancel <-chan struct{}) chan domain.Event {
+	r.from, r.layout, r.subscriber = from, layoutID, subscriberID
+	ch := make(chan domain.Event, len(r.events))
+	for _, e := range r.events {
+		if !e.EventTime.Before(from) {
+			ch <- e
+		}
+	}
+	close(ch)
+	return ch
+}
+
+func TestServer_serveChainEventsStream(t *testing.T) {
+	t1 := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	e1 := domain.Event{ID: "1", Key: "queue.threshold.exceeded", EventTime: t1, LayoutID: "10"}
+	e2 := domain.Event{ID: "2", Key: "queue.threshold.exceeded", EventTime: t1.Add(time.Second), LayoutID: "10"}
+	tests := []struct {
+		name     string
+		lastID   string
+		wantCode int
+		wantFrom time.Time
+		want     []string
+	}{
+		{"from", "", http.StatusOK, t1, []string{subscription.CursorOf(e1).String(), subscription.CursorOf(e2).String()}},
+		{"resumed", subscription.CursorOf(e1).String(), http.StatusOK, t1, []string{subscription.CursorOf(e2).String()}},
+		{"wrong_last_id", "12345", http.StatusBadRequest, time.Time{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeEventRepo{events: domain.Events{e1, e2}}
+			s := &Server{log: zap.NewNop().Sugar(), mWS: api_websocket_connections, evRepo: repo}
+			req := httptest.NewRequest(http.MethodGet,
+				"/v2/chains/events/stream?layout_id=10&from="+t1.Format(time.RFC3339), nil)
+			if tt.lastID != "" {
+				req.Header.Set(headerLastEventID, tt.lastID)
+			}
+			rec := httptest.NewRecorder()
+			if err := s.serveChainEventsStream(echo.New().NewContext(req, rec)); err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			if !repo.from.Equal(tt.wantFrom) || repo.layout != "10" {
+				t.Errorf("subscribed from %s to layout %s, want from %s to layout 10", repo.from, repo.layout, tt.wantFrom)
+			}
+			if ct := rec.Header().Get(echo.HeaderContentType); ct != "text/event-stream" {
+				t.Errorf("Content-Type = %s, want text/event-stream", ct)
+			}
+			body := rec.Body.String()
+			if !strings.HasPrefix(body, "retry: 3000\n\n") {
+				t.Errorf("stream must start with retry, got %q", body)
+			}
+			if ids := streamIDs(body); strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ids of the events = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_serveChainEventsStream_hub(t *testing.T) {
+	now := time.Now()
+	e1 := domain.Event{ID: "1", EventTime: now.Add(time.Minute), LayoutID: "10", StoreID: "2"}
+	e2 := domain.Event{ID: "2", EventTime: now.Add(2 * time.Minute), LayoutID: "10", StoreID: "2"}
+	repo := &fakeEventRepo{events: domain.Events{e1, e2}}
+	hub, err := eventhub.New(context.Background(), repo, eventhub.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	s := &Server{log: zap.NewNop().Sugar(), mWS: api_websocket_connections, evRepo: repo, hub: hub}
+	req := httptest.NewRequest(http.MethodGet, "/v2/chains/events/stream?layout_id=10&store_id=2&kind=*", nil)
+	rec := httptest.NewRecorder()
+	if err := s.serveChainEventsStream(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if want := "hub:10/2///"; repo.subscriber != want {
+		t.Errorf("consumer %q, want %q of the hub", repo.subscriber, want)
+	}
+	want := []string{subscription.CursorOf(e1).String(), subscription.CursorOf(e2).String()}
+	if ids := streamIDs(rec.Body.String()); strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("ids of the events = %v, want %v", ids, want)
+	}
+}
+
+func TestServer_serveChainEventsStreamDisabled(t *testing.T) {
+	s := &Server{log: zap.NewNop().Sugar()}
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v2/chains/events/stream", nil)
+	if err := s.serveChainEventsStream(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+// streamIDs returns ids of the events sent to the stream.
+func streamIDs(body string) []string {
+	ids := make([]string, 0)
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "id: ") {
+			ids = append(ids, strings.TrimPrefix(line, "id: "))
+		}
+	}
+	return ids
+}
@@ -0,0 +1,280 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventhub"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/subscription"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsCommandSubscribe    string = "subscribe"
+	wsCommandUnsubscribe  string = "unsubscribe"
+	wsCommandUpdateFilter string = "update-filter"
+	wsFrameAck            string = "ack"
+	wsFrameError          string = "error"
+	// defaultFilterID id of the filter made by the first parameters message
+	defaultFilterID string = "default"
+	maxEventFilters int    = 32
+	wsFramesBuffer  int    = 16
+)
+
+// EventFilter values of the event fields, event matches if every field is one of the values,
+// empty list matches any value
+type EventFilter struct {
+	LayoutID []string `json:"layout_id,omitempty"`
+	StoreID  []string `json:"store_id,omitempty"`
+	Key      []string `json:"key,omitempty"`
+	Kind     []string `json:"kind,omitempty"`
+	Severity []string `json:"severity,omitempty"`
+}
+
+// EventCommand message of the websocket client after parameters:
+// subscribe adds filter, update-filter replaces filter, unsubscribe removes filter by filter_id,
+// ack acknowledges event of the durable subscription by id and event_time
+type EventCommand struct {
+	Type      string       `json:"type"`
+	FilterID  string       `json:"filter_id,omitempty"`
+	Filter    *EventFilter `json:"filter,omitempty"`
+	ID        string       `json:"id,omitempty"`
+	EventTime time.Time    `json:"event_time,omitempty"`
+}
+
+// EventFrame reply of the server to the command of the websocket client, type is ack or error,
+// events are sent as is without type
+type EventFrame struct {
+	Type     string `json:"type"`
+	Command  string `json:"command,omitempty"`
+	FilterID string `json:"filter_id,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// filterOf returns filter by the parameters of the websocket client.
+func filterOf(p *RequestEvent) EventFilter {
+	f := EventFilter{}
+	for _, v := range []struct {
+		dst *[]string
+		val string
+	}{
+		{&f.LayoutID, p.LayoutID}, {&f.StoreID, p.StoreID}, {&f.Key, p.Key}, {&f.Kind, p.Kind}, {&f.Severity, p.Severity},
+	} {
+		if v.val != "" {
+			*v.dst = []string{v.val}
+		}
+	}
+	return f
+}
+
+// Match reports whether event matches filter.
+func (f EventFilter) Match(e domain.Event) bool {
+	return oneOf(f.LayoutID, e.LayoutID) && oneOf(f.StoreID, e.StoreID) && oneOf(f.Key, e.Key) &&
+		oneOf(f.Kind, e.Kind) && oneOf(f.Severity, e.Severity)
+}
+
+// topicOf returns topic of the hub consumer by the filters: fields with the same single value
+// in every filter narrow the consumer, other fields are matched by the connection.
+func topicOf(filters map[string]EventFilter) eventhub.Topic {
+	var common [5]string
+	n := 0
+	for _, f := range filters {
+		for i, values := range [5][]string{f.LayoutID, f.StoreID, f.Key, f.Kind, f.Severity} {
+			v := ""
+			if len(values) == 1 && !isAnyParam(values[0]) {
+				v = values[0]
+			}
+			if n == 0 {
+				common[i] = v
+			} else if common[i] != v {
+				common[i] = ""
+			}
+		}
+		n++
+	}
+	return eventhub.Topic{LayoutID: common[0], StoreID: common[1], Key: common[2], Kind: common[3], Severity: common[4]}
+}
+
+// consumeAllEvents returns all new events for the internal subscriber till ctx is done,
+// with events.hub.isuse the subscriber shares the consumer of the hub without filters.
+func (s *Server) consumeAllEvents(ctx context.Context, subscriber string) <-chan domain.Event {
+	if s.hub == nil {
+		return s.evRepo.FindConsumerChainEvents(subscriber, "", "", "", "", "", time.Now(), ctx.Done())
+	}
+	sub := s.hub.Subscribe(eventhub.Topic{})
+	go func() {
+		<-ctx.Done()
+		sub.Close()
+	}()
+	return sub.Events()
+}
+
+func oneOf(values []string, v string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, val := range values {
+		if val == v {
+			return true
+		}
+	}
+	return false
+}
+
+// eventsConn websocket connection of the events consumer, events are written
+// if they match any of the filters, filters are changed by commands of the client.
+type eventsConn struct {
+	ws *websocket.Conn
+	// subID id of the durable subscription, its events are filtered by the stored filter
+	subID  string
+	frames chan EventFrame
+	mu     sync.RWMutex
+	// filters guarded by mu, changed by reader
+	filters map[string]EventFilter
+	// sent position of the last written event, used by writer only
+	sent subscription.Cursor
+	// sub consumer of the hub, replaced by writer
+	sub *eventhub.Subscriber
+	// topic of the hub consumer, used by reader only
+	topic eventhub.Topic
+	// resubscribe passes consumer of the new topic from reader to writer, nil without hub
+	resubscribe chan *eventhub.Subscriber
+}
+
+func newEventsConn(ws *websocket.Conn) *eventsConn {
+	return &eventsConn{
+		ws:      ws,
+		frames:  make(chan EventFrame, wsFramesBuffer),
+		filters: make(map[string]EventFilter),
+	}
+}
+
+// match reports whether event matches any filter of the connection.
+func (c *eventsConn) match(e domain.Event) bool {
+	if c.subID != "" {
+		return true
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, f := range c.filters {
+		if f.Match(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply changes filters by command, returns error if command is wrong.
+func (c *eventsConn) apply(cmd EventCommand) error {
+	if c.subID != "" {
+		return fmt.Errorf("filters of the durable subscription %s can't be changed", c.subID)
+	}
+	if cmd.FilterID == "" {
+		return fmt.Errorf("filter_id of the %s command is empty", cmd.Type)
+	}
+	if cmd.Filter == nil && cmd.Type != wsCommandUnsubscribe {
+		return fmt.Errorf("filter of the %s command is empty", cmd.Type)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, exists := c.filters[cmd.FilterID]
+	switch cmd.Type {
+	case wsCommandSubscribe:
+		if exists {
+			return fmt.Errorf("filter %s already exists, use %s", cmd.FilterID, wsCommandUpdateFilter)
+		}
+		if len(c.filters) >= maxEventFilters {
+			return fmt.Errorf("too many filters, max %d", maxEventFilters)
+		}
+		c.filters[cmd.FilterID] = *cmd.Filter
+	case wsCommandUpdateFilter:
+		if !exists {
+			return fmt.Errorf("filter %s not found", cmd.FilterID)
+		}
+		c.filters[cmd.FilterID] = *cmd.Filter
+	case wsCommandUnsubscribe:
+		if !exists {
+			return fmt.Errorf("filter %s not found", cmd.FilterID)
+		}
+		delete(c.filters, cmd.FilterID)
+	}
+	return nil
+}
+
+// reply sends frame to the writer, drops it if connection is closed.
+func (c *eventsConn) reply(ctx context.Context, f EventFrame) {
+	select {
+	case <-ctx.Done():
+	case c.frames <- f:
+	}
+}
+
+// readEventCommands reads commands of the websocket client till it disconnects, then cancels consumer.
+func (s *Server) readEventCommands(ctx context.Context, conn *eventsConn, cancel context.CancelFunc) {
+	defer cancel()
+	for {
+		t, msg, err := conn.ws.ReadMessage()
+		if err != nil {
+			s.log.Debugf("read from ws stopped, %v", err)
+			return
+		}
+		if t != websocket.TextMessage {
+			continue
+		}
+		cmd := EventCommand{}
+		if err := json.Unmarshal(msg, &cmd); err != nil {
+			s.log.Warnf("got wrong format message %s, need command", string(msg))
+			conn.reply(ctx, EventFrame{Type: wsFrameError, Error: fmt.Sprintf("wrong format, %s", err)})
+			continue
+		}
+		switch cmd.Type {
+		case eventAckType:
+			if conn.subID == "" {
+				conn.reply(ctx, EventFrame{Type: wsFrameError, Command: cmd.Type, Error: "ack without subscription_id"})
+				continue
+			}
+			err := subscription.Ack(ctx, s.subs, conn.subID, subscription.Cursor{Time: cmd.EventTime, ID: cmd.ID})
+			if err != nil {
+				s.log.Errorf("ack event %s of subscription %s error, %s", cmd.ID, conn.subID, err)
+				conn.reply(ctx, EventFrame{Type: wsFrameError, Command: cmd.Type, Error: err.Error()})
+			}
+		case wsCommandSubscribe, wsCommandUnsubscribe, wsCommandUpdateFilter:
+			if err := conn.apply(cmd); err != nil {
+				conn.reply(ctx, EventFrame{Type: wsFrameError, Command: cmd.Type, FilterID: cmd.FilterID, Error: err.Error()})
+				continue
+			}
+			s.log.Debugf("applied %s of the filter %s", cmd.Type, cmd.FilterID)
+			if conn.resubscribe != nil {
+				s.resubscribe(ctx, conn)
+			}
+			conn.reply(ctx, EventFrame{Type: wsFrameAck, Command: cmd.Type, FilterID: cmd.FilterID})
+		default:
+			conn.reply(ctx, EventFrame{Type: wsFrameError, Command: cmd.Type,
+				Error: fmt.Sprintf("unknown command %s, allowed %s, %s, %s, %s", cmd.Type,
+					wsCommandSubscribe, wsCommandUnsubscribe, wsCommandUpdateFilter, eventAckType)})
+		}
+	}
+}
+
+// resubscribe moves the connection to the hub consumer of the topic of the changed filters,
+// writer closes the previous consumer.
+func (s *Server) resubscribe(ctx context.Context, conn *eventsConn) {
+	conn.mu.RLock()
+	t := topicOf(conn.filters)
+	conn.mu.RUnlock()
+	if t == conn.topic {
+		return
+	}
+	sub := s.hub.Subscribe(t)
+	select {
+	case <-ctx.Done():
+		sub.Close()
+	case conn.resubscribe <- sub:
+		s.log.Debugf("events consumer moved from the topic %s to %s", conn.topic, t)
+		conn.topic = t
+	}
+}
@@ -0,0 +1,314 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventhub"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// liveEventRepo sends events pushed by test till consumer is cancelled.
+type liveEventRepo struct {
+	ch        chan domain.Event
+	cancelled chan struct{}
+}
+
+func (r *liveEventRepo) FindChainEvents(from, to time.Time, layoutID, storeID, key, kind, severity string,
+	limit, offset int64) (domain.Events, int64, error) {
+	return domain.Events{}, 0, nil
+}
+
+func (r *liveEventRepo) FindConsumerChainEvents(subscriberID, layoutID, storeID, key, kind, severity string,
+	from time.Time, cancel <-chan struct{}) chan domain.Event {
+	go func() {
+		<-cancel
+		close(r.cancelled)
+	}()
+	return r.ch
+}
+
+func TestEventFilter_Match(t *testing.T) {
+	e := domain.Event{LayoutID: "10", StoreID: "2", Key: "queue.threshold.exceeded", Kind: "business", Severity: "alarm"}
+	tests := []struct {
+		name string
+		f    EventFilter
+		want bool
+	}{
+		{"any", EventFilter{}, true},
+		{"one_of_stores", EventFilter{LayoutID: []string{"10"}, StoreID: []string{"1", "2"}}, true},
+		{"other_store", EventFilter{StoreID: []string{"1", "3"}}, false},
+		{"all_fields", EventFilter{Key: []string{e.Key}, Kind: []string{"business"}, Severity: []string{"warn", "alarm"}}, true},
+		{"other_severity", EventFilter{LayoutID: []string{"10"}, Severity: []string{"info"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.f.Match(e); got != tt.want {
+				t.Errorf("EventFilter.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_serveChainEventsWS(t *testing.T) {
+	repo := &liveEventRepo{ch: make(chan domain.Event), cancelled: make(chan struct{})}
+	s := &Server{log: zap.NewNop().Sugar(), mWS: api_websocket_connections, evRepo: repo,
+		upgrader: &websocket.Upgrader{}}
+	e := echo.New()
+	e.GET("/v2/chains/events/ws", s.serveChainEventsWS)
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/v2/chains/events/ws", nil)
+	if err != nil {
+		t.Fatalf("dial error, %s", err)
+	}
+	defer ws.Close()
+
+	send := func(msg string) {
+		if err := ws.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatalf("write error, %s", err)
+		}
+	}
+	// frame reads the next message, events have no type
+	frame := func() (EventFrame, domain.Event) {
+		f, ev := EventFrame{}, domain.Event{}
+		_, msg, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatalf("read error, %s", err)
+		}
+		if strings.Contains(string(msg), `"type"`) {
+			if err := json.Unmarshal(msg, &f); err != nil {
+				t.Fatalf("unmarshal frame error, %s", err)
+			}
+			return f, ev
+		}
+		if err := ev.UnmarshalJSON(msg); err != nil {
+			t.Fatalf("unmarshal event error, %s", err)
+		}
+		return f, ev
+	}
+	push := func(id, store, severity string) {
+		repo.ch <- domain.Event{ID: id, EventTime: time.Now(), LayoutID: "10", StoreID: store, Severity: severity}
+	}
+
+	send(`{"layout_id":"10"}`)
+	push("1", "1", "info")
+	if _, ev := frame(); ev.ID != "1" {
+		t.Fatalf("got event %q, want 1 by parameters", ev.ID)
+	}
+	send(`{"type":"update-filter","filter_id":"default","filter":{"store_id":["2","3"]}}`)
+	if f, _ := frame(); f.Type != wsFrameAck || f.Command != wsCommandUpdateFilter || f.FilterID != defaultFilterID {
+		t.Fatalf("got frame %+v, want ack of update-filter", f)
+	}
+	push("2", "1", "info")
+	push("3", "2", "info")
+	if _, ev := frame(); ev.ID != "3" {
+		t.Fatalf("got event %q, want 3 of the store 2", ev.ID)
+	}
+	send(`{"type":"subscribe","filter_id":"alarms","filter":{"severity":["alarm"]}}`)
+	if f, _ := frame(); f.Type != wsFrameAck || f.FilterID != "alarms" {
+		t.Fatalf("got frame %+v, want ack of subscribe", f)
+	}
+	push("4", "5", "alarm")
+	if _, ev := frame(); ev.ID != "4" {
+		t.Fatalf("got event %q, want 4 by alarms filter", ev.ID)
+	}
+	for _, cmd := range []string{
+		`{"type":"unsubscribe","filter_id":"unknown"}`,
+		`{"type":"subscribe","filter_id":"alarms","filter":{}}`,
+		`{"type":"ack","id":"4","event_time":"2021-06-01T12:00:00Z"}`,
+		`{"type":"bogus"}`,
+		`not json`,
+	} {
+		send(cmd)
+		if f, _ := frame(); f.Type != wsFrameError || f.Error == "" {
+			t.Errorf("got frame %+v to %s, want error", f, cmd)
+		}
+	}
+	send(`{"type":"unsubscribe","filter_id":"alarms"}`)
+	if f, _ := frame(); f.Type != wsFrameAck || f.Command != wsCommandUnsubscribe {
+		t.Fatalf("got frame %+v, want ack of unsubscribe", f)
+	}
+
+	// consumer is cancelled when client disconnects
+	ws.Close()
+	select {
+	case <-repo.cancelled:
+	case <-time.After(time.Second):
+		t.Error("consumer isn't cancelled after client disconnect")
+	}
+}
+
+func TestTopicOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters map[string]EventFilter
+		want    eventhub.Topic
+	}{
+		{"none", nil, eventhub.Topic{}},
+		{"single", map[string]EventFilter{"a": {LayoutID: []string{"10"}, StoreID: []string{"2"}}},
+			eventhub.Topic{LayoutID: "10", StoreID: "2"}},
+		{"any", map[string]EventFilter{"a": {LayoutID: []string{"*"}, Kind: []string{"business"}}},
+			eventhub.Topic{Kind: "business"}},
+		{"one_of", map[string]EventFilter{"a": {LayoutID: []string{"10"}, StoreID: []string{"1", "2"}}},
+			eventhub.Topic{LayoutID: "10"}},
+		{"common", map[string]EventFilter{
+			"a": {LayoutID: []string{"10"}, StoreID: []string{"1"}},
+			"b": {LayoutID: []string{"10"}, StoreID: []string{"2"}},
+			"c": {LayoutID: []string{"10"}, Severity: []string{"alarm"}},
+		}, eventhub.Topic{LayoutID: "10"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := topicOf(tt.filters); got != tt.want {
+				t.Errorf("topicOf() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// topicEventRepo starts live consumer per layout, consumers of the stopped layout are replaced.
+type topicEventRepo struct {
+	liveEventRepo
+	mu        sync.Mutex
+	consumers map[string]*liveEventRepo
+	started   int
+}
+
+func (r *topicEventRepo) FindConsumerChainEvents(subscriberID, layoutID, storeID, key, kind, severity string,
+	from time.Time, cancel <-chan struct{}) chan domain.Event {
+	live := &liveEventRepo{ch: make(chan domain.Event), cancelled: make(chan struct{})}
+	r.mu.Lock()
+	r.consumers[layoutID] = live
+	r.started++
+	r.mu.Unlock()
+	return live.FindConsumerChainEvents(subscriberID, layoutID, storeID, key, kind, severity, from, cancel)
+}
+
+func (r *topicEventRepo) consumer(layoutID string) (*liveEventRepo, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.consumers[layoutID], r.started
+}
+
+func TestServer_serveChainEventsWS_hub(t *testing.T) {
+	repo := &topicEventRepo{consumers: make(map[string]*liveEventRepo)}
+	hub, err := eventhub.New(context.Background(), repo, eventhub.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	s := &Server{log: zap.NewNop().Sugar(), mWS: api_websocket_connections, evRepo: repo, hub: hub,
+		upgrader: &websocket.Upgrader{}}
+	e := echo.New()
+	e.GET("/v2/chains/events/ws", s.serveChainEventsWS)
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	// command sends message and waits ack, ack means client is subscribed to the topic of its filters
+	command := func(ws *websocket.Conn, msg string) {
+		if err := ws.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatalf("write error, %s", err)
+		}
+		if f := (EventFrame{}); ws.ReadJSON(&f) != nil || f.Type != wsFrameAck {
+			t.Fatalf("got frame %+v, want ack of %s", f, msg)
+		}
+	}
+	dial := func(params string) *websocket.Conn {
+		ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/v2/chains/events/ws", nil)
+		if err != nil {
+			t.Fatalf("dial error, %s", err)
+		}
+		if err := ws.WriteMessage(websocket.TextMessage, []byte(params)); err != nil {
+			t.Fatalf("write error, %s", err)
+		}
+		command(ws, `{"type":"subscribe","filter_id":"same","filter":{"layout_id":["10"]}}`)
+		return ws
+	}
+	read := func(ws *websocket.Conn, want string) {
+		ev := domain.Event{}
+		if err := ws.ReadJSON(&ev); err != nil || ev.ID != want {
+			t.Errorf("got event %q, %v, want %s", ev.ID, err, want)
+		}
+	}
+	a, b := dial(`{"layout_id":"10"}`), dial(`{"layout_id":"10"}`)
+	defer a.Close()
+	defer b.Close()
+	l10, started := repo.consumer("10")
+	if started != 1 {
+		t.Fatalf("started %d consumers, want 1 shared by connections of the layout", started)
+	}
+	l10.ch <- domain.Event{ID: "1", EventTime: time.Now(), LayoutID: "10", StoreID: "1"}
+	read(a, "1")
+	read(b, "1")
+
+	// changed filters move client to the consumer of the new topic
+	command(b, `{"type":"update-filter","filter_id":"default","filter":{"layout_id":["20"]}}`)
+	wide, _ := repo.consumer("")
+	command(b, `{"type":"unsubscribe","filter_id":"same"}`)
+	l20, started := repo.consumer("20")
+	if started != 3 {
+		t.Fatalf("started %d consumers, want 3 by topics of the filters", started)
+	}
+	select {
+	case <-wide.cancelled:
+	case <-time.After(time.Second):
+		t.Error("consumer of the previous topic isn't cancelled")
+	}
+	l20.ch <- domain.Event{ID: "2", EventTime: time.Now(), LayoutID: "20", StoreID: "2"}
+	read(b, "2")
+	l10.ch <- domain.Event{ID: "3", EventTime: time.Now(), LayoutID: "10", StoreID: "3"}
+	read(a, "3")
+
+	// consumer is cancelled when the last client of the topic disconnects
+	for _, c := range []struct {
+		ws   *websocket.Conn
+		live *liveEventRepo
+	}{{a, l10}, {b, l20}} {
+		c.ws.Close()
+		select {
+		case <-c.live.cancelled:
+		case <-time.After(time.Second):
+			t.Error("consumer isn't cancelled after clients disconnect")
+		}
+	}
+}
+
+func TestServer_consumeAllEvents_hub(t *testing.T) {
+	repo := &topicEventRepo{consumers: make(map[string]*liveEventRepo)}
+	hub, err := eventhub.New(context.Background(), repo, eventhub.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	s := &Server{log: zap.NewNop().Sugar(), evRepo: repo, hub: hub}
+	ctx, cancel := context.WithCancel(context.Background())
+	notify, hooks := s.consumeAllEvents(ctx, notifyConsumer), s.consumeAllEvents(ctx, webhooksConsumer)
+	live, started := repo.consumer("")
+	if started != 1 {
+		t.Fatalf("started %d consumers, want single consumer of the hub", started)
+	}
+	live.ch <- domain.Event{ID: "1"}
+	for _, in := range []<-chan domain.Event{notify, hooks} {
+		select {
+		case e := <-in:
+			if e.ID != "1" {
+				t.Errorf("got event %s, want 1", e.ID)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("event isn't received")
+		}
+	}
+	cancel()
+	select {
+	case <-live.cancelled:
+	case <-time.After(time.Second):
+		t.Error("consumer of the hub isn't stopped with the subscribers")
+	}
+}
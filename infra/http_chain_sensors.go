@@ -88,6 +88,7 @@ func (s *Server) apiCreateChainSensor(c echo.Context) error {
 		s.log.Errorf("repo.AddChainSensor error %v", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
+	c.Set(ctxCreatedID, id)
 	href := `{"href":"/v2/chains/sensors/` + id + `"}`
 	return c.JSON(http.StatusCreated, CreatedStatus(href))
 }
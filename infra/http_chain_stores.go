@@ -85,7 +85,7 @@ func (s *Server) apiCreateChainStore(c echo.Context) error {
 		s.log.Errorf("apiCreateChainStore, bad request error %v", err)
 		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
 	}
-	repo, _, err := s.getRepo(c)
+	repo, layoutID, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
@@ -95,6 +95,11 @@ func (s *Server) apiCreateChainStore(c echo.Context) error {
 		s.log.Errorf("repo.AddChainStore error %v", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
+	if store.LayoutID != "" {
+		layoutID = store.LayoutID
+	}
+	s.invalidateLayoutACL(c, layoutID)
+	c.Set(ctxCreatedID, id)
 	href := `{"href":"/v2/chains/stores/` + id + `"}`
 	return c.JSON(http.StatusCreated, CreatedStatus(href))
 }
@@ -338,7 +343,7 @@ func (s *Server) apiDeleteChainStore(c echo.Context) error {
 		s.log.Errorf("bad request apiDeleteStore, %v", errEmptyID)
 		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(errEmptyID))
 	}
-	repo, _, err := s.getRepo(c)
+	repo, layoutID, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
@@ -348,6 +353,7 @@ func (s *Server) apiDeleteChainStore(c echo.Context) error {
 		s.log.Errorf("repo.DelChainStore error %v", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
+	s.invalidateLayoutACL(c, layoutID)
 	return c.JSON(http.StatusOK, OkStatus(fmt.Sprintf("deleted %d row(s)", cnt)))
 }
 
@@ -420,7 +426,7 @@ func (s *Server) apiUpdChainStore(c echo.Context) error {
 	if store.StoreID == "" {
 		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(fmt.Errorf("empty store_id not allowed")))
 	}
-	repo, _, err := s.getRepo(c)
+	repo, layoutID, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
@@ -431,5 +437,9 @@ func (s *Server) apiUpdChainStore(c echo.Context) error {
 		s.log.Errorf("repo.DelChainStore error %v", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
+	if store.LayoutID != "" {
+		layoutID = store.LayoutID
+	}
+	s.invalidateLayoutACL(c, layoutID)
 	return c.JSON(http.StatusOK, OkStatus(fmt.Sprintf("update %d row(s)", count)))
 }
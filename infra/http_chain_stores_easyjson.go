@@ -5,6 +5,7 @@ package infra
 import (
 	json "encoding/json"
 	domain "git.countmax.ru/countmax/layoutconfig.api/domain"
+	connmanager "git.countmax.ru/countmax/layoutconfig.api/internal/connmanager"
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
@@ -503,6 +504,29 @@ func easyjsonE2ef0e1DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra2(in *jlexer.
 			}
 		case "result_set":
 			easyjsonE2ef0e1DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra3(in, &out.ResultSet)
+		case "errors":
+			if in.IsNull() {
+				in.Skip()
+				out.Errors = nil
+			} else {
+				in.Delim('[')
+				if out.Errors == nil {
+					if !in.IsDelim(']') {
+						out.Errors = make(RepoErrors, 0, 2)
+					} else {
+						out.Errors = RepoErrors{}
+					}
+				} else {
+					out.Errors = (out.Errors)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v2 connmanager.RepoError
+					easyjsonE2ef0e1DecodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(in, &v2)
+					out.Errors = append(out.Errors, v2)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -524,11 +548,11 @@ func easyjsonE2ef0e1EncodeGitCountmaxRuCountmaxLayoutconfigApiInfra2(out *jwrite
 			out.RawString("null")
 		} else {
 			out.RawByte('[')
-			for v2, v3 := range in.Data {
-				if v2 > 0 {
+			for v3, v4 := range in.Data {
+				if v3 > 0 {
 					out.RawByte(',')
 				}
-				(v3).MarshalEasyJSON(out)
+				(v4).MarshalEasyJSON(out)
 			}
 			out.RawByte(']')
 		}
@@ -538,6 +562,20 @@ func easyjsonE2ef0e1EncodeGitCountmaxRuCountmaxLayoutconfigApiInfra2(out *jwrite
 		out.RawString(prefix)
 		easyjsonE2ef0e1EncodeGitCountmaxRuCountmaxLayoutconfigApiInfra3(out, in.ResultSet)
 	}
+	if len(in.Errors) != 0 {
+		const prefix string = ",\"errors\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v5, v6 := range in.Errors {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				easyjsonE2ef0e1EncodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(out, v6)
+			}
+			out.RawByte(']')
+		}
+	}
 	out.RawByte('}')
 }
 
@@ -564,6 +602,55 @@ func (v *ChainStoresResponse) UnmarshalJSON(data []byte) error {
 func (v *ChainStoresResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonE2ef0e1DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra2(l, v)
 }
+func easyjsonE2ef0e1DecodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(in *jlexer.Lexer, out *connmanager.RepoError) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "dest":
+			out.Dest = string(in.String())
+		case "error":
+			out.Error = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE2ef0e1EncodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(out *jwriter.Writer, in connmanager.RepoError) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"dest\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Dest))
+	}
+	{
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
+	out.RawByte('}')
+}
 func easyjsonE2ef0e1DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra3(in *jlexer.Lexer, out *ResultSet) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
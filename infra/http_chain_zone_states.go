@@ -31,6 +31,7 @@ type ChainZonesStatesResponse struct {
 // @Success 200 {object} infra.ChainZonesStatesResponse
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 500 {object} infra.ErrResponse
@@ -49,11 +50,25 @@ func (s *Server) apiChainZonesStates(c echo.Context) error {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
+	zones, err := s.readableZones(c, repo, layoutID)
+	if err != nil {
+		s.log.Errorf("readableZones error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !isAnyParam(zoneID) && !zones.allow(zoneID) {
+		s.log.Warnf("not permitted read for layout %s and zone %s", layoutID, zoneID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
+	if !storeParamAllowed(zones.stores, storeID) {
+		s.log.Warnf("not permitted read for layout %s and store %s", layoutID, storeID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	zoneStates, count, err := repo.FindChainZonesStates(c.Request().Context(), layoutID, storeID, zoneID, from, to, offset, limit)
 	if err != nil {
 		s.log.Errorf("repo.FindChainZonesStates error %v", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
+	zoneStates = zoneStates.FilterByStore(zones.stores.Allow)
 	response := ChainZonesStatesResponse{
 		Data: zoneStates,
 		Metadata: Metadata{
@@ -80,6 +95,7 @@ func (s *Server) apiChainZonesStates(c echo.Context) error {
 // @Success 200 {object} domain.ZoneStates
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 500 {object} infra.ErrResponse
@@ -92,13 +108,25 @@ func (s *Server) apiChainZonesLastStates(c echo.Context) error {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: add perm LayoutCheck read only. X5 specified not 4 all layouts
+	zones, err := s.readableZones(c, repo, layoutID)
+	if err != nil {
+		s.log.Errorf("readableZones error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !isAnyParam(zoneID) && !zones.allow(zoneID) {
+		s.log.Warnf("not permitted read for layout %s and zone %s", layoutID, zoneID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
+	if !storeParamAllowed(zones.stores, storeID) {
+		s.log.Warnf("not permitted read for layout %s and store %s", layoutID, storeID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	zoneStates, err := repo.FindChainZonesStatesLast(c.Request().Context(), layoutID, storeID, zoneID)
 	if err != nil {
 		s.log.Errorf("repo.FindChainZonesStatesLast error %v", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
-	return c.JSON(http.StatusOK, zoneStates)
+	return c.JSON(http.StatusOK, zoneStates.FilterByStore(zones.stores.Allow))
 }
 
 // apiChainZonesLastStates docs
@@ -113,6 +141,7 @@ func (s *Server) apiChainZonesLastStates(c echo.Context) error {
 // @Success 200 {object} domain.ZoneState
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 500 {object} infra.ErrResponse
@@ -139,11 +168,23 @@ func (s *Server) apiChainZoneStateAtTime(c echo.Context) error {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: add perm LayoutCheck read only. X5 specified not 4 all layouts
+	zones, err := s.readableZones(c, repo, layoutID)
+	if err != nil {
+		s.log.Errorf("readableZones error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !isAnyParam(zoneID) && !zones.allow(zoneID) {
+		s.log.Warnf("not permitted read for layout %s and zone %s", layoutID, zoneID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	zoneState, err := repo.FindChainZoneStateAtTime(c.Request().Context(), layoutID, zoneID, at)
 	if err != nil {
 		s.log.Errorf("repo.FindChainZoneStateAtTime error %v", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
+	if zoneState != nil && !zones.stores.Allow(zoneState.StoreID) {
+		s.log.Warnf("not permitted read for layout %s and store %s", layoutID, zoneState.StoreID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	return c.JSON(http.StatusOK, zoneState)
 }
@@ -83,6 +83,7 @@ func (s *Server) apiCreateChainZone(c echo.Context) error {
 		s.log.Errorf("repo.AddChainZone error %v", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
+	c.Set(ctxCreatedID, id)
 	href := `{"href":"/v2/chains/zones/` + id + `"}`
 	return c.JSON(http.StatusCreated, CreatedStatus(href))
 }
@@ -1,9 +1,11 @@
 package infra
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -108,6 +110,7 @@ func (s *Server) apiCreateChain(c echo.Context) error {
 		s.log.Errorf("AddChain(chain.title=%s) error %v", newChain.Title, err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
+	c.Set(ctxCreatedID, id)
 	href := `{"href":"/v2/chains/` + id + `"}`
 	return c.JSON(http.StatusCreated, CreatedStatus(href))
 }
@@ -116,7 +119,7 @@ func (s *Server) apiCreateChain(c echo.Context) error {
 // apiChains docs
 // @Summary Get all chains in the retail schema
 // @Description get slice of chains with loc (location), date, offset, limit, fields, include parameters
-// @Description layout_id (recommended parameter), if not pass datasource may be not correct
+// @Description layout_id not passed or * - chains are requested from the all databases in parallel, failed databases are listed in errors of the metadata
 // @Description fields - comma separated values of field names, can be layout_id,kind,title,languages... all of them described at the model
 // @Description include - comma separated list of entities, embedded in current, for chain it can be stores
 // @Produce  json
@@ -151,6 +154,9 @@ func (s *Server) apiChains(c echo.Context) error {
 	if c.QueryParam("fields") == "" {
 		fields = nil
 	}
+	if isFanOut(c) {
+		return s.apiChainsFanOut(c, loc, dt, fields, offset, limit)
+	}
 	repo, layout, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
@@ -196,6 +202,67 @@ func (s *Server) apiChains(c echo.Context) error {
 	return s.responserMIME(c, http.StatusOK, response)
 }
 
+// apiChainsFanOut requests chains from the all databases in parallel,
+// merges readable chains ordered by layout_id and returns requested page
+func (s *Server) apiChainsFanOut(c echo.Context, loc, dt string, fields []string, offset, limit int64) error {
+	perm := s.perm.FromRequest(c.Request())
+	include := c.QueryParam("include")
+	results, errs := s.fanOut(c, func(ctx context.Context, repo domain.LayoutRepo) (interface{}, error) {
+		res := make(domain.Chains, 0)
+		err := readPages(func(offset, limit int64) (int, int64, error) {
+			chains, count, err := repo.FindChains(ctx, loc, dt, "*", offset, limit)
+			for _, chain := range chains {
+				if s.repoM.Routed(chain.LayoutID, repo) && perm.CheckLayout(chain.LayoutID, "read") {
+					res = append(res, chain)
+				}
+			}
+			return len(chains), count, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if include == "stores" && len(res) > 0 {
+			var shortStoreFieldSet []string = []string{"store_id", "layout_id", "title", "crm_key"}
+			stores := make(domain.ChainStores, 0)
+			err = readPages(func(offset, limit int64) (int, int64, error) {
+				page, count, err := repo.FindChainStores(ctx, loc, dt, "*", "*", offset, limit, "*")
+				stores = append(stores, page...)
+				return len(page), count, err
+			})
+			if err != nil {
+				return nil, err
+			}
+			stores.SetZeroValue(shortStoreFieldSet)
+			res.IncludeStores(stores)
+		}
+		return res, nil
+	})
+	if len(results) == 0 && len(errs) > 0 {
+		return s.responserMIME(c, http.StatusInternalServerError, ErrServerInternal(errRepo))
+	}
+	all := make(domain.Chains, 0)
+	for _, res := range results {
+		all = append(all, res.(domain.Chains)...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].LayoutID < all[j].LayoutID })
+	from, to := pageBounds(len(all), offset, limit)
+	chains := all[from:to]
+	chains.SetZeroValue(fields)
+	response := ChainsResponse{
+		Data: chains,
+		Metadata: Metadata{
+			ResultSet: ResultSet{
+				Count:  int64(len(chains)),
+				Offset: offset,
+				Limit:  limit,
+				Total:  int64(len(all)),
+			},
+			Errors: errs,
+		},
+	}
+	return s.responserMIME(c, http.StatusOK, response)
+}
+
 // apiChainByID docs
 // @Summary Get specified chain in the retail schema
 // @Description get chain with loc(ation), date, layout_id, fields, include parameters
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/connmanager"
 	"git.countmax.ru/countmax/layoutconfig.api/internal/permission"
 	"github.com/labstack/echo/v4"
 )
@@ -215,6 +216,30 @@ func (s *Server) apiHealthCheck(c echo.Context) error {
 	return c.JSON(http.StatusOK, OkStatus("OK"))
 }
 
+// HealthDetails state of the service and its databases
+type HealthDetails struct {
+	Status    string                 `json:"status"`
+	Error     string                 `json:"error,omitempty"`
+	Databases []connmanager.DBStatus `json:"databases"`
+}
+
+// apiHealthDetails returns 200 ok if repository is connected with states of the all countmax databases
+// @Summary Healthcheck service with states of the countmax databases
+// @Description state of the database can be connecting, healthy, degraded (registered, but health check failed)
+// @Description or lost (connection failed, retried with exponential backoff)
+// @Tags health
+// @Success 200 {object} infra.HealthDetails
+// @Failure 500 {object} infra.HealthDetails
+// @Router /health/details [get]
+func (s *Server) apiHealthDetails(c echo.Context) error {
+	details := HealthDetails{Status: http.StatusText(http.StatusOK), Databases: s.repoM.Statuses()}
+	if err := s.healthCheck(c.Request().Context()); err != nil {
+		details.Status, details.Error = http.StatusText(http.StatusInternalServerError), err.Error()
+		return c.JSON(http.StatusInternalServerError, details)
+	}
+	return c.JSON(http.StatusOK, details)
+}
+
 func (s *Server) metrics(c echo.Context) error {
 	return c.Redirect(http.StatusPermanentRedirect, "/metrics")
 }
@@ -237,7 +262,8 @@ func (s *Server) apiVersion(c echo.Context) error {
 
 // Metadata - metadata, page, limit, offset... etc...
 type Metadata struct {
-	ResultSet ResultSet `json:"result_set"`
+	ResultSet ResultSet  `json:"result_set"`
+	Errors    RepoErrors `json:"errors,omitempty"` // failed databases of the fan-out query, result is partial
 }
 
 // ResultSet - values total, limit....
@@ -345,6 +371,16 @@ func ErrForbidden(err error) ErrResponse {
 	}
 }
 
+// ErrConflict - wrapper for make err structure for already existing resource
+func ErrConflict(err error) ErrResponse {
+	return ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusConflict,
+		StatusText:     http.StatusText(http.StatusConflict),
+		ErrorText:      fmt.Sprintf("%v", err),
+	}
+}
+
 // ErrPayloadTooLarge - wrapper for make err structure
 func ErrPayloadTooLarge(err error) ErrResponse {
 	return ErrResponse{
@@ -355,6 +391,16 @@ func ErrPayloadTooLarge(err error) ErrResponse {
 	}
 }
 
+// ErrServiceUnavailable - wrapper for make err structure for temporarily unavailable database
+func ErrServiceUnavailable(err error) ErrResponse {
+	return ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusServiceUnavailable,
+		StatusText:     http.StatusText(http.StatusServiceUnavailable),
+		ErrorText:      fmt.Sprintf("%v", err),
+	}
+}
+
 // ErrUnsupportedFormat - 415 error implementation
 var ErrUnsupportedFormat = &ErrResponse{HTTPStatusCode: http.StatusUnsupportedMediaType, StatusText: "415 - Unsupported Media Type."}
 
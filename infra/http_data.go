@@ -21,6 +21,7 @@ import (
 // @Success 200 {object} domain.DatasInside
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 500 {object} infra.ErrResponse
@@ -41,18 +42,26 @@ func (s *Server) apiZoneDataInsideDay(c echo.Context) error {
 		}
 		day = pday
 	}
-	repo, _, err := s.getRepo(c)
+	repo, layoutID, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: add ZoneFiltered read
+	zones, err := s.readableZones(c, repo, layoutID)
+	if err != nil {
+		s.log.Errorf("readableZones error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !isAnyParam(pZoneID) && !zones.allow(pZoneID) {
+		s.log.Warnf("not permitted read for layout %s and zone %s", layoutID, pZoneID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	data, err := repo.FindZoneDataInsideDay(c.Request().Context(), zoneID, &day)
 	if err != nil {
 		s.log.Errorf("repo.FindZoneDataInsideDay error %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
-	return c.JSON(http.StatusOK, data)
+	return c.JSON(http.StatusOK, data.FilterByZone(zones.allow))
 }
 
 // nolint:lll
@@ -72,6 +81,7 @@ func (s *Server) apiZoneDataInsideDay(c echo.Context) error {
 // @Success 200 {object} domain.DatasInside
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 500 {object} infra.ErrResponse
@@ -101,18 +111,26 @@ func (s *Server) apiZoneDataInsideRange(c echo.Context) error {
 		}
 		day = pday
 	}
-	repo, _, err := s.getRepo(c)
+	repo, layoutID, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: add ZoneFiltered read
+	zones, err := s.readableZones(c, repo, layoutID)
+	if err != nil {
+		s.log.Errorf("readableZones error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !isAnyParam(pZoneID) && !zones.allow(pZoneID) {
+		s.log.Warnf("not permitted read for layout %s and zone %s", layoutID, pZoneID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	data, err := repo.FindZoneDataInsideRange(c.Request().Context(), from, to, zoneID, &day)
 	if err != nil {
 		s.log.Errorf("repo.FindZoneDataInsideRange error %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
-	return c.JSON(http.StatusOK, data)
+	return c.JSON(http.StatusOK, data.FilterByZone(zones.allow))
 }
 
 // apiZoneDataInside docs
@@ -126,6 +144,7 @@ func (s *Server) apiZoneDataInsideRange(c echo.Context) error {
 // @Success 200 {object} domain.DatasInside
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 500 {object} infra.ErrResponse
@@ -136,16 +155,24 @@ func (s *Server) apiZoneDataInside(c echo.Context) error {
 	if pZoneID != "" {
 		zoneID = &pZoneID
 	}
-	repo, _, err := s.getRepo(c)
+	repo, layoutID, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: add ZoneFiltered read
+	zones, err := s.readableZones(c, repo, layoutID)
+	if err != nil {
+		s.log.Errorf("readableZones error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !isAnyParam(pZoneID) && !zones.allow(pZoneID) {
+		s.log.Warnf("not permitted read for layout %s and zone %s", layoutID, pZoneID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	data, err := repo.FindZoneDataInsideNow(c.Request().Context(), zoneID) // TODO: add zoneIDs list param
 	if err != nil {
 		s.log.Errorf("repo.FindZoneDataInsideNow error %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
-	return c.JSON(http.StatusOK, data)
+	return c.JSON(http.StatusOK, data.FilterByZone(zones.allow))
 }
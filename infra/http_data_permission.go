@@ -0,0 +1,128 @@
+package infra
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/acl"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	errPermission error = errors.New("permission process failed")
+)
+
+// zonesFilter zones of the stores allowed to the user.
+type zonesFilter struct {
+	stores permission.StoresFilter
+	ids    map[string]struct{}
+}
+
+// allow reports whether zone allowed.
+func (f zonesFilter) allow(zoneID string) bool {
+	if f.stores.All {
+		return true
+	}
+	_, ok := f.ids[zoneID]
+	return ok
+}
+
+// filterList keeps allowed zones of the requested list,
+// asterisk or empty list means all zones of the allowed stores.
+func (f zonesFilter) filterList(zoneIDs []string) []string {
+	if f.stores.All {
+		return zoneIDs
+	}
+	if len(zoneIDs) == 0 || (len(zoneIDs) == 1 && (zoneIDs[0] == "*" || zoneIDs[0] == "")) {
+		res := make([]string, 0, len(f.ids))
+		for id := range f.ids {
+			res = append(res, id)
+		}
+		sort.Strings(res)
+		return res
+	}
+	res := make([]string, 0, len(zoneIDs))
+	for _, id := range zoneIDs {
+		if f.allow(id) {
+			res = append(res, id)
+		}
+	}
+	return res
+}
+
+// isAnyParam reports whether query parameter not restricts entities.
+func isAnyParam(id string) bool {
+	return id == "" || id == "*"
+}
+
+// readableStores returns filter of the stores allowed to read in the layout by the request user.
+func (s *Server) readableStores(c echo.Context, layoutID string) (permission.StoresFilter, error) {
+	return s.perm.AllowedStores(c.Request(), layoutID, acl.ActionRead)
+}
+
+// storeParamAllowed reports whether requested store_id allowed,
+// not specified store allowed, results must be filtered by the stores filter.
+func storeParamAllowed(stores permission.StoresFilter, storeID string) bool {
+	return isAnyParam(storeID) || stores.Allow(storeID)
+}
+
+// readableZones returns filter of the zones of the stores allowed to read in the layout,
+// zones are requested from the repo only for users with restrictions by stores.
+func (s *Server) readableZones(c echo.Context, repo domain.LayoutRepo, layoutID string) (zonesFilter, error) {
+	stores, err := s.readableStores(c, layoutID)
+	if err != nil {
+		return zonesFilter{}, err
+	}
+	f := zonesFilter{stores: stores, ids: make(map[string]struct{})}
+	if stores.All || stores.Empty() {
+		return f, nil
+	}
+	if layoutID == "" {
+		layoutID = "*"
+	}
+	date := time.Now().Format("2006-01-02")
+	err = readPages(func(offset, limit int64) (int, int64, error) {
+		zones, total, err := repo.FindChainZones(c.Request().Context(),
+			"ru", date, layoutID, "*", "*", "*", "*", "*", offset, limit)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, zone := range zones {
+			if stores.Allow(zone.StoreID) {
+				f.ids[zone.ZoneID] = struct{}{}
+			}
+		}
+		return len(zones), total, nil
+	})
+	if err != nil {
+		return zonesFilter{}, err
+	}
+	return f, nil
+}
+
+// readableMallZones returns filter of the zones of the renters allowed to read in the mall layout,
+// renters of the mall are the stores of the layout permissions.
+func (s *Server) readableMallZones(c echo.Context, repo domain.LayoutRepo, layoutID string) (zonesFilter, error) {
+	stores, err := s.readableStores(c, layoutID)
+	if err != nil {
+		return zonesFilter{}, err
+	}
+	f := zonesFilter{stores: stores, ids: make(map[string]struct{})}
+	if stores.All || stores.Empty() {
+		return f, nil
+	}
+	date := time.Now().Format("2006-01-02")
+	for _, renterID := range stores.List() {
+		zones, err := repo.FindMallZonesByRenter(c.Request().Context(), "ru", date, renterID)
+		if err != nil {
+			return zonesFilter{}, err
+		}
+		for _, zone := range zones {
+			f.ids[zone.ZoneID] = struct{}{}
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,48 @@
+package infra
+
+import (
+	"context"
+	b64 "encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/acl"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission/cache"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission/cache/lru"
+)
+
+// newStoresPerm makes permission manager with cached user restricted by the stores of the layout,
+// stores are expanded to their entrances e1, e2...
+func newStoresPerm(t *testing.T, uid, layoutID string, stores ...string) *permission.Manager {
+	t.Helper()
+	c := lru.New(10, time.Hour)
+	u := &cache.User{ID: uid, ACLs: make(map[cache.LayoutID]acl.EntityItems, 1)}
+	lid := cache.LayoutID(layoutID)
+	enters := make([]string, 0, len(stores))
+	for i := range stores {
+		enters = append(enters, fmt.Sprintf("e%d", i+1))
+	}
+	u.AddItems(lid, true, acl.Actions{acl.ActionRead}, []string{layoutID}, acl.EntityKindLayouts)
+	u.AddItems(lid, true, acl.Actions{acl.ActionRead}, stores, acl.EntityKindStores)
+	u.AddItems(lid, true, acl.Actions{acl.ActionRead}, enters, acl.EntityKindEnters)
+	if err := c.Add(context.Background(), uid, u, time.Hour); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	return permission.NewManager(c, nil, permission.DefaultDeny, time.Hour)
+}
+
+// setStoresPerm sets headers of the user with permission to read the stores of the layout.
+func setStoresPerm(req *http.Request, uid, layoutID string, stores ...string) {
+	resources := []string{"watcom.ru:data.counting:layouts:" + layoutID}
+	for _, id := range stores {
+		resources = append(resources, "watcom.ru:data.counting:stores:"+id)
+	}
+	perm, _ := json.Marshal([]map[string]interface{}{
+		{"resources": resources, "actions": []string{"read"}, "effect": "allow"}})
+	req.Header.Set(permission.XUserID, uid)
+	req.Header.Set(permission.XUserPermission, b64.StdEncoding.EncodeToString(perm))
+}
@@ -0,0 +1,80 @@
+package infra
+
+import (
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/connmanager"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultFanOutTimeout time.Duration = 10 * time.Second
+	// readPageSize rows requested from the database at once
+	readPageSize int64 = 500
+	// readMaxRows rows read from the single database at most
+	readMaxRows int64 = 100000
+)
+
+var (
+	errTooManyRows error = errors.New("too many rows, narrow the query")
+)
+
+// RepoErrors failed databases of the fan-out query
+type RepoErrors []connmanager.RepoError
+
+// isFanOut reports whether request must be sent to the all databases,
+// it is so when layout_id not passed or is asterisk
+func isFanOut(c echo.Context) bool {
+	layoutID := c.QueryParam("layout_id")
+	if layoutID == "" {
+		layoutID = c.Param("layout_id")
+	}
+	return isAnyParam(layoutID)
+}
+
+// fanOut runs query for the all databases with timeout countmax.fanout_timeout per database
+func (s *Server) fanOut(c echo.Context, query connmanager.FanOutQuery) ([]interface{}, RepoErrors) {
+	timeout := s.config.GetDuration("countmax.fanout_timeout")
+	if timeout <= 0 {
+		timeout = defaultFanOutTimeout
+	}
+	res, errs := s.repoM.FanOut(c.Request().Context(), timeout, query)
+	for _, e := range errs {
+		s.log.Errorf("fan-out query to %s failed, %s", e.Dest, e.Error)
+	}
+	if len(errs) == 0 {
+		return res, nil
+	}
+	return res, RepoErrors(errs)
+}
+
+// pageBounds returns bounds of the page in the merged result with length n
+func pageBounds(n int, offset, limit int64) (int, int) {
+	from, to := offset, offset+limit
+	if from > int64(n) {
+		from = int64(n)
+	}
+	if to > int64(n) {
+		to = int64(n)
+	}
+	return int(from), int(to)
+}
+
+// readPages calls read page by page until the last page, read returns count of the rows of the page
+// and total count of the rows. Fails with errTooManyRows when total exceeds readMaxRows,
+// so the caller never gets truncated result.
+func readPages(read func(offset, limit int64) (int, int64, error)) error {
+	for offset := int64(0); ; offset += readPageSize {
+		n, total, err := read(offset, readPageSize)
+		if err != nil {
+			return err
+		}
+		if total > readMaxRows || offset+int64(n) > readMaxRows {
+			return errors.Wrapf(errTooManyRows, "%d rows, at most %d allowed", total, readMaxRows)
+		}
+		if int64(n) < readPageSize || offset+int64(n) >= total {
+			return nil
+		}
+	}
+}
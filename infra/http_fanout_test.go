@@ -0,0 +1,47 @@
+package infra
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReadPages(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int64
+		wantRows  int64
+		wantCalls int
+		wantErr   error
+	}{
+		{"empty", 0, 0, 1, nil},
+		{"single page", readPageSize - 1, readPageSize - 1, 1, nil},
+		{"full pages", 3 * readPageSize, 3 * readPageSize, 3, nil},
+		{"last page", 2*readPageSize + 1, 2*readPageSize + 1, 3, nil},
+		{"limit", readMaxRows, readMaxRows, int(readMaxRows / readPageSize), nil},
+		{"over limit", readMaxRows + 1, readPageSize, 1, errTooManyRows},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows int64
+			calls := 0
+			err := readPages(func(offset, limit int64) (int, int64, error) {
+				calls++
+				n := tt.total - offset
+				if n > limit {
+					n = limit
+				}
+				if n < 0 {
+					n = 0
+				}
+				rows += n
+				return int(n), tt.total, nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("readPages() error = %v, want %v", err, tt.wantErr)
+			}
+			if rows != tt.wantRows || calls != tt.wantCalls {
+				t.Errorf("readPages() read %d rows by %d calls, want %d by %d", rows, calls, tt.wantRows, tt.wantCalls)
+			}
+		})
+	}
+}
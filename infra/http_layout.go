@@ -1,8 +1,10 @@
 package infra
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"sort"
 	"time"
 
 	"git.countmax.ru/countmax/layoutconfig.api/domain"
@@ -22,6 +24,7 @@ type LayoutResponse struct {
 // apiLayouts docs
 // @Summary Get all layouts
 // @Description get slice of layouts/projects configuration with location, date, offset, limit parameters
+// @Description layouts are requested from the all databases in parallel, failed databases are listed in errors of the metadata
 // @Produce  json
 // @Produce  xml
 // @Tags common
@@ -46,28 +49,33 @@ func (s *Server) apiLayouts(c echo.Context) error {
 	if _, err := time.Parse("2006-01-02", dt); err != nil {
 		dt = time.Now().Format("2006-01-02")
 	}
-	var count int64
-	layouts := make(domain.Layouts, 0, limit)
 	perm := s.perm.FromRequest(c.Request())
-	for _, repo := range s.repoM.Repos() {
-		_layouts, _count, err := repo.FindLayouts(c.Request().Context(), loc, dt, offset, limit)
-		if err != nil {
-			s.log.Errorf("repo.FindLayouts error %s", err)
-			return s.responserMIME(c, http.StatusInternalServerError, ErrServerInternal(err))
-		}
-		currLen := int64(len(_layouts))
-	LLOOP:
-		for _, _layout := range _layouts { // TODO: check limit offset working!!!!
-			if !perm.CheckLayout(_layout.ID, "read") {
-				continue LLOOP
-			}
-			if currLen < limit {
-				layouts = append(layouts, _layout)
-				currLen++
+	results, errs := s.fanOut(c, func(ctx context.Context, repo domain.LayoutRepo) (interface{}, error) {
+		res := make(domain.Layouts, 0)
+		err := readPages(func(offset, limit int64) (int, int64, error) {
+			layouts, count, err := repo.FindLayouts(ctx, loc, dt, offset, limit)
+			for _, layout := range layouts {
+				if s.repoM.Routed(layout.ID, repo) && perm.CheckLayout(layout.ID, "read") {
+					res = append(res, layout)
+				}
 			}
+			return len(layouts), count, err
+		})
+		if err != nil {
+			return nil, err
 		}
-		count += _count
+		return res, nil
+	})
+	if len(results) == 0 && len(errs) > 0 {
+		return s.responserMIME(c, http.StatusInternalServerError, ErrServerInternal(errRepo))
+	}
+	all := make(domain.Layouts, 0)
+	for _, res := range results {
+		all = append(all, res.(domain.Layouts)...)
 	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	from, to := pageBounds(len(all), offset, limit)
+	layouts := all[from:to]
 	//
 	response := LayoutResponse{
 		Data: layouts,
@@ -76,8 +84,9 @@ func (s *Server) apiLayouts(c echo.Context) error {
 				Count:  int64(len(layouts)),
 				Offset: offset,
 				Limit:  limit,
-				Total:  count,
+				Total:  int64(len(all)),
 			},
+			Errors: errs,
 		},
 	}
 	s.log.Debugf("headers: %+v", c.Request().Header)
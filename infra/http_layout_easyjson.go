@@ -4,6 +4,7 @@ package infra
 
 import (
 	json "encoding/json"
+	connmanager "git.countmax.ru/countmax/layoutconfig.api/internal/connmanager"
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
@@ -40,6 +41,29 @@ func easyjson957c3c97DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra(in *jlexer.
 			(out.Data).UnmarshalEasyJSON(in)
 		case "result_set":
 			easyjson957c3c97DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra1(in, &out.ResultSet)
+		case "errors":
+			if in.IsNull() {
+				in.Skip()
+				out.Errors = nil
+			} else {
+				in.Delim('[')
+				if out.Errors == nil {
+					if !in.IsDelim(']') {
+						out.Errors = make(RepoErrors, 0, 2)
+					} else {
+						out.Errors = RepoErrors{}
+					}
+				} else {
+					out.Errors = (out.Errors)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 connmanager.RepoError
+					easyjson957c3c97DecodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(in, &v1)
+					out.Errors = append(out.Errors, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -64,6 +88,20 @@ func easyjson957c3c97EncodeGitCountmaxRuCountmaxLayoutconfigApiInfra(out *jwrite
 		out.RawString(prefix)
 		easyjson957c3c97EncodeGitCountmaxRuCountmaxLayoutconfigApiInfra1(out, in.ResultSet)
 	}
+	if len(in.Errors) != 0 {
+		const prefix string = ",\"errors\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.Errors {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				easyjson957c3c97EncodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(out, v3)
+			}
+			out.RawByte(']')
+		}
+	}
 	out.RawByte('}')
 }
 
@@ -90,6 +128,55 @@ func (v *LayoutResponse) UnmarshalJSON(data []byte) error {
 func (v *LayoutResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjson957c3c97DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra(l, v)
 }
+func easyjson957c3c97DecodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(in *jlexer.Lexer, out *connmanager.RepoError) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "dest":
+			out.Dest = string(in.String())
+		case "error":
+			out.Error = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson957c3c97EncodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(out *jwriter.Writer, in connmanager.RepoError) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"dest\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Dest))
+	}
+	{
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
+	out.RawByte('}')
+}
 func easyjson957c3c97DecodeGitCountmaxRuCountmaxLayoutconfigApiInfra1(in *jlexer.Lexer, out *ResultSet) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"strings"
 
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/acl"
 	"git.countmax.ru/countmax/layoutconfig.api/repos"
 	"github.com/labstack/echo/v4"
 )
@@ -29,6 +31,7 @@ import (
 // @Success 200 {object} domain.ZonesAttendance
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 413 {object} infra.ErrResponse
@@ -56,8 +59,16 @@ func (s *Server) apiMallZonesDataAttendance(c echo.Context) error {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: add FilteredZone read
-	data, err := repo.FindMallZonesDataAttendance(c.Request().Context(), from, to, groupBy, layoutID, useRawData, strings.Join(zoneIDs, ","))
+	zones, err := s.readableMallZones(c, repo, layoutID)
+	if err != nil {
+		s.log.Errorf("readableMallZones error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	filteredList := zones.filterList(zoneIDs)
+	if len(filteredList) == 0 {
+		return c.JSON(http.StatusOK, domain.ZonesAttendance{})
+	}
+	data, err := repo.FindMallZonesDataAttendance(c.Request().Context(), from, to, groupBy, layoutID, useRawData, strings.Join(filteredList, ","))
 	if err != nil {
 		if err == repos.ErrNotAllowedDataRange {
 			return c.JSON(http.StatusRequestEntityTooLarge, ErrPayloadTooLarge(err))
@@ -65,7 +76,7 @@ func (s *Server) apiMallZonesDataAttendance(c echo.Context) error {
 		s.log.Errorf("repo.FindMallZonesDataAttendance error %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
-	return c.JSON(http.StatusOK, data)
+	return c.JSON(http.StatusOK, data.FilterByZone(zones.allow))
 }
 
 // apiMallEntrancesDataAttendance docs
@@ -87,6 +98,7 @@ func (s *Server) apiMallZonesDataAttendance(c echo.Context) error {
 // @Success 200 {object} domain.EntrancesAttendance
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 413 {object} infra.ErrResponse
@@ -114,8 +126,15 @@ func (s *Server) apiMallEntrancesDataAttendance(c echo.Context) error {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: add FilteredEnter read
-	data, err := repo.FindMallEntrancesDataAttendance(c.Request().Context(), from, to, groupBy, layoutID, useRawData, strings.Join(enterIDs, ","))
+	filteredEnterIDsList, err := s.perm.FilteredEnters(c.Request(), layoutID, strings.Join(enterIDs, ","), acl.ActionRead)
+	if err != nil {
+		s.log.Errorf("perm.FilteredEnters error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if filteredEnterIDsList == "" {
+		return c.JSON(http.StatusOK, domain.EntrancesAttendance{})
+	}
+	data, err := repo.FindMallEntrancesDataAttendance(c.Request().Context(), from, to, groupBy, layoutID, useRawData, filteredEnterIDsList)
 	if err != nil {
 		if err == repos.ErrNotAllowedDataRange {
 			return c.JSON(http.StatusRequestEntityTooLarge, ErrPayloadTooLarge(err))
@@ -145,6 +164,7 @@ func (s *Server) apiMallEntrancesDataAttendance(c echo.Context) error {
 // @Success 200 {object} domain.RentersAttendance
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 413 {object} infra.ErrResponse
@@ -174,8 +194,15 @@ func (s *Server) apiRenterDataAttendance(c echo.Context) error {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: add perm ListFilteredRenters read
-	data, err := repo.FindRenterDataAttendance(c.Request().Context(), from, to, groupBy, layoutID, useRawData, strings.Join(renterIDs, ","))
+	filteredList, err := s.perm.FilteredStores(c.Request(), layoutID, strings.Join(renterIDs, ","), acl.ActionRead)
+	if err != nil {
+		s.log.Errorf("perm.FilteredStores error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if filteredList == "" {
+		return c.JSON(http.StatusOK, domain.RentersAttendance{})
+	}
+	data, err := repo.FindRenterDataAttendance(c.Request().Context(), from, to, groupBy, layoutID, useRawData, filteredList)
 	if err != nil {
 		if err == repos.ErrNotAllowedDataRange {
 			s.log.Errorf("repo.FindRenterDataAttendance too large error, %s", err)
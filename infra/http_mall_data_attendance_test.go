@@ -0,0 +1,144 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/connmanager"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// fakeAttendanceRepo returns attendance for every requested entity and remembers requested list,
+// zone zN of the chain belongs to the store s1 for N divisible by 100, to the store s2 otherwise.
+type fakeAttendanceRepo struct {
+	domain.DefImplLayoutRepo
+	requested string
+}
+
+func (r *fakeAttendanceRepo) Dest() string { return "fake" }
+
+func (r *fakeAttendanceRepo) FindLayouts(context.Context, string, string, int64, int64) (domain.Layouts, int64, error) {
+	return domain.Layouts{{ID: "10"}}, 1, nil
+}
+
+func (r *fakeAttendanceRepo) FindChainZones(_ context.Context, _, _, _, _, _, _, _, _ string,
+	offset, limit int64) (domain.ChainZones, int64, error) {
+	const total = 1200
+	zones := make(domain.ChainZones, 0, limit)
+	for i := offset; i < offset+limit && i < total; i++ {
+		zone := domain.ChainZone{ZoneID: fmt.Sprintf("z%d", i), StoreID: "s2"}
+		if i%100 == 0 {
+			zone.StoreID = "s1"
+		}
+		zones = append(zones, zone)
+	}
+	return zones, total, nil
+}
+
+func (r *fakeAttendanceRepo) FindMallZonesByRenter(_ context.Context, _, _, renterID string) (domain.MallZones, error) {
+	return domain.MallZones{{ZoneID: "zone-of-" + renterID}}, nil
+}
+
+func (r *fakeAttendanceRepo) FindChainZonesDataAttendance(_ context.Context, _, _ time.Time,
+	_, _, _, list string) (domain.ZonesAttendance, error) {
+	r.requested = list
+	return domain.ZonesAttendance{{ZoneID: "z0"}, {ZoneID: "z1"}}, nil
+}
+
+func (r *fakeAttendanceRepo) FindMallZonesDataAttendance(_ context.Context, _, _ time.Time,
+	_, _, _, list string) (domain.ZonesAttendance, error) {
+	r.requested = list
+	return domain.ZonesAttendance{{ZoneID: "zone-of-s1"}, {ZoneID: "zone-of-s2"}}, nil
+}
+
+func (r *fakeAttendanceRepo) FindMallEntrancesDataAttendance(_ context.Context, _, _ time.Time,
+	_, _, _, list string) (domain.EntrancesAttendance, error) {
+	r.requested = list
+	res := domain.EntrancesAttendance{}
+	for _, id := range strings.Split(list, ",") {
+		res = append(res, domain.EntranceAttendance{EntranceID: id})
+	}
+	return res, nil
+}
+
+func (r *fakeAttendanceRepo) FindRenterDataAttendance(_ context.Context, _, _ time.Time,
+	_, _, _, list string) (domain.RentersAttendance, error) {
+	r.requested = list
+	res := domain.RentersAttendance{}
+	for _, id := range strings.Split(list, ",") {
+		res = append(res, domain.RenterAttendance{RenterID: id})
+	}
+	return res, nil
+}
+
+func TestServer_dataAttendanceRestrictedByStores(t *testing.T) {
+	const uid = "byStore"
+	repo := &fakeAttendanceRepo{}
+	repoM, err := connmanager.NewStatic(repo)
+	if err != nil {
+		t.Fatalf("connmanager.NewStatic error, %s", err)
+	}
+	s := &Server{log: zap.NewNop().Sugar(), repoM: repoM, perm: newStoresPerm(t, uid, "10", "s1")}
+	e := echo.New()
+	tests := []struct {
+		name          string
+		handler       echo.HandlerFunc
+		query         string
+		wantRequested string
+		wantIDs       []string
+	}{
+		{"chain zones of the allowed store", s.apiChainZonesDataAttendance, "",
+			"z0,z100,z1000,z1100,z200,z300,z400,z500,z600,z700,z800,z900", []string{"z0"}},
+		{"mall zones of the allowed renter", s.apiMallZonesDataAttendance, "",
+			"zone-of-s1", []string{"zone-of-s1"}},
+		{"mall zones of the other renter", s.apiMallZonesDataAttendance, "&zone_ids=zone-of-s2",
+			"", []string{}},
+		{"allowed mall entrances", s.apiMallEntrancesDataAttendance, "&entrance_ids=e1,e2",
+			"e1", []string{"e1"}},
+		{"allowed renters", s.apiRenterDataAttendance, "&renter_ids=s1,s2",
+			"s1", []string{"s1"}},
+		{"other renters", s.apiRenterDataAttendance, "&renter_ids=s2",
+			"", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.requested = ""
+			req := httptest.NewRequest(http.MethodGet, "/?layout_id=10"+tt.query, nil)
+			setStoresPerm(req, uid, "10", "s1")
+			rec := httptest.NewRecorder()
+			if err := tt.handler(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+			if repo.requested != tt.wantRequested {
+				t.Errorf("requested from the repo %q, want %q", repo.requested, tt.wantRequested)
+			}
+			rows := []map[string]interface{}{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &rows); err != nil {
+				t.Fatalf("unmarshal %s error, %s", rec.Body.String(), err)
+			}
+			ids := make([]string, 0, len(rows))
+			for _, row := range rows {
+				for _, key := range []string{"zone_id", "entrance_id", "renter_id"} {
+					if id, ok := row[key].(string); ok {
+						ids = append(ids, id)
+					}
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("response ids = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ package infra
 
 import (
 	json "encoding/json"
+	connmanager "git.countmax.ru/countmax/layoutconfig.api/internal/connmanager"
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
@@ -40,6 +41,29 @@ func easyjsonFb6db89bDecodeGitCountmaxRuCountmaxLayoutconfigApiInfra(in *jlexer.
 			(out.Data).UnmarshalEasyJSON(in)
 		case "result_set":
 			easyjsonFb6db89bDecodeGitCountmaxRuCountmaxLayoutconfigApiInfra1(in, &out.ResultSet)
+		case "errors":
+			if in.IsNull() {
+				in.Skip()
+				out.Errors = nil
+			} else {
+				in.Delim('[')
+				if out.Errors == nil {
+					if !in.IsDelim(']') {
+						out.Errors = make(RepoErrors, 0, 2)
+					} else {
+						out.Errors = RepoErrors{}
+					}
+				} else {
+					out.Errors = (out.Errors)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 connmanager.RepoError
+					easyjsonFb6db89bDecodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(in, &v1)
+					out.Errors = append(out.Errors, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -64,6 +88,20 @@ func easyjsonFb6db89bEncodeGitCountmaxRuCountmaxLayoutconfigApiInfra(out *jwrite
 		out.RawString(prefix)
 		easyjsonFb6db89bEncodeGitCountmaxRuCountmaxLayoutconfigApiInfra1(out, in.ResultSet)
 	}
+	if len(in.Errors) != 0 {
+		const prefix string = ",\"errors\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.Errors {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				easyjsonFb6db89bEncodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(out, v3)
+			}
+			out.RawByte(']')
+		}
+	}
 	out.RawByte('}')
 }
 
@@ -90,6 +128,55 @@ func (v *MallDevicesResponse) UnmarshalJSON(data []byte) error {
 func (v *MallDevicesResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonFb6db89bDecodeGitCountmaxRuCountmaxLayoutconfigApiInfra(l, v)
 }
+func easyjsonFb6db89bDecodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(in *jlexer.Lexer, out *connmanager.RepoError) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "dest":
+			out.Dest = string(in.String())
+		case "error":
+			out.Error = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonFb6db89bEncodeGitCountmaxRuCountmaxLayoutconfigApiInternalConnmanager(out *jwriter.Writer, in connmanager.RepoError) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"dest\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Dest))
+	}
+	{
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
+	out.RawByte('}')
+}
 func easyjsonFb6db89bDecodeGitCountmaxRuCountmaxLayoutconfigApiInfra1(in *jlexer.Lexer, out *ResultSet) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
@@ -1,7 +1,9 @@
 package infra
 
 import (
+	"context"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -18,7 +20,7 @@ type MallsResponse struct {
 // apiMalls docs
 // @Summary Get all malls in the mall schema
 // @Description get slice of malls with loc (location), date, offset, limit, fields parameters
-// @Description layout_id (recommended parameter), if not pass datasource may be not correct
+// @Description layout_id not passed or * - malls are requested from the all databases in parallel, failed databases are listed in errors of the metadata
 // @Description fields - comma separated values of field names, can be layout_id,kind,title,languages...
 // @Produce  json
 // @Tags malls
@@ -50,6 +52,9 @@ func (s *Server) apiMalls(c echo.Context) error {
 	if c.QueryParam("fields") == "" {
 		fields = nil
 	}
+	if isFanOut(c) {
+		return s.apiMallsFanOut(c, loc, dt, fields, offset, limit)
+	}
 	repo, _, err := s.getRepo(c)
 	if err != nil {
 		s.log.Errorf("getRepo error, %s", err)
@@ -76,6 +81,52 @@ func (s *Server) apiMalls(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
+// apiMallsFanOut requests malls from the all databases in parallel,
+// merges readable malls ordered by layout_id and returns requested page
+func (s *Server) apiMallsFanOut(c echo.Context, loc, dt string, fields []string, offset, limit int64) error {
+	perm := s.perm.FromRequest(c.Request())
+	results, errs := s.fanOut(c, func(ctx context.Context, repo domain.LayoutRepo) (interface{}, error) {
+		res := make(domain.Malls, 0)
+		err := readPages(func(offset, limit int64) (int, int64, error) {
+			malls, count, err := repo.FindMalls(ctx, loc, dt, offset, limit)
+			for _, mall := range malls {
+				if s.repoM.Routed(mall.LayoutID, repo) && perm.CheckLayout(mall.LayoutID, "read") {
+					res = append(res, mall)
+				}
+			}
+			return len(malls), count, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	})
+	if len(results) == 0 && len(errs) > 0 {
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
+	}
+	all := make(domain.Malls, 0)
+	for _, res := range results {
+		all = append(all, res.(domain.Malls)...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].LayoutID < all[j].LayoutID })
+	from, to := pageBounds(len(all), offset, limit)
+	malls := all[from:to]
+	malls.SetZeroValue(fields)
+	response := MallsResponse{
+		Data: malls,
+		Metadata: Metadata{
+			ResultSet: ResultSet{
+				Count:  int64(len(malls)),
+				Offset: offset,
+				Limit:  limit,
+				Total:  int64(len(all)),
+			},
+			Errors: errs,
+		},
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
 // apiMallByID docs
 // @Summary Get specified mall in the mall schema
 // @Description get mall with loc(ation), date, layout_id, fields parameters
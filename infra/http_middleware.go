@@ -1,10 +1,25 @@
 package infra
 
 import (
+	"bufio"
+	b64 "encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
 
 	"git.countmax.ru/countmax/layoutconfig.api/internal/acl"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/apikey"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/repodecor/instrument"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/repodecor/resilience"
 	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // middlewareCheckLayout - sec middleware for check access to layout
@@ -76,6 +91,23 @@ func (s *Server) middlewareCheckEnter(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+// middlewareCheckAdmin - sec middleware for check access to service administration,
+// allowed only by explicit permission to resource <namespace>:data.counting:admin passed in the request,
+// default policy isn't used
+func (s *Server) middlewareCheckAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		action := extractAction(c)
+		if !s.perm.CheckAdmin(c.Request(), action) {
+			s.log.Warnf("not permitted admin action %s", action)
+			return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+		}
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+		return nil
+	}
+}
+
 // helpers
 
 func extractAction(c echo.Context) acl.Action {
@@ -90,3 +122,183 @@ func extractAction(c echo.Context) acl.Action {
 	}
 	return action
 }
+
+// middlewareAPIKey - sec middleware for machine-to-machine clients,
+// api key passed via Authorization header (Bearer or ApiKey scheme) replaces user headers
+// by id and permissions of the key, so all next checks made by permission manager as usual
+func (s *Server) middlewareAPIKey(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		token, ok := apiKeyToken(req.Header.Get(echo.HeaderAuthorization))
+		if !ok {
+			if strings.HasPrefix(req.Header.Get(permission.XUserID), apikey.UserPrefix) {
+				s.log.Warnf("user id %s reserved for api keys", req.Header.Get(permission.XUserID))
+				return c.JSON(http.StatusUnauthorized, ErrUnAuthorized(apikey.ErrInvalid))
+			}
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			return nil
+		}
+		if s.keys == nil {
+			return c.JSON(http.StatusUnauthorized, ErrUnAuthorized(errAPIKeysDisabled))
+		}
+		key, err := apikey.Resolve(req.Context(), s.keys, token)
+		if err != nil {
+			if errors.Is(err, apikey.ErrInvalid) || errors.Is(err, apikey.ErrExpired) {
+				s.log.Warnf("api key rejected, %s", err)
+				return c.JSON(http.StatusUnauthorized, ErrUnAuthorized(err))
+			}
+			s.log.Errorf("apikey.Resolve error, %s", err)
+			return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+		}
+		if !key.AllLayouts() {
+			layoutID := c.QueryParam("layout_id")
+			if layoutID == "" {
+				layoutID = c.Param("layout_id")
+			}
+			if isAnyParam(layoutID) || !key.InScope(layoutID) {
+				s.log.Warnf("layout_id=%s out of scope of the api key %s", layoutID, key.ID)
+				return c.JSON(http.StatusForbidden, ErrForbidden(errAPIKeyScope))
+			}
+		}
+		bts, err := json.Marshal(key.Permissions)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+		}
+		req.Header.Set(permission.XUserID, key.UserID())
+		req.Header.Set(permission.XUserPermission, b64.StdEncoding.EncodeToString(bts))
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+		return nil
+	}
+}
+
+// apiKeyToken extracts api key token from the Authorization header value.
+func apiKeyToken(auth string) (string, bool) {
+	for _, scheme := range []string{"Bearer ", "ApiKey "} {
+		if len(auth) > len(scheme) && strings.EqualFold(auth[:len(scheme)], scheme) {
+			return strings.TrimSpace(auth[len(scheme):]), true
+		}
+	}
+	return "", false
+}
+
+// middlewareRejected replaces server error of the handler by 503 with Retry-After,
+// if query to the database was rejected by circuit breaker or limit of the concurrent queries.
+func (s *Server) middlewareRejected(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx, tracker := resilience.WithTracker(c.Request().Context())
+		c.SetRequest(c.Request().WithContext(ctx))
+		res := c.Response()
+		w := &rejectedWriter{ResponseWriter: res.Writer, tracker: tracker}
+		res.Writer = w
+		defer func() { res.Writer = w.ResponseWriter }()
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+		if w.rejected != nil {
+			s.log.Warnf("request %s rejected, %s", c.Request().URL.Path, w.rejected)
+			res.Status = http.StatusServiceUnavailable
+		}
+		return nil
+	}
+}
+
+// rejectedWriter writes 503 instead of the server error if tracker has rejection.
+type rejectedWriter struct {
+	http.ResponseWriter
+	tracker  *resilience.Tracker
+	rejected *resilience.RejectedError
+}
+
+// WriteHeader writes 503 with body and Retry-After for rejected requests.
+func (w *rejectedWriter) WriteHeader(code int) {
+	if code >= http.StatusInternalServerError {
+		w.rejected = w.tracker.Rejected()
+	}
+	if w.rejected == nil {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	retry := int64(math.Ceil(w.rejected.RetryAfter.Seconds()))
+	if retry < 1 {
+		retry = 1
+	}
+	w.Header().Set("Retry-After", strconv.FormatInt(retry, 10))
+	w.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	w.ResponseWriter.WriteHeader(http.StatusServiceUnavailable)
+	bts, _ := json.Marshal(ErrServiceUnavailable(w.rejected))
+	_, _ = w.ResponseWriter.Write(bts)
+}
+
+// Write drops body of the handler for rejected requests.
+func (w *rejectedWriter) Write(b []byte) (int, error) {
+	if w.rejected != nil {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (w *rejectedWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker for websockets.
+func (w *rejectedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// middlewareTrace starts span of the request and puts request id to the context,
+// spans of the queries to the repos are children of the request span.
+func (s *Server) middlewareTrace(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		rID := c.Response().Header().Get(echo.HeaderXRequestID)
+		ctx := instrument.WithRequestID(req.Context(), rID)
+		ctx, span := instrument.Tracer().Start(ctx, req.Method+" "+c.Path(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request_id", rID),
+				attribute.String("http.method", req.Method),
+				attribute.String("http.route", c.Path()),
+			))
+		defer span.End()
+		c.SetRequest(req.WithContext(ctx))
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+		span.SetAttributes(attribute.Int("http.status_code", c.Response().Status))
+		return nil
+	}
+}
+
+// setOrigins sets allowed origins of the CORS requests by comma separated list,
+// list is replaced on config reload.
+func (s *Server) setOrigins(raw string) {
+	origins := make([]string, 0)
+	for _, o := range strings.Split(raw, ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			origins = append(origins, o)
+		}
+	}
+	s.origins.Store(origins)
+}
+
+// allowOrigin checks origin of the CORS request, allowed origins can contain wildcards.
+func (s *Server) allowOrigin(origin string) (bool, error) {
+	origins, _ := s.origins.Load().([]string)
+	for _, o := range origins {
+		if o == "*" || o == origin {
+			return true, nil
+		}
+		if ok, _ := path.Match(o, origin); ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
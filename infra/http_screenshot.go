@@ -42,6 +42,7 @@ type ScreenshotsResponse struct {
 // @Success 200 {object} infra.ScreenshotsResponse
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 500 {object} infra.ErrResponse
@@ -66,11 +67,21 @@ func (s *Server) apiGetScreenshots(c echo.Context) error {
 		}
 		deviceIDs = defDeviceIDs
 	}
+	stores, err := s.readableStores(c, layoutID)
+	if err != nil {
+		s.log.Errorf("readableStores error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !storeParamAllowed(stores, storeID) {
+		s.log.Warnf("not permitted read for layout %s and store %s", layoutID, storeID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	// request screens with devices ids
 	screens, count, err := s.dmRepo.FindScreens(layoutID, storeID, status, deviceIDs, from, to, limit, offset)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
 	}
+	screens = screens.FilterByStore(stores.Allow)
 	if len(screens) == 0 {
 		s.log.With(zap.String("layoutID", layoutID), zap.String("storeID", storeID), zap.String("deviceID", deviceID)).
 			Warnf("from=%s, to=%s, offset=%d, limit=%d, nothing not found", from, to, offset, limit)
@@ -113,6 +124,7 @@ func (s *Server) apiGetScreenshots(c echo.Context) error {
 // @Success 200 {object} domain.Screenshots
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 500 {object} infra.ErrResponse
@@ -147,11 +159,21 @@ func (s *Server) apiGetScreenshotsAtTime(c echo.Context) error {
 		deviceIDs = defDeviceIDs
 	}
 
+	stores, err := s.readableStores(c, layoutID)
+	if err != nil {
+		s.log.Errorf("readableStores error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !storeParamAllowed(stores, storeID) {
+		s.log.Warnf("not permitted read for layout %s and store %s", layoutID, storeID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	// request screens with devices ids
 	screens, err := s.dmRepo.FindScreensAtTime(layoutID, storeID, deviceIDs, at)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
 	}
+	screens = screens.FilterByStore(stores.Allow)
 	if len(screens) == 0 {
 		s.log.With(zap.String("layoutID", layoutID), zap.String("storeID", storeID), zap.Strings("deviceIDs", deviceIDs)).Warnf("at=%s, nothing not found", at)
 		return c.JSON(http.StatusNotFound, ErrNotFound(fmt.Errorf("at=%s for layout_id=%s, store_id=%s and device_ids=%v does't have screens", at, layoutID, storeID, deviceIDs)))
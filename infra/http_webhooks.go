@@ -0,0 +1,475 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/webhook"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// ctxCreatedID key of the context for id of the entity created by handler
+	ctxCreatedID string = "created_id"
+	// maxPublishedBody max size of the request entity sent in the webhook message
+	maxPublishedBody int64  = 1 << 20
+	webhooksConsumer string = "webhooks"
+)
+
+var (
+	errWebhooksDisabled error = errors.New("webhooks disabled, webhooks.url is empty")
+)
+
+// WebhookRequest parameters of the webhook, secret is generated if empty
+type WebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	Kinds      []string `json:"kinds,omitempty"`
+	Keys       []string `json:"keys,omitempty"`
+	Severities []string `json:"severities,omitempty"`
+	IsActive   *bool    `json:"is_active,omitempty"`
+}
+
+// WebhooksResponse http wrapper for the list of webhooks
+type WebhooksResponse struct {
+	Data []webhook.Hook `json:"data"`
+}
+
+// WebhookDeliveriesResponse http wrapper with metadata for the delivery log
+type WebhookDeliveriesResponse struct {
+	Data     []*webhook.Delivery `json:"data"`
+	Metadata Metadata            `json:"metadata"`
+}
+
+// apiCreateWebhook docs
+// @Summary Create webhook of the layout
+// @Description create webhook delivering changes of the layout entities and events as signed json,
+// @Description kinds: event, chain, store, entrance, zone, device, sensor, binding, behavior; empty - all,
+// @Description keys and severities filter only events; secret is generated if empty and returned only here,
+// @Description signature: X-Webhook-Signature: sha256=hex(hmac_sha256(secret, X-Webhook-Timestamp + "." + body)),
+// @Description url resolved to the private, loopback or link-local address is rejected unless host is in webhooks.allowed_hosts
+// @Accept json
+// @Produce json
+// @Tags webhooks
+// @Param layout_id path string true "layout id"
+// @Param webhook body infra.WebhookRequest true "url and filters"
+// @Success 201 {object} webhook.Hook
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/layouts/{layout_id}/webhooks [post]
+func (s *Server) apiCreateWebhook(c echo.Context) error {
+	if s.webhooks == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errWebhooksDisabled))
+	}
+	p := &WebhookRequest{}
+	if err := c.Bind(p); err != nil {
+		s.log.Errorf("apiCreateWebhook, bad request error %v", err)
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	}
+	h, err := webhook.NewHook(webhook.Hook{LayoutID: c.Param("layout_id"), URL: p.URL, Secret: p.Secret,
+		Kinds: p.Kinds, Keys: p.Keys, Severities: p.Severities}, s.actor(c), time.Now())
+	if err != nil {
+		return s.webhookError(c, err)
+	}
+	if err := s.deliverer.CheckURL(c.Request().Context(), h.URL); err != nil {
+		return s.webhookError(c, err)
+	}
+	if p.IsActive != nil {
+		h.IsActive = *p.IsActive
+	}
+	if err := s.webhooks.PutHook(c.Request().Context(), h); err != nil {
+		return s.webhookError(c, err)
+	}
+	s.log.Infof("webhook %s of layout %s created by %s", h.ID, h.LayoutID, h.Creator)
+	return c.JSON(http.StatusCreated, h)
+}
+
+// apiWebhooks docs
+// @Summary Get webhooks of the layout
+// @Description get webhooks of the layout without secrets
+// @Produce json
+// @Tags webhooks
+// @Param layout_id path string true "layout id"
+// @Success 200 {object} infra.WebhooksResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/layouts/{layout_id}/webhooks [get]
+func (s *Server) apiWebhooks(c echo.Context) error {
+	if s.webhooks == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(errWebhooksDisabled))
+	}
+	hooks, err := s.webhooks.ListHooks(c.Request().Context(), c.Param("layout_id"))
+	if err != nil {
+		return s.webhookError(c, err)
+	}
+	res := WebhooksResponse{Data: make([]webhook.Hook, 0, len(hooks))}
+	for _, h := range hooks {
+		res.Data = append(res.Data, h.Public())
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// apiWebhookByID docs
+// @Summary Get webhook by id
+// @Description get webhook of the layout without secret
+// @Produce json
+// @Tags webhooks
+// @Param layout_id path string true "layout id"
+// @Param webhook_id path string true "webhook id"
+// @Success 200 {object} webhook.Hook
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/layouts/{layout_id}/webhooks/{webhook_id} [get]
+func (s *Server) apiWebhookByID(c echo.Context) error {
+	h, err := s.webhookOf(c)
+	if err != nil {
+		return s.webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, h.Public())
+}
+
+// apiUpdWebhook docs
+// @Summary Update webhook
+// @Description replace url and filters of the webhook, secret is kept if empty, is_active is kept if not passed,
+// @Description url resolved to the private, loopback or link-local address is rejected unless host is in webhooks.allowed_hosts
+// @Accept json
+// @Produce json
+// @Tags webhooks
+// @Param layout_id path string true "layout id"
+// @Param webhook_id path string true "webhook id"
+// @Param webhook body infra.WebhookRequest true "url and filters"
+// @Success 200 {object} webhook.Hook
+// @Failure 400 {object} infra.ErrResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/layouts/{layout_id}/webhooks/{webhook_id} [put]
+func (s *Server) apiUpdWebhook(c echo.Context) error {
+	h, err := s.webhookOf(c)
+	if err != nil {
+		return s.webhookError(c, err)
+	}
+	p := &WebhookRequest{}
+	if err := c.Bind(p); err != nil {
+		s.log.Errorf("apiUpdWebhook, bad request error %v", err)
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	}
+	h.URL, h.Kinds, h.Keys, h.Severities = p.URL, p.Kinds, p.Keys, p.Severities
+	if p.Secret != "" {
+		h.Secret = p.Secret
+	}
+	if p.IsActive != nil {
+		h.IsActive = *p.IsActive
+	}
+	if err := webhook.Validate(*h); err != nil {
+		return s.webhookError(c, err)
+	}
+	if err := s.deliverer.CheckURL(c.Request().Context(), h.URL); err != nil {
+		return s.webhookError(c, err)
+	}
+	now := time.Now()
+	h.ModifiedAt = &now
+	if err := s.webhooks.PutHook(c.Request().Context(), h); err != nil {
+		return s.webhookError(c, err)
+	}
+	s.log.Infof("webhook %s updated by %s", h.ID, s.actor(c))
+	return c.JSON(http.StatusOK, h.Public())
+}
+
+// apiDeleteWebhook docs
+// @Summary Delete webhook
+// @Description delete webhook with its delivery log
+// @Produce json
+// @Tags webhooks
+// @Param layout_id path string true "layout id"
+// @Param webhook_id path string true "webhook id"
+// @Success 200 {object} infra.SuccessResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/layouts/{layout_id}/webhooks/{webhook_id} [delete]
+func (s *Server) apiDeleteWebhook(c echo.Context) error {
+	h, err := s.webhookOf(c)
+	if err != nil {
+		return s.webhookError(c, err)
+	}
+	if err := s.webhooks.DelHook(c.Request().Context(), h.ID); err != nil {
+		return s.webhookError(c, err)
+	}
+	s.log.Infof("webhook %s deleted by %s", h.ID, s.actor(c))
+	return c.JSON(http.StatusOK, OkStatus(fmt.Sprintf("deleted webhook %s", h.ID)))
+}
+
+// apiWebhookDeliveries docs
+// @Summary Get delivery log of the webhook
+// @Description get deliveries of the webhook, the newest first, status: pending, retrying, delivered, dead
+// @Produce json
+// @Tags webhooks
+// @Param layout_id path string true "layout id"
+// @Param webhook_id path string true "webhook id"
+// @Param status query string false "comma separated statuses"
+// @Param offset query int false "offset"
+// @Param limit query int false "limit"
+// @Success 200 {object} infra.WebhookDeliveriesResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/layouts/{layout_id}/webhooks/{webhook_id}/deliveries [get]
+func (s *Server) apiWebhookDeliveries(c echo.Context) error {
+	var statuses []string
+	if status := c.QueryParam("status"); status != "" {
+		statuses = strings.Split(status, ",")
+	}
+	return s.webhookDeliveries(c, statuses)
+}
+
+// apiWebhookDeadLetters docs
+// @Summary Get dead letters of the webhook
+// @Description get deliveries of the webhook failed all attempts, the newest first
+// @Produce json
+// @Tags webhooks
+// @Param layout_id path string true "layout id"
+// @Param webhook_id path string true "webhook id"
+// @Param offset query int false "offset"
+// @Param limit query int false "limit"
+// @Success 200 {object} infra.WebhookDeliveriesResponse
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/layouts/{layout_id}/webhooks/{webhook_id}/deadletters [get]
+func (s *Server) apiWebhookDeadLetters(c echo.Context) error {
+	return s.webhookDeliveries(c, []string{webhook.StatusDead})
+}
+
+// apiRedeliverWebhook docs
+// @Summary Redeliver message of the webhook
+// @Description send delivery again with reset attempts, usually from the dead letters
+// @Produce json
+// @Tags webhooks
+// @Param layout_id path string true "layout id"
+// @Param webhook_id path string true "webhook id"
+// @Param delivery_id path string true "delivery id"
+// @Success 200 {object} webhook.Delivery
+// @Failure 403 {object} infra.ErrResponse
+// @Failure 404 {object} infra.ErrResponse
+// @Failure 500 {object} infra.ErrResponse
+// @Failure 503 {object} infra.ErrResponse
+// @Router /v2/layouts/{layout_id}/webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver [post]
+func (s *Server) apiRedeliverWebhook(c echo.Context) error {
+	h, err := s.webhookOf(c)
+	if err != nil {
+		return s.webhookError(c, err)
+	}
+	ctx := c.Request().Context()
+	dl, err := s.webhooks.GetDelivery(ctx, c.Param("delivery_id"))
+	if err == nil && dl.HookID != h.ID {
+		err = webhook.ErrNotFound
+	}
+	if err != nil {
+		return s.webhookError(c, err)
+	}
+	if dl, err = s.deliverer.Redeliver(ctx, dl.ID); err != nil {
+		return s.webhookError(c, err)
+	}
+	s.log.Infof("delivery %s of webhook %s redelivered by %s", dl.ID, h.ID, s.actor(c))
+	return c.JSON(http.StatusOK, dl)
+}
+
+// webhookDeliveries responses page of the deliveries of the webhook with statuses.
+func (s *Server) webhookDeliveries(c echo.Context, statuses []string) error {
+	h, err := s.webhookOf(c)
+	if err != nil {
+		return s.webhookError(c, err)
+	}
+	offset, limit := s.getPageParams(c)
+	ds, err := s.webhooks.ListDeliveries(c.Request().Context(), webhook.DeliveryQuery{HookID: h.ID, Statuses: statuses})
+	if err != nil {
+		return s.webhookError(c, err)
+	}
+	total := int64(len(ds))
+	page := make([]*webhook.Delivery, 0)
+	if offset < total {
+		end := offset + limit
+		if end > total {
+			end = total
+		}
+		page = ds[offset:end]
+	}
+	return c.JSON(http.StatusOK, WebhookDeliveriesResponse{
+		Data: page,
+		Metadata: Metadata{
+			ResultSet: ResultSet{
+				Count:  int64(len(page)),
+				Offset: offset,
+				Limit:  limit,
+				Total:  total,
+			},
+		},
+	})
+}
+
+// webhookOf returns webhook of the request, webhook of the other layout is not found.
+func (s *Server) webhookOf(c echo.Context) (*webhook.Hook, error) {
+	if s.webhooks == nil {
+		return nil, errWebhooksDisabled
+	}
+	h, err := s.webhooks.GetHook(c.Request().Context(), c.Param("webhook_id"))
+	if err != nil {
+		return nil, err
+	}
+	if h.LayoutID != c.Param("layout_id") {
+		return nil, webhook.ErrNotFound
+	}
+	return h, nil
+}
+
+// webhookError responses by error of the webhooks.
+func (s *Server) webhookError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, errWebhooksDisabled):
+		return c.JSON(http.StatusServiceUnavailable, ErrServiceUnavailable(err))
+	case errors.Is(err, webhook.ErrInvalid), errors.Is(err, webhook.ErrForbiddenHost):
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+	case errors.Is(err, webhook.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrNotFound(err))
+	}
+	s.log.Errorf("webhooks error, %s", err)
+	return c.JSON(http.StatusInternalServerError, ErrServerInternal(err))
+}
+
+// middlewarePublish publishes succeeded change of the entity of the kind to the webhooks,
+// id of the entity is taken from the created id, path param <kind>_id or the request entity.
+func (s *Server) middlewarePublish(kind string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if s.deliverer == nil {
+				return next(c)
+			}
+			var body []byte
+			req := c.Request()
+			if req.Body != nil && req.Method != http.MethodDelete {
+				var err error
+				body, err = ioutil.ReadAll(io.LimitReader(req.Body, maxPublishedBody+1))
+				if err != nil {
+					return c.JSON(http.StatusBadRequest, ErrInvalidRequest(err))
+				}
+				req.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+			}
+			err := next(c)
+			status := c.Response().Status
+			if err != nil || status < http.StatusOK || status >= http.StatusMultipleChoices {
+				return err
+			}
+			m := changeMessage(c, kind, body)
+			if m.LayoutID == "" {
+				return nil
+			}
+			if err := s.publish(context.Background(), m); err != nil {
+				s.log.Errorf("publish %s %s to webhooks error, %s", m.Kind, m.Action, err)
+			}
+			return nil
+		}
+	}
+}
+
+// changeMessage makes message of the change of the entity by the request.
+func changeMessage(c echo.Context, kind string, body []byte) webhook.Message {
+	m := webhook.Message{Kind: kind, LayoutID: c.QueryParam("layout_id")}
+	switch c.Request().Method {
+	case http.MethodPost:
+		m.Action = webhook.ActionCreate
+	case http.MethodDelete:
+		m.Action = webhook.ActionDelete
+	default:
+		m.Action = webhook.ActionUpdate
+	}
+	fields := map[string]interface{}{}
+	if int64(len(body)) <= maxPublishedBody && json.Valid(body) {
+		m.Data = json.RawMessage(body)
+		_ = json.Unmarshal(body, &fields)
+	} else if m.Action == webhook.ActionDelete {
+		// entity of the deletion is passed by query params
+		params := make(map[string]string)
+		for name := range c.QueryParams() {
+			params[name] = c.QueryParam(name)
+		}
+		if len(params) > 0 {
+			m.Data, _ = json.Marshal(params)
+		}
+	}
+	field := func(name string) string {
+		if v, ok := fields[name].(string); ok {
+			return v
+		}
+		return ""
+	}
+	if m.LayoutID == "" {
+		m.LayoutID = c.Param("layout_id")
+	}
+	if m.LayoutID == "" {
+		m.LayoutID = field("layout_id")
+	}
+	idName := kind + "_id"
+	if kind == webhook.KindChain {
+		idName = "layout_id"
+	}
+	if id, ok := c.Get(ctxCreatedID).(string); ok {
+		m.EntityID = id
+	} else if id := c.Param(idName); id != "" {
+		m.EntityID = id
+	} else if id := c.QueryParam(idName); id != "" {
+		m.EntityID = id
+	} else {
+		m.EntityID = field(idName)
+	}
+	return m
+}
+
+// publish sends message to the webhooks.
+func (s *Server) publish(ctx context.Context, m webhook.Message) error {
+	m, err := webhook.NewMessage(m, time.Now())
+	if err != nil {
+		return err
+	}
+	return s.deliverer.Publish(ctx, m)
+}
+
+// publishEvents sends events of the events database and changes of their states to the webhooks.
+func (s *Server) publishEvents(ctx context.Context) {
+	s.log.Debug("start publishing events to webhooks")
+	defer s.log.Debug("stop publishing events to webhooks")
+	for e := range s.consumeAllEvents(ctx, webhooksConsumer) {
+		m := eventMessage(e)
+		if err := s.publish(ctx, m); err != nil {
+			s.log.Errorf("publish event %s to webhooks error, %s", e.ID, err)
+		}
+	}
+}
+
+// eventMessage makes message of the event, action is set for the change of the state.
+func eventMessage(e domain.Event) webhook.Message {
+	m := webhook.Message{Kind: webhook.KindEvent, LayoutID: e.LayoutID, EntityID: e.ID, Event: &e}
+	if e.Change != nil {
+		m.Action = e.Change.Action
+		m.Time = e.Change.Time
+	}
+	return m
+}
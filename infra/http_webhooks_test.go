@@ -0,0 +1,112 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/webhook"
+	hooksmem "git.countmax.ru/countmax/layoutconfig.api/internal/webhook/mem"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+func TestServer_webhooks(t *testing.T) {
+	ctx := context.Background()
+	hooks := hooksmem.New()
+	s := &Server{log: zap.NewNop().Sugar(), webhooks: hooks,
+		deliverer: webhook.NewDispatcher(hooks, webhook.Config{QueueSize: 10,
+			AllowedHosts: []string{"receiver.local"}})}
+	e := echo.New()
+	call := func(method, target, body string, names, values []string, handler echo.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(permission.XUserID, "integrator")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames(names...)
+		c.SetParamValues(values...)
+		if err := handler(c); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		return rec
+	}
+
+	rec := call(http.MethodPost, "/", `{"url":"http://receiver.local/hook","kinds":["store"]}`,
+		[]string{"layout_id"}, []string{"10"}, s.apiCreateWebhook)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d, %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	h := webhook.Hook{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &h); err != nil || h.Secret == "" || h.Creator != "integrator" {
+		t.Fatalf("created webhook = %+v, %v, want webhook with secret of integrator", h, err)
+	}
+	if rec = call(http.MethodPost, "/", `{"url":"ftp://receiver.local"}`, []string{"layout_id"}, []string{"10"},
+		s.apiCreateWebhook); rec.Code != http.StatusBadRequest {
+		t.Errorf("create with ftp url status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec = call(http.MethodPost, "/", `{"url":"http://127.0.0.1:8080/hook"}`, []string{"layout_id"}, []string{"10"},
+		s.apiCreateWebhook); rec.Code != http.StatusBadRequest {
+		t.Errorf("create with loopback url status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	rec = call(http.MethodGet, "/", "", []string{"layout_id"}, []string{"10"}, s.apiWebhooks)
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), h.Secret) {
+		t.Errorf("list status = %d, %s, want webhooks without secret", rec.Code, rec.Body.String())
+	}
+	if rec = call(http.MethodGet, "/", "", []string{"layout_id", "webhook_id"}, []string{"20", h.ID},
+		s.apiWebhookByID); rec.Code != http.StatusNotFound {
+		t.Errorf("get of other layout status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	addStore := func(c echo.Context) error {
+		c.Set(ctxCreatedID, "s1")
+		return c.JSON(http.StatusCreated, CreatedStatus("s1"))
+	}
+	failed := func(c echo.Context) error {
+		return c.JSON(http.StatusBadRequest, ErrInvalidRequest(errEmptyID))
+	}
+	body := `{"layout_id":"10","title":"Store 1"}`
+	call(http.MethodPost, "/v2/chains/stores", body, nil, nil, s.middlewarePublish(webhook.KindStore)(addStore))
+	call(http.MethodPost, "/v2/chains/stores", body, nil, nil, s.middlewarePublish(webhook.KindStore)(failed))
+	// other kind is filtered by webhook
+	call(http.MethodPost, "/v2/chains/zones", body, nil, nil, s.middlewarePublish(webhook.KindZone)(addStore))
+	call(http.MethodDelete, "/v2/chains/stores?layout_id=10&store_id=s2", "", nil, nil,
+		s.middlewarePublish(webhook.KindStore)(func(c echo.Context) error {
+			return c.JSON(http.StatusOK, OkStatus("deleted"))
+		}))
+
+	ds, err := hooks.ListDeliveries(ctx, webhook.DeliveryQuery{HookID: h.ID})
+	if err != nil || len(ds) != 2 {
+		t.Fatalf("deliveries = %d, %v, want 2", len(ds), err)
+	}
+	byAction := map[string]webhook.Message{}
+	for _, d := range ds {
+		byAction[d.Message.Action] = d.Message
+	}
+	if m := byAction[webhook.ActionCreate]; m.EntityID != "s1" || m.LayoutID != "10" || string(m.Data) != body {
+		t.Errorf("create message = %+v, want store s1 of layout 10 with request entity", m)
+	}
+	if m := byAction[webhook.ActionDelete]; m.EntityID != "s2" || m.LayoutID != "10" {
+		t.Errorf("delete message = %+v, want store s2 of layout 10", m)
+	}
+
+	rec = call(http.MethodGet, "/?limit=1", "", []string{"layout_id", "webhook_id"}, []string{"10", h.ID}, s.apiWebhookDeliveries)
+	res := WebhookDeliveriesResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil || len(res.Data) != 1 || res.Metadata.ResultSet.Total != 2 {
+		t.Errorf("deliveries page = %+v, %v, want 1 of 2", res, err)
+	}
+	rec = call(http.MethodPost, "/", "", []string{"layout_id", "webhook_id", "delivery_id"},
+		[]string{"10", h.ID, ds[0].ID}, s.apiRedeliverWebhook)
+	if rec.Code != http.StatusOK {
+		t.Errorf("redeliver status = %d, want %d, %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	disabled := &Server{log: zap.NewNop().Sugar()}
+	if rec = call(http.MethodGet, "/", "", []string{"layout_id"}, []string{"10"}, disabled.apiWebhooks); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("disabled list status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
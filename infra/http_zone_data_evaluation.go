@@ -25,6 +25,7 @@ import (
 // @Success 200 {object} domain.ZoneDataEvaluations
 // @Failure 400 {object} infra.HTTPError
 // @Failure 401 {object} infra.HTTPError
+// @Failure 403 {object} infra.ErrResponse
 // @Failure 405 {object} infra.HTTPError
 // @Failure 404 {object} infra.ErrResponse
 // @Failure 413 {object} infra.ErrResponse
@@ -43,11 +44,19 @@ func (s *Server) apiZoneDataEvaluation(c echo.Context) error {
 		s.log.Errorf("getRepo error, %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errInvalidDataSource))
 	}
-	// TODO: CheckLayout read
+	stores, err := s.readableStores(c, pLayoutID)
+	if err != nil {
+		s.log.Errorf("readableStores error, %s", err)
+		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errPermission))
+	}
+	if !storeParamAllowed(stores, pStoreID) {
+		s.log.Warnf("not permitted read for layout %s and store %s", pLayoutID, pStoreID)
+		return c.JSON(http.StatusForbidden, ErrForbidden(nil))
+	}
 	data, err := repo.FindZoneDataEvaluation(c.Request().Context(), from, to, pLayoutID, pStoreID, pSCB, pIsFull)
 	if err != nil {
 		s.log.Errorf("repo.FindZoneDataEvaluation error %s", err)
 		return c.JSON(http.StatusInternalServerError, ErrServerInternal(errRepo))
 	}
-	return c.JSON(http.StatusOK, data)
+	return c.JSON(http.StatusOK, data.FilterByStore(stores.Allow))
 }
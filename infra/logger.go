@@ -27,19 +27,10 @@ func (s *Server) setLogger(version, build, githash string) {
 	}
 
 	atom := zap.NewAtomicLevel()
-	switch s.config.GetString("log.level") {
-	case "debug", "debugging", "deb", "debag":
-		atom.SetLevel(zap.DebugLevel)
-	case "info", "information", "inf":
-		atom.SetLevel(zap.InfoLevel)
-	case "warn", "warning", "WARN":
-		atom.SetLevel(zap.WarnLevel)
-	case "err", "error":
-		atom.SetLevel(zap.ErrorLevel)
-	default:
-		atom.SetLevel(zap.InfoLevel)
-
-	}
+	// unknown level is info
+	level, _ := parseLevel(s.config.GetString("log.level"))
+	atom.SetLevel(level)
+	s.logLevel = atom
 
 	// To keep the example deterministic, disable timestamps in the output.
 	var encoderCfg zapcore.EncoderConfig
@@ -67,3 +58,18 @@ func (s *Server) setLogger(version, build, githash string) {
 
 	s.log = logger.Sugar()
 }
+
+// parseLevel returns logging level by its name from config, info and false for unknown name.
+func parseLevel(name string) (zapcore.Level, bool) {
+	switch name {
+	case "debug", "debugging", "deb", "debag":
+		return zap.DebugLevel, true
+	case "info", "information", "inf":
+		return zap.InfoLevel, true
+	case "warn", "warning", "WARN":
+		return zap.WarnLevel, true
+	case "err", "error":
+		return zap.ErrorLevel, true
+	}
+	return zap.InfoLevel, false
+}
@@ -0,0 +1,165 @@
+// Package apikey contains API keys of the machine-to-machine clients,
+// keys are stored hashed and bound to permissions, layouts scope and expiration.
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission"
+)
+
+const (
+	// UserPrefix prefix of the user id for requests authorized by the api key.
+	UserPrefix string = "apikey:"
+	idBytes    int    = 12
+	secretSize int    = 32
+	separator  string = "."
+)
+
+var (
+	// ErrNotFound key not exists in the repo.
+	ErrNotFound = errors.New("api key not found")
+	// ErrInvalid token malformed or secret not matched.
+	ErrInvalid = errors.New("invalid api key")
+	// ErrExpired key expired.
+	ErrExpired = errors.New("api key expired")
+)
+
+// Key api key of the client, secret of the key is not stored, only hash.
+type Key struct {
+	ID          string                 `json:"key_id"`
+	Title       string                 `json:"title"`
+	Hash        string                 `json:"hash,omitempty"`
+	Permissions permission.Permissions `json:"permissions"`
+	// Layouts scope of the key, empty or asterisk - all layouts
+	Layouts   []string   `json:"layouts"`
+	ExpireAt  *time.Time `json:"expire_at,omitempty"`
+	Creator   string     `json:"creator,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RepoInterface storage of the keys.
+type RepoInterface interface {
+	Get(ctx context.Context, id string) (*Key, error)
+	Add(ctx context.Context, k *Key) error
+	Del(ctx context.Context, id string) error
+	List(ctx context.Context) ([]*Key, error)
+}
+
+// New makes key with generated id and secret, returns key and token for the client,
+// token contains secret and shown only once.
+func New(title string, ps permission.Permissions, layouts []string,
+	expireAt *time.Time, creator string) (*Key, string, error) {
+	//
+	id, err := randomHex(idBytes)
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "generate id failed")
+	}
+	secret, err := randomHex(secretSize)
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "generate secret failed")
+	}
+	k := &Key{
+		ID:          id,
+		Title:       title,
+		Hash:        hash(secret),
+		Permissions: ps,
+		Layouts:     layouts,
+		ExpireAt:    expireAt,
+		Creator:     creator,
+		CreatedAt:   time.Now(),
+	}
+	return k, id + separator + secret, nil
+}
+
+// Parse splits token to id and secret of the key.
+func Parse(token string) (id, secret string, err error) {
+	parts := strings.SplitN(strings.TrimSpace(token), separator, 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", ErrInvalid
+	}
+	return parts[0], parts[1], nil
+}
+
+// Resolve finds key by token, verifies secret and expiration.
+func Resolve(ctx context.Context, repo RepoInterface, token string) (*Key, error) {
+	id, secret, err := Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	k, err := repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrInvalid
+		}
+		return nil, errors.WithMessage(err, "get key failed")
+	}
+	if !k.Verify(secret) {
+		return nil, ErrInvalid
+	}
+	if k.Expired(time.Now()) {
+		return nil, ErrExpired
+	}
+	return k, nil
+}
+
+// Verify checks secret by hash of the key.
+func (k *Key) Verify(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(k.Hash), []byte(hash(secret))) == 1
+}
+
+// Expired reports whether key expired at moment.
+func (k *Key) Expired(at time.Time) bool {
+	return k.ExpireAt != nil && at.After(*k.ExpireAt)
+}
+
+// InScope reports whether layout in the scope of the key.
+func (k *Key) InScope(layoutID string) bool {
+	if len(k.Layouts) == 0 {
+		return true
+	}
+	for _, id := range k.Layouts {
+		if id == "*" || id == layoutID {
+			return true
+		}
+	}
+	return false
+}
+
+// AllLayouts reports whether scope of the key not limited.
+func (k *Key) AllLayouts() bool {
+	return k.InScope("*")
+}
+
+// UserID returns id of the user for permission manager.
+func (k *Key) UserID() string {
+	return UserPrefix + k.ID
+}
+
+// Public returns copy of the key without hash.
+func (k *Key) Public() Key {
+	res := *k
+	res.Hash = ""
+	return res
+}
+
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,75 @@
+package apikey_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/apikey"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/apikey/mem"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission"
+)
+
+func TestResolve(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.New()
+	past := time.Now().Add(-time.Minute)
+	valid, validToken, err := apikey.New("bi", permission.DefaultAllow, []string{"118416189"}, nil, "admin")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	expired, expiredToken, err := apikey.New("1c", permission.DefaultAllow, nil, &past, "admin")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	for _, k := range []*apikey.Key{valid, expired} {
+		if err := repo.Add(ctx, k); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	if strings.Contains(valid.Hash, strings.SplitN(validToken, ".", 2)[1]) {
+		t.Error("secret must not be stored")
+	}
+	tests := []struct {
+		name    string
+		token   string
+		wantID  string
+		wantErr error
+	}{
+		{"1.valid", validToken, valid.ID, nil},
+		{"2.expired", expiredToken, "", apikey.ErrExpired},
+		{"3.wrongSecret", valid.ID + ".deadbeef", "", apikey.ErrInvalid},
+		{"4.unknownID", "unknown.deadbeef", "", apikey.ErrInvalid},
+		{"5.malformed", "deadbeef", "", apikey.ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := apikey.Resolve(ctx, repo, tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Resolve() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.ID != tt.wantID {
+				t.Errorf("Resolve() = %s, want %s", got.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestKey_InScope(t *testing.T) {
+	scoped := apikey.Key{Layouts: []string{"118416189", "37664168"}}
+	if !scoped.InScope("37664168") {
+		t.Error("InScope(37664168) = false, want true")
+	}
+	if scoped.InScope("73685311") {
+		t.Error("InScope(73685311) = true, want false")
+	}
+	if scoped.AllLayouts() {
+		t.Error("AllLayouts() = true, want false")
+	}
+	all := apikey.Key{}
+	if !all.InScope("73685311") || !all.AllLayouts() {
+		t.Error("key without scope must allow all layouts")
+	}
+}
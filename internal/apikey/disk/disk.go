@@ -0,0 +1,100 @@
+// Package disk implements apikey.RepoInterface in the embedded on-disk database.
+package disk
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/apikey"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/boltdb"
+)
+
+var bucketKeys = []byte("apikeys")
+
+// Repo on-disk storage of the keys.
+type Repo struct {
+	db *bolt.DB
+}
+
+// New builder for Repo, opens or creates database file by path.
+func New(path string) (*Repo, error) {
+	db, err := boltdb.Open(path, bucketKeys)
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{db: db}, nil
+}
+
+// Get returns key by id, if not exists returns apikey.ErrNotFound.
+func (r *Repo) Get(ctx context.Context, id string) (*apikey.Key, error) {
+	var bts []byte
+	err := r.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketKeys).Get([]byte(id))
+		if v != nil {
+			bts = append([]byte{}, v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "bolt view failed")
+	}
+	if bts == nil {
+		return nil, apikey.ErrNotFound
+	}
+	k := &apikey.Key{}
+	if err := json.Unmarshal(bts, k); err != nil {
+		return nil, errors.Wrap(err, "unmarshal key failed")
+	}
+	return k, nil
+}
+
+// Add puts or replaces key.
+func (r *Repo) Add(ctx context.Context, k *apikey.Key) error {
+	bts, err := json.Marshal(k)
+	if err != nil {
+		return errors.Wrap(err, "marshal key failed")
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketKeys).Put([]byte(k.ID), bts)
+	})
+}
+
+// Del removes key, if not exists returns apikey.ErrNotFound.
+func (r *Repo) Del(ctx context.Context, id string) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketKeys)
+		if b.Get([]byte(id)) == nil {
+			return apikey.ErrNotFound
+		}
+		return b.Delete([]byte(id))
+	})
+}
+
+// List returns all keys ordered by creation time.
+func (r *Repo) List(ctx context.Context) ([]*apikey.Key, error) {
+	res := make([]*apikey.Key, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketKeys).ForEach(func(_, v []byte) error {
+			k := &apikey.Key{}
+			if err := json.Unmarshal(v, k); err != nil {
+				return errors.Wrap(err, "unmarshal key failed")
+			}
+			res = append(res, k)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].CreatedAt.Before(res[j].CreatedAt) })
+	return res, nil
+}
+
+// Close closes database file.
+func (r *Repo) Close() error {
+	return r.db.Close()
+}
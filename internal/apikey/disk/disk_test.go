@@ -0,0 +1,65 @@
+package disk_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/apikey"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/apikey/disk"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission"
+)
+
+func TestRepo_AddListDelReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apikeys")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir error, %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "apikeys.db")
+	ctx := context.Background()
+	r, err := disk.New(path)
+	if err != nil {
+		t.Fatalf("disk.New error, %s", err)
+	}
+	k1, token, err := apikey.New("bi", permission.DefaultAllow, []string{"118416189"}, nil, "admin")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	k2, _, err := apikey.New("1c", permission.DefaultDeny, nil, nil, "admin")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	for _, k := range []*apikey.Key{k1, k2} {
+		if err := r.Add(ctx, k); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	if err := r.Del(ctx, k2.ID); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if err := r.Del(ctx, k2.ID); !errors.Is(err, apikey.ErrNotFound) {
+		t.Errorf("Repo.Del() twice error = %v, want %v", err, apikey.ErrNotFound)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	reopened, err := disk.New(path)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	defer reopened.Close()
+	keys, err := reopened.List(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if len(keys) != 1 || keys[0].ID != k1.ID {
+		t.Fatalf("Repo.List() = %v, want only %s", keys, k1.ID)
+	}
+	if _, err := apikey.Resolve(ctx, reopened, token); err != nil {
+		t.Errorf("Resolve() after reopen error, %s", err)
+	}
+}
@@ -0,0 +1,65 @@
+// Package mem implements apikey.RepoInterface in the process memory,
+// keys are lost on restart of the service.
+package mem
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/apikey"
+)
+
+// Repo memory storage of the keys.
+type Repo struct {
+	mu   sync.RWMutex
+	keys map[string]apikey.Key
+}
+
+// New builder for Repo.
+func New() *Repo {
+	return &Repo{keys: make(map[string]apikey.Key)}
+}
+
+// Get returns key by id, if not exists returns apikey.ErrNotFound.
+func (r *Repo) Get(ctx context.Context, id string) (*apikey.Key, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	k, ok := r.keys[id]
+	if !ok {
+		return nil, apikey.ErrNotFound
+	}
+	return &k, nil
+}
+
+// Add puts or replaces key.
+func (r *Repo) Add(ctx context.Context, k *apikey.Key) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[k.ID] = *k
+	return nil
+}
+
+// Del removes key, if not exists returns apikey.ErrNotFound.
+func (r *Repo) Del(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keys[id]; !ok {
+		return apikey.ErrNotFound
+	}
+	delete(r.keys, id)
+	return nil
+}
+
+// List returns all keys ordered by creation time.
+func (r *Repo) List(ctx context.Context) ([]*apikey.Key, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]*apikey.Key, 0, len(r.keys))
+	for id := range r.keys {
+		k := r.keys[id]
+		res = append(res, &k)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].CreatedAt.Before(res[j].CreatedAt) })
+	return res, nil
+}
@@ -0,0 +1,67 @@
+// Package boltdb opens the embedded on-disk databases of the stores by url,
+// url can be memory or file:///path/to/file.db.
+package boltdb
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Memory url of the store in memory of the process.
+const Memory = "memory"
+
+const (
+	fileMode    os.FileMode   = 0600
+	dirMode     os.FileMode   = 0700
+	openTimeout time.Duration = 5 * time.Second
+)
+
+var (
+	// ErrUnsupported scheme of the url not memory or file.
+	ErrUnsupported = errors.New("unsupported url, allowed memory, file://")
+)
+
+// Path returns path of the database file by url, for memory returns empty path.
+func Path(rawURL string) (string, error) {
+	if rawURL == Memory {
+		return "", nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "parse url %s failed", rawURL)
+	}
+	if u.Scheme != "file" || u.Path == "" {
+		return "", errors.Wrapf(ErrUnsupported, "url %s", rawURL)
+	}
+	return u.Path, nil
+}
+
+// Open opens or creates database file by path with the buckets,
+// missing directories of the path are created.
+func Open(path string, buckets ...[]byte) (*bolt.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return nil, errors.Wrapf(err, "make dir of the %s failed", path)
+	}
+	db, err := bolt.Open(path, fileMode, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, errors.Wrapf(err, "bolt.Open %s failed", path)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "create buckets failed")
+	}
+	return db, nil
+}
@@ -0,0 +1,59 @@
+package boltdb_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/boltdb"
+)
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr error
+	}{
+		{"memory", "", nil},
+		{"file:///var/lib/layoutconfig/apikeys.db", "/var/lib/layoutconfig/apikeys.db", nil},
+		{"redis://localhost:6379/0", "", boltdb.ErrUnsupported},
+		{"file://", "", boltdb.ErrUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := boltdb.Path(tt.url)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("Path() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpen_makesDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boltdb")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir error, %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "var", "lib", "store.db")
+	db, err := boltdb.Open(path, []byte("a"), []byte("b"))
+	if err != nil {
+		t.Fatalf("boltdb.Open error, %s", err)
+	}
+	defer db.Close()
+	err = db.View(func(tx *bolt.Tx) error {
+		for _, name := range []string{"a", "b"} {
+			if tx.Bucket([]byte(name)) == nil {
+				t.Errorf("bucket %s isn't created", name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+}
@@ -0,0 +1,84 @@
+package commonapiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// cachedDSN resolved connection string of the project.
+type cachedDSN struct {
+	DSN        string    `json:"dsn"`
+	ResolvedAt time.Time `json:"resolved_at"`
+}
+
+// dsnCache on-disk cache of the resolved connection strings by project id,
+// file contains passwords, so it's readable only by owner.
+type dsnCache struct {
+	mu    sync.Mutex
+	path  string
+	items map[string]cachedDSN
+}
+
+func newDSNCache(path string) *dsnCache {
+	return &dsnCache{path: path}
+}
+
+// load reads cache file once, absent or broken file means empty cache, must be called under lock.
+func (c *dsnCache) load() {
+	if c.items != nil {
+		return
+	}
+	c.items = make(map[string]cachedDSN)
+	bts, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return
+	}
+	_ = json.Unmarshal(bts, &c.items)
+}
+
+func (c *dsnCache) get(id string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	item, ok := c.items[id]
+	return item.DSN, ok
+}
+
+// put saves connection string, file is replaced atomically.
+func (c *dsnCache) put(id, dsn string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	if item, ok := c.items[id]; ok && item.DSN == dsn {
+		return nil
+	}
+	c.items[id] = cachedDSN{DSN: dsn, ResolvedAt: time.Now()}
+	bts, err := json.MarshalIndent(c.items, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return fmt.Errorf("make dir of the cache %s error, %v", c.path, err)
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("make temp file of the cache %s error, %v", c.path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(bts); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cache %s error, %v", c.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write cache %s error, %v", c.path, err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("replace cache %s error, %v", c.path, err)
+	}
+	return nil
+}
@@ -4,8 +4,11 @@ package commonapiclient
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
@@ -13,52 +16,149 @@ import (
 )
 
 const (
-	maxIdleConns int = 10
+	maxIdleConns     int           = 10
+	defaultRetries   int           = 3
+	defaultRetryWait time.Duration = time.Second
+	maxRetryWait     time.Duration = 30 * time.Second
 )
 
+// Config params of the commonapi client.
+type Config struct {
+	URL       string
+	Token     string
+	Timeout   time.Duration
+	Retries   int           // count of the retries of the failed request, 0 - default 3, < 0 - without retries
+	RetryWait time.Duration // wait before the first retry, doubled for every next retry
+	CAFile    string        // path to the PEM CA certificates of the commonapi, empty - system pool
+	Insecure  bool          // don't verify certificate of the commonapi
+	CacheFile string        // path to the on-disk cache of the resolved connection strings, empty - without cache
+}
+
 // API repository for commonapi
 type API struct {
-	url     string
-	token   string
-	handler *http.Client
+	url       string
+	token     string
+	retries   int
+	retryWait time.Duration
+	handler   *http.Client
+	cache     *dsnCache
+}
+
+// Result resolved connection string of the project.
+type Result struct {
+	ProjectID string
+	DSN       string
+	Cached    bool  // connection string from the on-disk cache, commonapi request failed
+	Err       error // error of the resolving, DSN is empty
+	CacheErr  error // error of the writing to the cache, DSN is valid
+}
+
+// statusError bad http response of the commonapi.
+type statusError struct {
+	uri  string
+	code int
 }
 
-// New builder for API
-func New(url, token string, timeout time.Duration) (*API, error) {
+func (e *statusError) Error() string {
+	return fmt.Sprintf("uri: %s, bad response, httpcode=%d", e.uri, e.code)
+}
+
+// New builder for API, commonapi availability isn't checked,
+// so service can start with connection strings from the cache.
+func New(cfg Config) (*API, error) {
+	tlsCfg, err := newTLSConfig(cfg.CAFile, cfg.Insecure)
+	if err != nil {
+		return nil, err
+	}
 	api := &API{
-		url:   url,
-		token: token,
+		url:       strings.TrimSuffix(cfg.URL, "/"),
+		token:     cfg.Token,
+		retries:   cfg.Retries,
+		retryWait: cfg.RetryWait,
 	}
-	// accept google invalid *.watcom.ru cert error
-	// nolint:gosec
-	cfg := &tls.Config{
-		InsecureSkipVerify: true,
+	if api.retries == 0 {
+		api.retries = defaultRetries
+	}
+	if api.retryWait <= 0 {
+		api.retryWait = defaultRetryWait
+	}
+	if cfg.CacheFile != "" {
+		api.cache = newDSNCache(cfg.CacheFile)
 	}
 	// set timeouts and border for opened connections
-	api.handler = &http.Client{Timeout: timeout, Transport: &http.Transport{
+	api.handler = &http.Client{Timeout: cfg.Timeout, Transport: &http.Transport{
 		MaxIdleConns:    maxIdleConns,
-		IdleConnTimeout: timeout,
-		TLSClientConfig: cfg,
+		IdleConnTimeout: cfg.Timeout,
+		TLSClientConfig: tlsCfg,
 	}}
-	return api, api.healthz(context.Background())
+	return api, nil
 }
 
-// GetConnections returns list of the connection strings to countmax dbs
-func (a *API) GetConnections(list string) ([]string, error) {
-	//http://elk-01.watcom.local:7007/v2/projects/10984
-	ids := strings.Split(list, ",")
-	if len(ids) == 0 {
-		return nil, fmt.Errorf("empty list not allowed")
+// newTLSConfig makes tls config with CA certificates from the file.
+func newTLSConfig(caFile string, insecure bool) (*tls.Config, error) {
+	// nolint:gosec
+	cfg := &tls.Config{InsecureSkipVerify: insecure}
+	if caFile == "" {
+		return cfg, nil
+	}
+	pem, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA file %s error, %v", caFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA file %s doesn't contain PEM certificates", caFile)
 	}
-	result := make([]string, 0, len(ids))
+	cfg.RootCAs = pool
+	return cfg, nil
+}
+
+// ParseIDs splits comma separated list of the project ids.
+func ParseIDs(list string) []string {
+	ids := make([]string, 0)
+	for _, id := range strings.Split(list, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// GetConnections resolves connection strings of the projects, errors are reported per project.
+func (a *API) GetConnections(ctx context.Context, ids []string) []Result {
+	//http://elk-01.watcom.local:7007/v2/projects/10984
+	result := make([]Result, 0, len(ids))
 	for _, id := range ids {
-		dsn, err := a.getConnectionByID(id)
-		if err != nil {
-			return nil, err
+		result = append(result, a.resolve(ctx, id))
+	}
+	return result
+}
+
+// GetConnection resolves connection string of the project.
+func (a *API) GetConnection(ctx context.Context, id string) (string, error) {
+	res := a.resolve(ctx, id)
+	return res.DSN, res.Err
+}
+
+// resolve requests project with retries, on failure returns connection string from the cache.
+func (a *API) resolve(ctx context.Context, id string) Result {
+	res := Result{ProjectID: id}
+	dsn, err := a.getConnectionByID(ctx, id)
+	if err == nil {
+		res.DSN = dsn
+		if a.cache != nil {
+			res.CacheErr = a.cache.put(id, dsn)
 		}
-		result = append(result, dsn)
+		return res
 	}
-	return result, nil
+	if a.cache != nil {
+		if cached, ok := a.cache.get(id); ok {
+			res.DSN, res.Cached = cached, true
+			return res
+		}
+	}
+	res.Err = err
+	return res
 }
 
 // Project - entity of project, business object
@@ -78,7 +178,28 @@ type Project struct {
 	Password    string `json:"password"`
 }
 
-func (p *Project) makeURL() string {
+// IsPostgres reports whether database of the project is postgres by the type name.
+func (p *Project) IsPostgres() bool {
+	t := strings.ToLower(p.TypeName)
+	return strings.Contains(t, "postgres") || strings.Contains(t, "pgsql")
+}
+
+// MakeURL returns connection string of the project database.
+func (p *Project) MakeURL() string {
+	if p.IsPostgres() {
+		//postgres://%s:%s@%s:%d/%s?sslmode=disable&pool_max_conns=2
+		query := url.Values{}
+		query.Add("sslmode", "disable")
+		query.Add("pool_max_conns", "2")
+		u := &url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(p.Login, p.Password),
+			Host:     fmt.Sprintf("%s:%d", p.IP, p.Port),
+			Path:     "/" + p.DBName,
+			RawQuery: query.Encode(),
+		}
+		return u.String()
+	}
 	//sqlserver://%s:%s@%s:%d?database=%s&connection_timeout=0&encrypt=disable
 	query := url.Values{}
 	query.Add("database", p.DBName)
@@ -94,30 +215,60 @@ func (p *Project) makeURL() string {
 	return u.String()
 }
 
-func (a *API) getConnectionByID(id string) (string, error) {
-	uri := fmt.Sprintf("%s/v2/projects/%s", a.url, id)
-	request, err := http.NewRequest("GET", uri, nil)
+// getConnectionByID requests project, retries network errors and server errors.
+func (a *API) getConnectionByID(ctx context.Context, id string) (string, error) {
+	wait := a.retryWait
+	for attempt := 0; ; attempt++ {
+		project, err := a.getProject(ctx, id)
+		if err == nil {
+			return project.MakeURL(), nil
+		}
+		if attempt >= a.retries || !retryable(err) {
+			return "", fmt.Errorf("project %s, attempts %d, %v", id, attempt+1, err)
+		}
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("project %s, attempts %d, %v", id, attempt+1, err)
+		case <-time.After(wait):
+		}
+		if wait *= 2; wait > maxRetryWait {
+			wait = maxRetryWait
+		}
+	}
+}
+
+// retryable reports whether request can succeed later.
+func retryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code >= http.StatusInternalServerError || se.code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+func (a *API) getProject(ctx context.Context, id string) (*Project, error) {
+	uri := fmt.Sprintf("%s/v2/projects/%s", a.url, url.PathEscape(id))
+	request, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	request.Header.Set("accept", "application/json")
 	request.Header.Set("Authorization", "Bearer "+a.token)
 	resp, err := a.handler.Do(request)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("uri: %s, bad response, httpcode=%d", uri, resp.StatusCode)
-		return "", err
+		return nil, &statusError{uri: uri, code: resp.StatusCode}
 	}
 	project := &Project{}
 	err = json.NewDecoder(resp.Body).Decode(project)
 	if err != nil {
 		err = fmt.Errorf("uri: %s, decode error %v, httpcode=%d", uri, err, resp.StatusCode)
-		return "", err
+		return nil, err
 	}
-	return project.makeURL(), nil
+	return project, nil
 }
 
 func (a *API) healthz(ctx context.Context) error {
@@ -132,7 +283,7 @@ func (a *API) healthz(ctx context.Context) error {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("uri: %s, bad response, httpcode=%d", uri, resp.StatusCode)
+		return &statusError{uri: uri, code: resp.StatusCode}
 	}
 	return nil
 }
@@ -11,6 +11,7 @@ import (
 
 	"git.countmax.ru/countmax/layoutconfig.api/domain"
 	"git.countmax.ru/countmax/layoutconfig.api/internal/commonapiclient"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/repodecor"
 	"git.countmax.ru/countmax/layoutconfig.api/repos"
 	"git.countmax.ru/countmax/pkg/logging"
 
@@ -23,6 +24,7 @@ const (
 	dtsAPI               string        = "api"
 	periodLostRetry      time.Duration = 120 * time.Second
 	timeoutRepoOperation time.Duration = 30 * time.Second
+	layoutsPageSize      int64         = 200
 )
 
 var (
@@ -39,30 +41,82 @@ type ExtServiceInterface interface {
 // Manager wraps map of the LayoutRepos by RWmutex.
 type Manager struct {
 	*sync.RWMutex
-	repos        map[string]domain.LayoutRepo
-	lostAndFound *[]lostDB
-	extSvc       []ExtServiceInterface
+	repos     map[string]domain.LayoutRepo
+	conflicts map[string]LayoutConflict // by layout id
+	sources   map[string]dbSource       // by Dest of the repo, for rebuild
+	dbs       map[string]*dbState       // by server, port and database name
+	dbsMu     sync.Mutex
+	newRepo   repoFactory
+	extSvc    []ExtServiceInterface
+	api       *commonapiclient.API
+	scope     string
+	timeout   time.Duration
+	drain     time.Duration // removed repos are closed after, for the requests in progress
 }
 
-// lostDB params for make LayoutRepo.
-type lostDB struct {
-	cs      string
-	timeout time.Duration
-	scope   string
+// dbSource params for make LayoutRepo.
+type dbSource struct {
+	cs        string
+	timeout   time.Duration
+	scope     string
+	projectID string
 }
 
-// New builder for the Manager.
-func New(ctx context.Context, cfg *viper.Viper) (*Manager, error) {
-	lf := make([]lostDB, 0)
-	m := &Manager{
-		RWMutex:      &sync.RWMutex{},
-		repos:        make(map[string]domain.LayoutRepo),
-		lostAndFound: &lf,
-	}
+// New builder for the Manager, every new repo is wrapped by decorators in the specified order.
+func New(ctx context.Context, cfg *viper.Viper, decorators ...repodecor.Decorator) (*Manager, error) {
+	m := newManager(decorate(repos.NewLayoutRepo, decorators...))
 	err := m.fillRepos(ctx, cfg)
+	if err == nil {
+		go m.monitor(ctx)
+	}
+	if period := cfg.GetDuration("countmax.resync"); err == nil && period > 0 {
+		go m.resyncer(ctx, period)
+	}
 	return m, err
 }
 
+// NewStatic makes Manager with the registered repos,
+// repos aren't monitored and resynced, as they have no source to reconnect by.
+func NewStatic(repos ...domain.LayoutRepo) (*Manager, error) {
+	m := newManager(nil)
+	for _, repo := range repos {
+		if err := m.RegisterRepo(repo); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// newManager makes empty Manager with specified factory of the repos.
+func newManager(newRepo repoFactory) *Manager {
+	return &Manager{
+		RWMutex:   &sync.RWMutex{},
+		repos:     make(map[string]domain.LayoutRepo),
+		conflicts: make(map[string]LayoutConflict),
+		sources:   make(map[string]dbSource),
+		dbs:       make(map[string]*dbState),
+		newRepo:   newRepo,
+		drain:     timeoutRepoOperation,
+	}
+}
+
+// decorate wraps repos made by factory.
+func decorate(newRepo repoFactory, decorators ...repodecor.Decorator) repoFactory {
+	if len(decorators) == 0 {
+		return newRepo
+	}
+	return func(ctx context.Context, scope, cs string, timeout time.Duration) (domain.LayoutRepo, error) {
+		repo, err := newRepo(ctx, scope, cs, timeout)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range decorators {
+			repo = d(repo)
+		}
+		return repo, nil
+	}
+}
+
 func (m *Manager) InitRepos() {
 	m.repos = make(map[string]domain.LayoutRepo)
 }
@@ -82,6 +136,10 @@ func (m *Manager) fillRepos(ctx context.Context, cfg *viper.Viper) error {
 	token := cfg.GetString("countmax.token")
 	scope := cfg.GetString("countmax.version")
 	listIDs := cfg.GetString("countmax.ids")
+	m.scope, m.timeout = scope, timeout
+	if timeout > 0 {
+		m.drain = timeout
+	}
 
 	switch path {
 	case dtsConfig:
@@ -90,7 +148,17 @@ func (m *Manager) fillRepos(ctx context.Context, cfg *viper.Viper) error {
 		if listIDs == "" {
 			return errors.Errorf("config countmax.ids key is empty")
 		}
-		return m.regRepoByAPI(ctx, scope, url, token, listIDs, timeout)
+		api := commonapiclient.Config{
+			URL:       url,
+			Token:     token,
+			Timeout:   timeout,
+			Retries:   cfg.GetInt("countmax.commonapi.retries"),
+			RetryWait: cfg.GetDuration("countmax.commonapi.retry_wait"),
+			CAFile:    cfg.GetString("countmax.commonapi.ca_file"),
+			Insecure:  cfg.GetBool("countmax.commonapi.insecure"),
+			CacheFile: cfg.GetString("countmax.commonapi.cache_file"),
+		}
+		return m.regRepoByAPI(ctx, scope, api, listIDs, timeout)
 	default:
 		return errors.Errorf("config %s is %s, unsupported, allow only %v", cfgKey, path, allowedDST)
 	}
@@ -129,62 +197,90 @@ func (m *Manager) GetExtServices() []ExtServiceInterface {
 
 // regRepoByConfig add single repo by connection string in the config.
 func (m *Manager) regRepoByConfig(ctx context.Context, scope, url string, timeout time.Duration) error {
-	cmr, err := repos.NewLayoutRepo(ctx, scope, url, timeout)
-	if err != nil {
-		return errors.Wrap(err, "repos.NewLayoutRepo failed")
-	}
-	err = m.RegisterRepo(cmr)
+	err := m.connect(ctx, dbSource{cs: url, timeout: timeout, scope: scope}, time.Now())
 	if err != nil {
-		return errors.Wrap(err, "registerRepo failed")
+		return errors.Wrap(err, "connect failed")
 	}
 	return nil
 }
 
-// regRepoByAPI adds many repos by get layouts from commonapi and process them connection strings.
-func (m *Manager) regRepoByAPI(ctx context.Context, scope, url, token, ids string, timeout time.Duration) error {
-	log := logging.FromContext(ctx)
-	api, err := commonapiclient.New(url, token, timeout)
+// regRepoByAPI adds many repos by get layouts from commonapi and process them connection strings,
+// projects which connection strings not resolved are lost and will be retried.
+func (m *Manager) regRepoByAPI(ctx context.Context, scope string, cfg commonapiclient.Config, ids string, timeout time.Duration) error {
+	api, err := commonapiclient.New(cfg)
 	if err != nil {
 		return errors.Wrap(err, "commonapiclient.New failed")
 	}
-	m.extSvc = append(m.extSvc, api)
-	css, err := api.GetConnections(ids)
-	if err != nil {
-		return errors.WithMessage(err, "config countmax.ids key is empty")
+	projects := commonapiclient.ParseIDs(ids)
+	if len(projects) == 0 {
+		return errors.Errorf("config countmax.ids key has no project ids")
 	}
-	for _, cs := range css {
-		cmr, err := repos.NewLayoutRepo(ctx, scope, cs, timeout)
-		if err != nil {
-			log.Errorf("repos.NewLayoutRepo for %s failed: %s, add to lostAndFound", getSrvPortDB(cs), err)
-			m.addLF(lostDB{cs: cs, timeout: timeout, scope: scope})
+	m.Lock()
+	m.api = api
+	m.extSvc = append(m.extSvc, api)
+	m.Unlock()
+	m.connectProjects(ctx, api.GetConnections(ctx, projects), scope, timeout)
+	return nil
+}
+
+// connectProjects connects to the databases of the resolved projects,
+// unresolved projects and unavailable databases are lost and will be retried.
+func (m *Manager) connectProjects(ctx context.Context, results []commonapiclient.Result, scope string, timeout time.Duration) {
+	log := logging.FromContext(ctx)
+	now := time.Now()
+	for _, res := range results {
+		src := dbSource{cs: res.DSN, timeout: timeout, scope: scope, projectID: res.ProjectID}
+		if res.Err != nil {
+			m.transit(ctx, src, "", StateLost, errors.WithMessage(res.Err, "resolve connection string failed"), now)
+			continue
 		}
-		err = m.RegisterRepo(cmr)
-		if err != nil {
-			log.Errorf("registerRepo failed, %s", err)
+		if res.Cached {
+			log.Warnf("commonapi unavailable, connection of the project %s is taken from the cache", res.ProjectID)
+		}
+		if res.CacheErr != nil {
+			log.Warnf("cache connection of the project %s failed, %s", res.ProjectID, res.CacheErr)
+		}
+		if err := m.connect(ctx, src, now); err != nil {
+			log.Errorf("connect to %s failed, it is lost and will be retried: %s", getSrvPortDB(src.cs), err)
 		}
 	}
-	go m.lostRetrier(ctx, periodLostRetry)
-	return nil
 }
 
 // RegisterRepo requests layouts from repo and fill repos maps in the Manager's hidden field.
 func (m *Manager) RegisterRepo(repo domain.LayoutRepo) error {
-	ctx, cancel := context.WithTimeout(context.Background(), timeoutRepoOperation)
-	defer cancel()
-	layouts, _, err := repo.FindLayouts(ctx, "ru", "*", 0, 199)
+	layouts, err := findLayouts(context.Background(), repo)
 	if err != nil {
-		return errors.Wrap(err, "repo.FindLayouts failed")
+		return err
 	}
 	m.Lock()
 	defer m.Unlock()
-	for _, l := range layouts {
-		m.repos[l.ID] = repo
-	}
+	m.assign(repo, layouts)
 	m.repos["*"] = repo
-	m.extSvc = append(m.extSvc, repo)
+	m.addExtSvc(repo)
 	return nil
 }
 
+// registerSource registers repo and remembers params it was made by.
+func (m *Manager) registerSource(repo domain.LayoutRepo, src dbSource) error {
+	if err := m.RegisterRepo(repo); err != nil {
+		return err
+	}
+	m.Lock()
+	defer m.Unlock()
+	m.sources[repo.Dest()] = src
+	return nil
+}
+
+// addExtSvc adds service for health checking once by Dest, must be called under lock.
+func (m *Manager) addExtSvc(svc ExtServiceInterface) {
+	for _, es := range m.extSvc {
+		if es.Dest() == svc.Dest() {
+			return
+		}
+	}
+	m.extSvc = append(m.extSvc, svc)
+}
+
 // getSrvPortDB returns uniq string with server.port.dbname parts.
 func getSrvPortDB(connStr string) string {
 	// "server=some.domain.ip;user id=root;password=master;port=1433;database=CM_Net523"
@@ -0,0 +1,56 @@
+package connmanager
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+)
+
+// RepoError failed query to the repo.
+type RepoError struct {
+	Dest  string `json:"dest"`
+	Error string `json:"error"`
+}
+
+// FanOutQuery query to the single repo, result is merged by the caller.
+type FanOutQuery func(ctx context.Context, repo domain.LayoutRepo) (interface{}, error)
+
+// FanOut runs query for every registered repo in parallel, each query is limited by timeout,
+// returns results of the succeeded repos and errors of the failed ones, both ordered by Dest.
+func (m *Manager) FanOut(ctx context.Context, timeout time.Duration, query FanOutQuery) ([]interface{}, []RepoError) {
+	repos := m.Repos()
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Dest() < repos[j].Dest() })
+	results := make([]interface{}, len(repos))
+	errs := make([]error, len(repos))
+	var wg sync.WaitGroup
+	for i := range repos {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			qctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			results[i], errs[i] = query(qctx, repos[i])
+		}(i)
+	}
+	wg.Wait()
+	res := make([]interface{}, 0, len(repos))
+	failed := make([]RepoError, 0)
+	for i, repo := range repos {
+		if errs[i] != nil {
+			failed = append(failed, RepoError{Dest: repo.Dest(), Error: errs[i].Error()})
+			continue
+		}
+		res = append(res, results[i])
+	}
+	return res, failed
+}
+
+// Routed reports whether layout routed to the repo,
+// layouts claimed by several repos must be taken only from the owner.
+func (m *Manager) Routed(layoutID string, repo domain.LayoutRepo) bool {
+	owner, ok := m.RepoByID(layoutID)
+	return !ok || owner.Dest() == repo.Dest()
+}
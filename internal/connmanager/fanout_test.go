@@ -0,0 +1,57 @@
+package connmanager
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+)
+
+// slowRepo answers after delay or when context done.
+type slowRepo struct {
+	*fakeRepo
+	delay time.Duration
+}
+
+func (r *slowRepo) FindLayouts(ctx context.Context, loc, dt string, offset, limit int64) (domain.Layouts, int64, error) {
+	select {
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	case <-time.After(r.delay):
+	}
+	return r.fakeRepo.FindLayouts(ctx, loc, dt, offset, limit)
+}
+
+func TestManager_FanOut(t *testing.T) {
+	m := newTestManager()
+	failed := newFakeRepo("db3", 1, "c")
+	slow := &slowRepo{fakeRepo: newFakeRepo("db4", 1, "d")}
+	for _, r := range []domain.LayoutRepo{newFakeRepo("db2", 2, "b"), newFakeRepo("db1", 1, "a"), failed, slow} {
+		if err := m.RegisterRepo(r); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	failed.set(nil, errors.New("connection refused"))
+	slow.delay = time.Second
+
+	res, errs := m.FanOut(context.Background(), 50*time.Millisecond,
+		func(ctx context.Context, repo domain.LayoutRepo) (interface{}, error) {
+			layouts, _, err := repo.FindLayouts(ctx, "ru", "*", 0, 10)
+			return layouts, err
+		})
+	got := make([]string, 0)
+	for _, r := range res {
+		for _, l := range r.(domain.Layouts) {
+			got = append(got, l.ID)
+		}
+	}
+	if want := []string{"a0", "b0", "b1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FanOut() results = %v, want %v", got, want)
+	}
+	if len(errs) != 2 || errs[0].Dest != "db3" || errs[1].Dest != "db4" {
+		t.Errorf("FanOut() errors = %v, want db3 and db4", errs)
+	}
+}
@@ -2,50 +2,282 @@ package connmanager
 
 import (
 	"context"
+	"sort"
 	"time"
 
-	"git.countmax.ru/countmax/layoutconfig.api/repos"
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
 	"git.countmax.ru/countmax/pkg/logging"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// State of the database connection.
+type State string
+
+const (
+	// StateConnecting connection to the database in progress.
+	StateConnecting State = "connecting"
+	// StateHealthy repo of the database registered and responds.
+	StateHealthy State = "healthy"
+	// StateDegraded repo of the database registered, but health check failed.
+	StateDegraded State = "degraded"
+	// StateLost connection to the database failed, it is retried with backoff.
+	StateLost State = "lost"
 )
 
-func (m *Manager) addLF(ldb lostDB) {
-	(*m.lostAndFound) = append((*m.lostAndFound), ldb)
+const (
+	backoffMin    time.Duration = 5 * time.Second
+	backoffMax    time.Duration = 5 * periodLostRetry
+	periodMonitor time.Duration = 5 * time.Second
+	periodHealth  time.Duration = 30 * time.Second
+)
+
+var (
+	allStates = []State{StateConnecting, StateHealthy, StateDegraded, StateLost}
+
+	dbStateGauge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "countmax_db_state",
+			Help: "State of the countmax database connection, 1 for the current state",
+		},
+		[]string{"db", "state"},
+	)
+
+	dbFailuresGauge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "countmax_db_failed_attempts",
+			Help: "Consecutive failed connections or health checks of the countmax database",
+		},
+		[]string{"db"},
+	)
+)
+
+// repoFactory makes repo by connection string, replaced in tests.
+type repoFactory func(ctx context.Context, scope, cs string, timeout time.Duration) (domain.LayoutRepo, error)
+
+// DBStatus observable state of the database.
+type DBStatus struct {
+	SrvPortDB string     `json:"srv_port_db"`
+	ProjectID string     `json:"project_id,omitempty"`
+	State     State      `json:"state"`
+	Since     time.Time  `json:"since"`
+	Failures  int        `json:"failures"`
+	LastError string     `json:"last_error,omitempty"`
+	NextRetry *time.Time `json:"next_retry,omitempty"`
 }
 
-func (m *Manager) delLF(i int) {
-	copy((*m.lostAndFound)[i:], (*m.lostAndFound)[i+1:])
+// dbState state of the database with params of the connection.
+type dbState struct {
+	src    dbSource
+	dest   string
+	status DBStatus
 }
 
-func (m *Manager) retryFill(ctx context.Context) {
-	log := logging.FromContext(ctx)
-	for i, lf := range *m.lostAndFound {
-		cmr, err := repos.NewLayoutRepo(ctx, lf.scope, lf.cs, lf.timeout)
-		if err != nil {
-			log.Errorf("repos.NewLayoutRepo for %s failed: %s", getSrvPortDB(lf.cs), err)
+// Statuses returns states of the all known databases ordered by server, port and database name.
+func (m *Manager) Statuses() []DBStatus {
+	m.dbsMu.Lock()
+	defer m.dbsMu.Unlock()
+	res := make([]DBStatus, 0, len(m.dbs))
+	for _, st := range m.dbs {
+		res = append(res, st.status)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].SrvPortDB < res[j].SrvPortDB })
+	return res
+}
+
+// connect makes and registers repo of the database, on failure database becomes lost.
+func (m *Manager) connect(ctx context.Context, src dbSource, now time.Time) error {
+	m.transit(ctx, src, "", StateConnecting, nil, now)
+	repo, err := m.newRepo(ctx, src.scope, src.cs, src.timeout)
+	if err == nil {
+		if err = m.registerSource(repo, src); err != nil {
+			closeRepo(repo)
+		}
+	}
+	if err != nil {
+		m.transit(ctx, src, "", StateLost, err, now)
+		return err
+	}
+	m.transit(ctx, src, repo.Dest(), StateHealthy, nil, now)
+	return nil
+}
+
+// retryLost connects to the lost databases which backoff expired, force ignores backoff.
+func (m *Manager) retryLost(ctx context.Context, now time.Time, force bool) {
+	due := make([]dbSource, 0)
+	m.dbsMu.Lock()
+	for _, st := range m.dbs {
+		if st.status.State != StateLost {
+			continue
 		}
-		err = m.RegisterRepo(cmr)
+		if force || st.status.NextRetry == nil || !now.Before(*st.status.NextRetry) {
+			due = append(due, st.src)
+		}
+	}
+	m.dbsMu.Unlock()
+	for _, src := range due {
+		if src.cs == "" {
+			var ok bool
+			if src, ok = m.resolveLost(ctx, src, now); !ok {
+				continue
+			}
+		}
+		_ = m.connect(ctx, src, now)
+	}
+}
+
+// resolveLost requests connection string of the project which wasn't resolved,
+// resolved project is monitored by its database.
+func (m *Manager) resolveLost(ctx context.Context, src dbSource, now time.Time) (dbSource, bool) {
+	m.RLock()
+	api := m.api
+	m.RUnlock()
+	if api == nil {
+		return src, false
+	}
+	cs, err := api.GetConnection(ctx, src.projectID)
+	if err != nil {
+		m.transit(ctx, src, "", StateLost, errors.WithMessage(err, "resolve connection string failed"), now)
+		return src, false
+	}
+	m.forget(src)
+	src.cs = cs
+	return src, true
+}
+
+// dbKey returns key of the database in the monitoring,
+// project which connection string isn't resolved is monitored by its id.
+func dbKey(src dbSource) string {
+	if src.cs == "" {
+		return "project:" + src.projectID
+	}
+	return getSrvPortDB(src.cs)
+}
+
+// checkHealth checks registered databases, failed ones become degraded until they respond again.
+func (m *Manager) checkHealth(ctx context.Context, now time.Time) {
+	type target struct {
+		src  dbSource
+		dest string
+	}
+	targets := make([]target, 0)
+	m.dbsMu.Lock()
+	for _, st := range m.dbs {
+		if st.status.State == StateHealthy || st.status.State == StateDegraded {
+			targets = append(targets, target{src: st.src, dest: st.dest})
+		}
+	}
+	m.dbsMu.Unlock()
+	m.RLock()
+	byDest := m.reposByDest()
+	m.RUnlock()
+	for _, t := range targets {
+		repo, ok := byDest[t.dest]
+		if !ok {
+			continue
+		}
+		hctx, cancel := context.WithTimeout(ctx, timeoutRepoOperation)
+		err := repo.Health(hctx)
+		cancel()
 		if err != nil {
-			log.Errorf("lostAndFound retryFill, registerRepo failed: %s", err)
+			m.transit(ctx, t.src, t.dest, StateDegraded, err, now)
 			continue
 		}
-		m.delLF(i)
-		m.retryFill(ctx)
+		m.transit(ctx, t.src, t.dest, StateHealthy, nil, now)
+	}
+}
+
+// transit moves database to the state, counts failures and plans retry of the lost database.
+func (m *Manager) transit(ctx context.Context, src dbSource, dest string, state State, err error, now time.Time) {
+	key := dbKey(src)
+	m.dbsMu.Lock()
+	defer m.dbsMu.Unlock()
+	st, ok := m.dbs[key]
+	if !ok {
+		st = &dbState{status: DBStatus{SrvPortDB: key, Since: now}}
+		m.dbs[key] = st
+	}
+	st.src = src
+	st.status.ProjectID = src.projectID
+	if dest != "" {
+		st.dest = dest
+	}
+	prev := st.status.State
+	if prev != state {
+		st.status.Since = now
+		if prev != "" && !(prev == StateConnecting || state == StateConnecting) {
+			logging.FromContext(ctx).Infof("database %s %s -> %s", key, prev, state)
+		}
+	}
+	st.status.State = state
+	st.status.NextRetry = nil
+	switch state {
+	case StateHealthy:
+		st.status.Failures, st.status.LastError = 0, ""
+	case StateDegraded, StateLost:
+		st.status.Failures++
+		st.status.LastError = err.Error()
+	}
+	if state == StateLost {
+		next := now.Add(backoff(st.status.Failures))
+		st.status.NextRetry = &next
+		logging.FromContext(ctx).Errorf("database %s lost, failure %d, next retry at %s: %s",
+			key, st.status.Failures, next.Format(time.RFC3339), err)
+	}
+	for _, s := range allStates {
+		v := 0.0
+		if s == state {
+			v = 1
+		}
+		dbStateGauge.WithLabelValues(key, string(s)).Set(v)
+	}
+	dbFailuresGauge.WithLabelValues(key).Set(float64(st.status.Failures))
+}
+
+// forget removes database from the monitoring.
+func (m *Manager) forget(src dbSource) {
+	key := dbKey(src)
+	m.dbsMu.Lock()
+	defer m.dbsMu.Unlock()
+	delete(m.dbs, key)
+	for _, s := range allStates {
+		dbStateGauge.DeleteLabelValues(key, string(s))
+	}
+	dbFailuresGauge.DeleteLabelValues(key)
+}
+
+// backoff returns exponential delay before the next retry after failures.
+func backoff(failures int) time.Duration {
+	d := backoffMin
+	for i := 1; i < failures && d < backoffMax; i++ {
+		d *= 2
+	}
+	if d > backoffMax {
+		d = backoffMax
 	}
+	return d
 }
 
-func (m *Manager) lostRetrier(ctx context.Context, period time.Duration) {
+// monitor retries lost databases and checks health of the registered ones.
+func (m *Manager) monitor(ctx context.Context) {
 	log := logging.FromContext(ctx)
-	log.Debugf("start lostRetrier with period %v", period)
-	defer log.Debug("stop lostRetrier")
-	t := time.NewTicker(period)
+	log.Debugf("start monitor with period %v", periodMonitor)
+	defer log.Debug("stop monitor")
+	t := time.NewTicker(periodMonitor)
 	defer t.Stop()
+	var lastHealth time.Time
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-t.C:
-			log.Debug("time to retryFill")
-			m.retryFill(ctx)
+		case now := <-t.C:
+			m.retryLost(ctx, now, false)
+			if now.Sub(lastHealth) >= periodHealth {
+				m.checkHealth(ctx, now)
+				lastHealth = now
+			}
 		}
 	}
 }
@@ -0,0 +1,188 @@
+package connmanager
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/repodecor"
+	"git.countmax.ru/countmax/pkg/logging"
+
+	"github.com/pkg/errors"
+)
+
+// LayoutConflict layout claimed by several repos, requests are routed to the owner.
+type LayoutConflict struct {
+	LayoutID  string   `json:"layout_id"`
+	Owner     string   `json:"owner"`
+	Claimants []string `json:"claimants"`
+}
+
+// Conflicts returns layouts claimed by several repos ordered by layout id.
+func (m *Manager) Conflicts() []LayoutConflict {
+	m.RLock()
+	defer m.RUnlock()
+	res := make([]LayoutConflict, 0, len(m.conflicts))
+	for _, c := range m.conflicts {
+		res = append(res, c)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].LayoutID < res[j].LayoutID })
+	return res
+}
+
+// Resync rereads layouts of the all registered repos and rebuilds routing of the layouts,
+// layouts of the unavailable repos are kept as is, layout stays with its current owner
+// if it still claims the layout, else goes to the first claimant by Dest;
+// layouts are read without lock, so results of the repos removed or rebuilt meanwhile are dropped.
+func (m *Manager) Resync(ctx context.Context) error {
+	log := logging.FromContext(ctx)
+	claims := make(map[string][]string)
+	byDest := make(map[string]domain.LayoutRepo)
+	failed := make([]string, 0)
+	for _, repo := range m.Repos() {
+		layouts, err := findLayouts(ctx, repo)
+		if err != nil {
+			log.Errorf("resync %s failed, %s", repo.Dest(), err)
+			failed = append(failed, repo.Dest())
+			continue
+		}
+		byDest[repo.Dest()] = repo
+		for _, l := range layouts {
+			claims[l.ID] = append(claims[l.ID], repo.Dest())
+		}
+	}
+	m.Lock()
+	defer m.Unlock()
+	// repos removed or rebuilt while layouts were read are not resynced
+	current := m.reposByDest()
+	for dest, repo := range byDest {
+		if cur, ok := current[dest]; !ok || cur != repo {
+			log.Debugf("resync of %s skipped, repo removed or replaced", dest)
+			delete(byDest, dest)
+		}
+	}
+	for lid, dests := range claims {
+		valid := dests[:0]
+		for _, dest := range dests {
+			if _, ok := byDest[dest]; ok {
+				valid = append(valid, dest)
+			}
+		}
+		if len(valid) == 0 {
+			delete(claims, lid)
+			continue
+		}
+		claims[lid] = valid
+	}
+	routes := make(map[string]domain.LayoutRepo, len(m.repos))
+	for lid, repo := range m.repos {
+		if _, ok := byDest[repo.Dest()]; !ok || lid == "*" {
+			routes[lid] = repo
+		}
+	}
+	conflicts := make(map[string]LayoutConflict)
+	var added, removed int
+	for lid, dests := range claims {
+		sort.Strings(dests)
+		owner := dests[0]
+		if cur, ok := m.repos[lid]; ok && contains(dests, cur.Dest()) {
+			owner = cur.Dest()
+		}
+		if kept, ok := routes[lid]; ok {
+			// owner unavailable now, keep routing to it
+			owner = kept.Dest()
+			dests = append(dests, owner)
+		} else {
+			routes[lid] = byDest[owner]
+		}
+		if _, ok := m.repos[lid]; !ok {
+			added++
+		}
+		if len(dests) > 1 {
+			conflicts[lid] = LayoutConflict{LayoutID: lid, Owner: owner, Claimants: dests}
+		}
+	}
+	for lid := range m.repos {
+		if _, ok := routes[lid]; !ok {
+			removed++
+		}
+	}
+	for lid, c := range conflicts {
+		if _, ok := m.conflicts[lid]; !ok {
+			log.Warnf("layout_id=%s claimed by %v, routed to %s", lid, c.Claimants, c.Owner)
+		}
+	}
+	m.repos, m.conflicts = routes, conflicts
+	log.Infof("resync layouts: added %d, removed %d, conflicts %d", added, removed, len(conflicts))
+	if len(failed) > 0 {
+		return errors.Errorf("resync failed for %v", failed)
+	}
+	return nil
+}
+
+// assign routes layouts to the repo, layouts already routed to another repo
+// are kept there and registered as conflicts, must be called under lock.
+func (m *Manager) assign(repo domain.LayoutRepo, layouts domain.Layouts) {
+	dest := repo.Dest()
+	for _, l := range layouts {
+		owner, ok := m.repos[l.ID]
+		if !ok || owner.Dest() == dest {
+			m.repos[l.ID] = repo
+			continue
+		}
+		c, ok := m.conflicts[l.ID]
+		if !ok {
+			c = LayoutConflict{LayoutID: l.ID, Owner: owner.Dest(), Claimants: []string{owner.Dest()}}
+		}
+		if !contains(c.Claimants, dest) {
+			c.Claimants = append(c.Claimants, dest)
+		}
+		m.conflicts[l.ID] = c
+	}
+}
+
+func (m *Manager) resyncer(ctx context.Context, period time.Duration) {
+	log := logging.FromContext(ctx)
+	log.Debugf("start resyncer with period %v", period)
+	defer log.Debug("stop resyncer")
+	t := time.NewTicker(period)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := m.Resync(ctx); err != nil {
+				log.Errorf("resync error, %s", err)
+			}
+		}
+	}
+}
+
+// findLayouts requests all layouts served by repo page by page.
+func findLayouts(ctx context.Context, repo domain.LayoutRepo) (domain.Layouts, error) {
+	// layouts are read from the database, not from the cache of the decorated repo
+	ctx, cancel := context.WithTimeout(repodecor.WithFresh(ctx), timeoutRepoOperation)
+	defer cancel()
+	result := make(domain.Layouts, 0, layoutsPageSize)
+	for offset := int64(0); ; offset += layoutsPageSize {
+		layouts, count, err := repo.FindLayouts(ctx, "ru", "*", offset, layoutsPageSize)
+		if err != nil {
+			return nil, errors.Wrapf(err, "repo.FindLayouts with offset %d failed", offset)
+		}
+		result = append(result, layouts...)
+		if int64(len(layouts)) < layoutsPageSize || int64(len(result)) >= count {
+			return result, nil
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
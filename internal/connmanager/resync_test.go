@@ -0,0 +1,227 @@
+package connmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+)
+
+// fakeRepo serves layouts by pages, other methods are not implemented.
+type fakeRepo struct {
+	domain.DefImplLayoutRepo
+	domain.DefImplACLRepoInterface
+	mu      sync.Mutex
+	dest    string
+	layouts []string
+	err     error
+	health  error
+	closed  int
+}
+
+func newFakeRepo(dest string, count int, prefix string) *fakeRepo {
+	r := &fakeRepo{dest: dest}
+	for i := 0; i < count; i++ {
+		r.layouts = append(r.layouts, fmt.Sprintf("%s%d", prefix, i))
+	}
+	return r
+}
+
+func (r *fakeRepo) FindLayouts(ctx context.Context, loc, dt string, offset, limit int64) (domain.Layouts, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, 0, r.err
+	}
+	res := make(domain.Layouts, 0, limit)
+	for i := offset; i < int64(len(r.layouts)) && i < offset+limit; i++ {
+		res = append(res, domain.Layout{ID: r.layouts[i]})
+	}
+	return res, int64(len(r.layouts)), nil
+}
+
+func (r *fakeRepo) set(layouts []string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.layouts, r.err = layouts, err
+}
+
+func (r *fakeRepo) setHealth(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.health = err
+}
+
+func (r *fakeRepo) Health(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.health
+}
+
+func (r *fakeRepo) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed++
+	return nil
+}
+
+func (r *fakeRepo) Dest() string  { return r.dest }
+func (r *fakeRepo) Scope() string { return "fake" }
+
+func newTestManager() *Manager {
+	return newManager(nil)
+}
+
+func routedTo(m *Manager, layoutID string) string {
+	repo, ok := m.RepoByID(layoutID)
+	if !ok {
+		return ""
+	}
+	return repo.Dest()
+}
+
+func TestManager_RegisterRepoPaginated(t *testing.T) {
+	m := newTestManager()
+	r := newFakeRepo("db1", 2*int(layoutsPageSize)+5, "l")
+	if err := m.RegisterRepo(r); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	for _, id := range []string{"l0", "l199", "l200", "l404"} {
+		if got := routedTo(m, id); got != "db1" {
+			t.Errorf("RepoByID(%s) = %q, want db1", id, got)
+		}
+	}
+	if got := len(m.ReposInfo()[0].Layouts); got != len(r.layouts) {
+		t.Errorf("registered %d layouts, want %d", got, len(r.layouts))
+	}
+}
+
+func TestManager_Resync(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager()
+	r1 := newFakeRepo("db1", 3, "a")
+	r2 := newFakeRepo("db2", 2, "b")
+	for _, r := range []*fakeRepo{r1, r2} {
+		if err := m.RegisterRepo(r); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	// a2 removed, a3 added to db1; db2 claims a0 too
+	r1.set([]string{"a0", "a1", "a3"}, nil)
+	r2.set([]string{"b0", "b1", "a0"}, nil)
+	if err := m.Resync(ctx); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	want := map[string]string{"a0": "db1", "a1": "db1", "a2": "", "a3": "db1", "b0": "db2", "b1": "db2"}
+	for id, dest := range want {
+		if got := routedTo(m, id); got != dest {
+			t.Errorf("RepoByID(%s) = %q, want %q", id, got, dest)
+		}
+	}
+	wantConflicts := []LayoutConflict{{LayoutID: "a0", Owner: "db1", Claimants: []string{"db1", "db2"}}}
+	if got := m.Conflicts(); !reflect.DeepEqual(got, wantConflicts) {
+		t.Errorf("Conflicts() = %v, want %v", got, wantConflicts)
+	}
+
+	// unavailable repo keeps its layouts
+	r1.set(nil, fmt.Errorf("connection refused"))
+	if err := m.Resync(ctx); err == nil {
+		t.Error("Resync() error = nil, want error for unavailable repo")
+	}
+	if got := routedTo(m, "a3"); got != "db1" {
+		t.Errorf("RepoByID(a3) = %q after failed resync, want db1", got)
+	}
+	if got := routedTo(m, "a0"); got != "db1" {
+		t.Errorf("RepoByID(a0) = %q after failed resync, want db1", got)
+	}
+
+	// owner removed, conflicted layout goes to the other claimant
+	if _, err := m.RemoveRepo(RepoID("db1")); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if got := routedTo(m, "a0"); got != "db2" {
+		t.Errorf("RepoByID(a0) = %q after remove, want db2", got)
+	}
+	if got := m.Conflicts(); len(got) != 0 {
+		t.Errorf("Conflicts() = %v after remove, want empty", got)
+	}
+}
+
+func TestManager_RemoveRepoDrained(t *testing.T) {
+	m := newTestManager()
+	m.drain = 50 * time.Millisecond
+	r := newFakeRepo("db1", 2, "a")
+	if err := m.RegisterRepo(r); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if _, err := m.RemoveRepo(RepoID("db1")); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	closed := func() int {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return r.closed
+	}
+	if closed() != 0 {
+		t.Fatal("removed repo closed before the drain period")
+	}
+	deadline := time.Now().Add(time.Second)
+	for closed() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if closed() != 1 {
+		t.Errorf("removed repo closed %d times after the drain period, want 1", closed())
+	}
+	if _, err := m.RemoveRepo(RepoID("db1")); !errors.Is(err, ErrRepoNotFound) {
+		t.Errorf("RemoveRepo() of the removed repo error = %v, want %v", err, ErrRepoNotFound)
+	}
+}
+
+// blockingRepo blocks reading of the layouts till released.
+type blockingRepo struct {
+	*fakeRepo
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingRepo) FindLayouts(ctx context.Context, loc, dt string, offset, limit int64) (domain.Layouts, int64, error) {
+	select {
+	case r.started <- struct{}{}:
+	default:
+	}
+	<-r.release
+	return r.fakeRepo.FindLayouts(ctx, loc, dt, offset, limit)
+}
+
+func TestManager_ResyncRemovedMeanwhile(t *testing.T) {
+	m := newTestManager()
+	r1 := &blockingRepo{fakeRepo: newFakeRepo("db1", 2, "a"), started: make(chan struct{}), release: make(chan struct{})}
+	r2 := newFakeRepo("db2", 2, "b")
+	close(r1.release)
+	for _, r := range []domain.LayoutRepo{r1, r2} {
+		if err := m.RegisterRepo(r); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	r1.release = make(chan struct{})
+	done := make(chan error)
+	go func() { done <- m.Resync(context.Background()) }()
+	<-r1.started
+	if _, err := m.RemoveRepo(RepoID("db1")); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	close(r1.release)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	for id, dest := range map[string]string{"a0": "", "a1": "", "b0": "db2"} {
+		if got := routedTo(m, id); got != dest {
+			t.Errorf("RepoByID(%s) = %q after resync of the removed repo, want %q", id, got, dest)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package connmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/commonapiclient"
+
+	"github.com/pkg/errors"
+)
+
+const repoIDSize int = 6
+
+var (
+	// ErrRepoNotFound repo with specified id not registered.
+	ErrRepoNotFound = errors.New("repo not found")
+	// ErrRepoExists repo for the database already registered.
+	ErrRepoExists = errors.New("repo already registered")
+	// ErrNoCommonAPI repos can't be added by project id without commonapi source.
+	ErrNoCommonAPI = errors.New("commonapi not configured, countmax.source must be api")
+)
+
+// RepoInfo registered repo with layouts it serves.
+type RepoInfo struct {
+	ID        string   `json:"repo_id"`
+	Dest      string   `json:"dest"`
+	Scope     string   `json:"scope"`
+	ProjectID string   `json:"project_id,omitempty"`
+	IsDefault bool     `json:"is_default"`
+	Layouts   []string `json:"layouts"`
+}
+
+// RepoID returns stable id of the repo by its Dest.
+func RepoID(dest string) string {
+	sum := sha256.Sum256([]byte(dest))
+	return hex.EncodeToString(sum[:repoIDSize])
+}
+
+// ReposInfo returns all registered repos ordered by Dest.
+func (m *Manager) ReposInfo() []RepoInfo {
+	m.RLock()
+	defer m.RUnlock()
+	return m.reposInfo()
+}
+
+// RepoInfo returns registered repo by id.
+func (m *Manager) RepoInfo(id string) (RepoInfo, error) {
+	m.RLock()
+	defer m.RUnlock()
+	for _, info := range m.reposInfo() {
+		if info.ID == id {
+			return info, nil
+		}
+	}
+	return RepoInfo{}, ErrRepoNotFound
+}
+
+// AddRepoByProject makes repo by connection string of the project from commonapi and registers it.
+func (m *Manager) AddRepoByProject(ctx context.Context, projectID string) (RepoInfo, error) {
+	m.RLock()
+	api := m.api
+	m.RUnlock()
+	if api == nil {
+		return RepoInfo{}, ErrNoCommonAPI
+	}
+	if projectID == "" || strings.Contains(projectID, ",") {
+		return RepoInfo{}, errors.Errorf("single project id expected, got %q", projectID)
+	}
+	cs, err := api.GetConnection(ctx, projectID)
+	if err != nil {
+		return RepoInfo{}, errors.WithMessagef(err, "get connection of project %s failed", projectID)
+	}
+	return m.addRepo(ctx, dbSource{cs: cs, timeout: m.timeout, scope: m.scope, projectID: projectID})
+}
+
+// SyncProjects brings databases to the list of project ids from commonapi:
+// repos of the projects missing in the list are removed, new projects are connected
+// or become lost to be retried, returns ids of the added and removed projects;
+// fails only before any change, so nothing is applied on error.
+func (m *Manager) SyncProjects(ctx context.Context, ids string) (added, removed []string, err error) {
+	m.RLock()
+	api := m.api
+	m.RUnlock()
+	if api == nil {
+		return nil, nil, ErrNoCommonAPI
+	}
+	want := make(map[string]struct{})
+	for _, id := range commonapiclient.ParseIDs(ids) {
+		want[id] = struct{}{}
+	}
+	if len(want) == 0 {
+		return nil, nil, errors.New("no project ids")
+	}
+	known := make(map[string]struct{})
+	lost := make([]dbSource, 0)
+	m.dbsMu.Lock()
+	for _, st := range m.dbs {
+		if st.src.projectID == "" {
+			continue
+		}
+		known[st.src.projectID] = struct{}{}
+		if _, ok := want[st.src.projectID]; !ok && st.status.State == StateLost {
+			lost = append(lost, st.src)
+		}
+	}
+	m.dbsMu.Unlock()
+	for _, src := range lost {
+		m.forget(src)
+	}
+	for _, info := range m.ReposInfo() {
+		if info.ProjectID == "" {
+			continue
+		}
+		known[info.ProjectID] = struct{}{}
+		if _, ok := want[info.ProjectID]; ok {
+			continue
+		}
+		// repo removed meanwhile is already gone
+		_, _ = m.RemoveRepo(info.ID)
+	}
+	for id := range known {
+		if _, ok := want[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	for id := range want {
+		if _, ok := known[id]; !ok {
+			added = append(added, id)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	m.connectProjects(ctx, api.GetConnections(ctx, added), m.scope, m.timeout)
+	return added, removed, nil
+}
+
+// AddRepoByDSN makes repo by connection string and registers it.
+func (m *Manager) AddRepoByDSN(ctx context.Context, dsn string) (RepoInfo, error) {
+	return m.addRepo(ctx, dbSource{cs: dsn, timeout: m.timeout, scope: m.scope})
+}
+
+// RemoveRepo unregisters repo and all layouts it serves, returns removed repo.
+func (m *Manager) RemoveRepo(id string) (RepoInfo, error) {
+	m.Lock()
+	defer m.Unlock()
+	repo, info, err := m.repoByID(id)
+	if err != nil {
+		return RepoInfo{}, err
+	}
+	m.unregister(repo)
+	m.forget(m.sources[info.Dest])
+	delete(m.sources, info.Dest)
+	m.closeLater(repo)
+	return info, nil
+}
+
+// RebuildRepo remakes repo by params it was made by, reconnects to the database
+// and rereads its layouts, returns rebuilt repo.
+func (m *Manager) RebuildRepo(ctx context.Context, id string) (RepoInfo, error) {
+	m.RLock()
+	old, info, err := m.repoByID(id)
+	src, ok := m.sources[info.Dest]
+	m.RUnlock()
+	if err != nil {
+		return RepoInfo{}, err
+	}
+	if !ok {
+		return RepoInfo{}, errors.Errorf("params of the repo %s unknown", id)
+	}
+	repo, layouts, err := m.makeRepo(ctx, src)
+	if err != nil {
+		return RepoInfo{}, err
+	}
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.reposByDest()[info.Dest]; !ok {
+		closeRepo(repo)
+		return RepoInfo{}, ErrRepoNotFound
+	}
+	isDefault := m.repos["*"] == old
+	m.unregister(old)
+	m.register(repo, layouts, src, isDefault)
+	m.closeLater(old)
+	m.transit(ctx, src, repo.Dest(), StateHealthy, nil, time.Now())
+	return m.infoByDest(repo.Dest()), nil
+}
+
+// Reregister rereads layouts of the all registered repos and retries lost databases,
+// layouts removed from the database are unregistered, new ones are registered.
+func (m *Manager) Reregister(ctx context.Context) ([]RepoInfo, error) {
+	err := m.Resync(ctx)
+	m.retryLost(ctx, time.Now(), true)
+	return m.ReposInfo(), err
+}
+
+// addRepo makes and registers new repo, database must not be registered yet.
+func (m *Manager) addRepo(ctx context.Context, src dbSource) (RepoInfo, error) {
+	m.RLock()
+	for _, s := range m.sources {
+		if getSrvPortDB(s.cs) == getSrvPortDB(src.cs) {
+			m.RUnlock()
+			return RepoInfo{}, ErrRepoExists
+		}
+	}
+	m.RUnlock()
+	repo, layouts, err := m.makeRepo(ctx, src)
+	if err != nil {
+		return RepoInfo{}, err
+	}
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.reposByDest()[repo.Dest()]; ok {
+		closeRepo(repo)
+		return RepoInfo{}, ErrRepoExists
+	}
+	m.register(repo, layouts, src, len(m.repos) == 0)
+	m.transit(ctx, src, repo.Dest(), StateHealthy, nil, time.Now())
+	return m.infoByDest(repo.Dest()), nil
+}
+
+// register fills maps by repo, must be called under lock.
+func (m *Manager) register(repo domain.LayoutRepo, layouts domain.Layouts, src dbSource, isDefault bool) {
+	m.assign(repo, layouts)
+	if isDefault {
+		m.repos["*"] = repo
+	}
+	m.sources[repo.Dest()] = src
+	m.addExtSvc(repo)
+}
+
+// unregister removes repo from maps, default repo is replaced by any other, must be called under lock.
+func (m *Manager) unregister(repo domain.LayoutRepo) {
+	dest := repo.Dest()
+	for lid, r := range m.repos {
+		if r.Dest() == dest {
+			delete(m.repos, lid)
+		}
+	}
+	m.releaseConflicts(dest)
+	if _, ok := m.repos["*"]; !ok {
+		for _, r := range m.repos {
+			m.repos["*"] = r
+			break
+		}
+	}
+	for i, es := range m.extSvc {
+		if es.Dest() == dest {
+			m.extSvc = append(m.extSvc[:i], m.extSvc[i+1:]...)
+			break
+		}
+	}
+}
+
+// releaseConflicts removes repo from claimants of the conflicted layouts,
+// layouts owned by the repo are routed to the next claimant, must be called under lock.
+func (m *Manager) releaseConflicts(dest string) {
+	byDest := m.reposByDest()
+	for lid, c := range m.conflicts {
+		claimants := make([]string, 0, len(c.Claimants))
+		for _, d := range c.Claimants {
+			if _, ok := byDest[d]; ok && d != dest {
+				claimants = append(claimants, d)
+			}
+		}
+		if len(claimants) == 0 {
+			delete(m.conflicts, lid)
+			continue
+		}
+		if c.Owner == dest {
+			c.Owner = claimants[0]
+			m.repos[lid] = byDest[c.Owner]
+		}
+		if len(claimants) == 1 {
+			delete(m.conflicts, lid)
+			continue
+		}
+		c.Claimants = claimants
+		m.conflicts[lid] = c
+	}
+}
+
+// repoByID must be called under lock.
+func (m *Manager) repoByID(id string) (domain.LayoutRepo, RepoInfo, error) {
+	for dest, repo := range m.reposByDest() {
+		if RepoID(dest) == id {
+			return repo, m.infoByDest(dest), nil
+		}
+	}
+	return nil, RepoInfo{}, ErrRepoNotFound
+}
+
+// reposByDest must be called under lock.
+func (m *Manager) reposByDest() map[string]domain.LayoutRepo {
+	res := make(map[string]domain.LayoutRepo, len(m.repos))
+	for _, repo := range m.repos {
+		res[repo.Dest()] = repo
+	}
+	return res
+}
+
+// reposInfo must be called under lock.
+func (m *Manager) reposInfo() []RepoInfo {
+	res := make([]RepoInfo, 0)
+	for dest := range m.reposByDest() {
+		res = append(res, m.infoByDest(dest))
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Dest < res[j].Dest })
+	return res
+}
+
+// infoByDest must be called under lock.
+func (m *Manager) infoByDest(dest string) RepoInfo {
+	info := RepoInfo{ID: RepoID(dest), Dest: dest, Layouts: make([]string, 0), ProjectID: m.sources[dest].projectID}
+	for lid, repo := range m.repos {
+		if repo.Dest() != dest {
+			continue
+		}
+		info.Scope = repo.Scope()
+		if lid == "*" {
+			info.IsDefault = true
+			continue
+		}
+		info.Layouts = append(info.Layouts, lid)
+	}
+	sort.Strings(info.Layouts)
+	return info
+}
+
+// makeRepo connects to the database and reads its layouts.
+func (m *Manager) makeRepo(ctx context.Context, src dbSource) (domain.LayoutRepo, domain.Layouts, error) {
+	repo, err := m.newRepo(ctx, src.scope, src.cs, src.timeout)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "make repo for %s failed", getSrvPortDB(src.cs))
+	}
+	layouts, err := findLayouts(ctx, repo)
+	if err != nil {
+		closeRepo(repo)
+		return nil, nil, err
+	}
+	return repo, layouts, nil
+}
+
+// closeLater closes unregistered repo after the drain period,
+// requests in progress are finished by the repo.
+func (m *Manager) closeLater(repo domain.LayoutRepo) {
+	time.AfterFunc(m.drain, func() { closeRepo(repo) })
+}
+
+// closeRepo releases connections of the repo if it supports closing,
+// decorated repos are unwrapped.
+func closeRepo(repo domain.LayoutRepo) {
+	for {
+		d, ok := repo.(interface{ Unwrap() domain.LayoutRepo })
+		if !ok {
+			break
+		}
+		repo = d.Unwrap()
+	}
+	switch c := repo.(type) {
+	case interface{ Close() error }:
+		_ = c.Close()
+	case interface{ Close() }:
+		c.Close()
+	}
+}
@@ -0,0 +1,153 @@
+// Package correlate groups repeated events into incidents: events with the same fingerprint
+// (key, layout, store, kind of the source and selected params of the source) following each other
+// within the window make the single incident with count of the occurrences.
+package correlate
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+)
+
+// AllParams in Config.Params includes all params of the source to the fingerprint.
+const AllParams string = "*"
+
+// pruneEvery count of the observed events between removals of the expired fingerprints
+const pruneEvery int = 1000
+
+// Config of the correlation.
+type Config struct {
+	// Window max interval between occurrences of the incident
+	Window time.Duration
+	// Params names of the params of the source included to the fingerprint, AllParams - all params
+	Params []string
+}
+
+// Incident group of the repeated events.
+type Incident struct {
+	ID          string         `json:"incident_id"`
+	Fingerprint string         `json:"fingerprint"`
+	Key         string         `json:"key,omitempty"`
+	LayoutID    string         `json:"layout_id,omitempty"`
+	StoreID     string         `json:"store_id,omitempty"`
+	SourceKind  string         `json:"source_kind,omitempty"`
+	Params      []domain.Param `json:"params,omitempty"`
+	Count       int64          `json:"count"`
+	FirstSeen   time.Time      `json:"first_seen"`
+	LastSeen    time.Time      `json:"last_seen"`
+	LastEventID string         `json:"last_event_id,omitempty"`
+	// Event representative event, the first occurrence
+	Event domain.Event `json:"event"`
+}
+
+// Fingerprint returns fingerprint of the event and params included to it.
+func (c Config) Fingerprint(e domain.Event) (string, []domain.Param) {
+	kind := ""
+	var params []domain.Param
+	if e.Source != nil {
+		kind = e.Source.Kind
+		for _, p := range e.Source.Params {
+			if c.included(p.Name) {
+				params = append(params, p)
+			}
+		}
+	}
+	sort.SliceStable(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	parts := []string{e.Key, e.LayoutID, e.StoreID, kind}
+	for _, p := range params {
+		parts = append(parts, p.Name+"="+p.Value)
+	}
+	sum := sha1.Sum([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:]), params
+}
+
+func (c Config) included(name string) bool {
+	for _, p := range c.Params {
+		if p == name || p == AllParams {
+			return true
+		}
+	}
+	return false
+}
+
+// Group groups events into incidents, the last seen first.
+func Group(events domain.Events, cfg Config) []*Incident {
+	sorted := make(domain.Events, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].EventTime.Before(sorted[j].EventTime) })
+	open := make(map[string]*Incident)
+	var res []*Incident
+	for _, e := range sorted {
+		fp, params := cfg.Fingerprint(e)
+		if inc, ok := open[fp]; ok && e.EventTime.Sub(inc.LastSeen) <= cfg.Window {
+			inc.Count++
+			inc.LastSeen = e.EventTime
+			inc.LastEventID = e.ID
+			continue
+		}
+		inc := newIncident(e, fp, params)
+		open[fp] = inc
+		res = append(res, inc)
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].LastSeen.After(res[j].LastSeen) })
+	return res
+}
+
+func newIncident(e domain.Event, fp string, params []domain.Param) *Incident {
+	kind := ""
+	if e.Source != nil {
+		kind = e.Source.Kind
+	}
+	return &Incident{
+		ID:          fp[:16] + "-" + e.EventTime.UTC().Format("20060102T150405.000000000"),
+		Fingerprint: fp,
+		Key:         e.Key,
+		LayoutID:    e.LayoutID,
+		StoreID:     e.StoreID,
+		SourceKind:  kind,
+		Params:      params,
+		Count:       1,
+		FirstSeen:   e.EventTime,
+		LastSeen:    e.EventTime,
+		LastEventID: e.ID,
+		Event:       e,
+	}
+}
+
+// Deduper passes the first event of the incident and drops its repeats, not safe for concurrent use.
+type Deduper struct {
+	cfg      Config
+	lastSeen map[string]time.Time
+	observed int
+}
+
+// NewDeduper builder for Deduper.
+func NewDeduper(cfg Config) *Deduper {
+	return &Deduper{cfg: cfg, lastSeen: make(map[string]time.Time)}
+}
+
+// First reports whether event opens new incident, repeat within window extends the incident.
+func (d *Deduper) First(e domain.Event) bool {
+	fp, _ := d.cfg.Fingerprint(e)
+	last, ok := d.lastSeen[fp]
+	if !e.EventTime.Before(last) {
+		d.lastSeen[fp] = e.EventTime
+	}
+	if d.observed++; d.observed%pruneEvery == 0 {
+		d.prune(e.EventTime)
+	}
+	return !ok || e.EventTime.Sub(last) > d.cfg.Window
+}
+
+// prune removes fingerprints expired at now.
+func (d *Deduper) prune(now time.Time) {
+	for fp, last := range d.lastSeen {
+		if now.Sub(last) > d.cfg.Window {
+			delete(d.lastSeen, fp)
+		}
+	}
+}
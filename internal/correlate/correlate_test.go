@@ -0,0 +1,100 @@
+package correlate_test
+
+import (
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/correlate"
+)
+
+var t0 = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+var cfg = correlate.Config{Window: 5 * time.Minute, Params: []string{"sensor_id"}}
+
+func event(id string, min int, sensor, value string) domain.Event {
+	return domain.Event{ID: id, Key: "sensor.offline", LayoutID: "10", StoreID: "s1",
+		EventTime: t0.Add(time.Duration(min) * time.Minute),
+		Source:    &domain.Source{Kind: "sensor", Params: []domain.Param{{Name: "value", Value: value}, {Name: "sensor_id", Value: sensor}}}}
+}
+
+// flapping sensor 1 with repeats within 5 minutes, pause of 10 minutes and repeat, sensor 2 once
+var events = domain.Events{
+	event("1", 0, "1", "a"), event("2", 3, "1", "b"), event("3", 7, "1", "c"), event("4", 4, "2", "a"),
+	event("5", 17, "1", "d"),
+}
+
+func TestConfig_Fingerprint(t *testing.T) {
+	fp1, params := cfg.Fingerprint(events[0])
+	fp2, _ := cfg.Fingerprint(events[1])
+	fp3, _ := cfg.Fingerprint(events[3])
+	if fp1 != fp2 || fp1 == fp3 || len(params) != 1 || params[0].Name != "sensor_id" {
+		t.Errorf("Fingerprint() = %s, %s, %s, params %+v, want equal for sensor 1 only with sensor_id", fp1, fp2, fp3, params)
+	}
+	all := correlate.Config{Params: []string{correlate.AllParams}}
+	a1, _ := all.Fingerprint(events[0])
+	a2, _ := all.Fingerprint(events[1])
+	if a1 == a2 {
+		t.Error("Fingerprint() with all params is equal for different values")
+	}
+}
+
+func TestGroup(t *testing.T) {
+	incidents := correlate.Group(events, cfg)
+	if len(incidents) != 3 {
+		t.Fatalf("Group() = %d incidents, want 3", len(incidents))
+	}
+	last, flapping, single := incidents[0], incidents[1], incidents[2]
+	if flapping.Count != 3 || !flapping.FirstSeen.Equal(t0) || !flapping.LastSeen.Equal(t0.Add(7*time.Minute)) ||
+		flapping.Event.ID != "1" || flapping.LastEventID != "3" {
+		t.Errorf("flapping incident %+v, want 3 events from 1 to 3", flapping)
+	}
+	if last.Count != 1 || last.Event.ID != "5" || last.Fingerprint != flapping.Fingerprint || last.ID == flapping.ID {
+		t.Errorf("last incident %+v, want new incident of sensor 1 after window", last)
+	}
+	if single.Count != 1 || single.Event.ID != "4" {
+		t.Errorf("single incident %+v, want event 4", single)
+	}
+}
+
+func TestEventRepo_FindConsumerChainEvents(t *testing.T) {
+	changed := events[1]
+	changed.Change = &domain.EventTransition{Action: "acknowledge"}
+	repo := correlate.NewEventRepo(&fakeEventRepo{events: append(events, changed)}, cfg)
+	cancel := make(chan struct{})
+	defer close(cancel)
+	var ids []string
+	for e := range repo.FindConsumerChainEvents("test", "", "", "", "", "", t0, cancel) {
+		ids = append(ids, e.ID)
+	}
+	// 2 and 3 are repeats, change of the state passes
+	want := []string{"1", "4", "5", "2"}
+	if len(ids) != len(want) {
+		t.Fatalf("stream %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("stream %v, want %v", ids, want)
+			break
+		}
+	}
+}
+
+type fakeEventRepo struct {
+	events domain.Events
+}
+
+func (r *fakeEventRepo) FindChainEvents(from, to time.Time, layoutID, storeID, key, kind, severity string,
+	limit, offset int64) (domain.Events, int64, error) {
+	return r.events, int64(len(r.events)), nil
+}
+
+func (r *fakeEventRepo) FindConsumerChainEvents(subscriberID, layoutID, storeID, key, kind, severity string,
+	from time.Time, cancel <-chan struct{}) chan domain.Event {
+	ch := make(chan domain.Event, len(r.events))
+	for _, e := range r.events {
+		ch <- e
+	}
+	close(ch)
+	return ch
+}
@@ -0,0 +1,60 @@
+package correlate
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+)
+
+var dropped = promauto.NewCounter(
+	prometheus.CounterOpts{
+		Name: "events_correlated_dropped_total",
+		Help: "Count of the repeated events of the incidents dropped from the streams of the events",
+	},
+)
+
+// EventRepo decorator of domain.IEventRepo, streams of the events pass only the first event of the incident,
+// changes of the states of the events and found events pass as is.
+type EventRepo struct {
+	next domain.IEventRepo
+	cfg  Config
+}
+
+// NewEventRepo builder for EventRepo.
+func NewEventRepo(next domain.IEventRepo, cfg Config) *EventRepo {
+	return &EventRepo{next: next, cfg: cfg}
+}
+
+// FindChainEvents implements domain.IEventRepo.
+func (r *EventRepo) FindChainEvents(from, to time.Time, layoutID, storeID, key, kind, severity string,
+	limit, offset int64) (domain.Events, int64, error) {
+	//
+	return r.next.FindChainEvents(from, to, layoutID, storeID, key, kind, severity, limit, offset)
+}
+
+// FindConsumerChainEvents implements domain.IEventRepo, repeats are dropped for each consumer separately.
+func (r *EventRepo) FindConsumerChainEvents(subscriberID, layoutID, storeID, key, kind, severity string,
+	from time.Time, cancel <-chan struct{}) chan domain.Event {
+	//
+	in := r.next.FindConsumerChainEvents(subscriberID, layoutID, storeID, key, kind, severity, from, cancel)
+	out := make(chan domain.Event)
+	go func() {
+		defer close(out)
+		d := NewDeduper(r.cfg)
+		for e := range in {
+			if e.Change == nil && !d.First(e) {
+				dropped.Inc()
+				continue
+			}
+			select {
+			case out <- e:
+			case <-cancel:
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,243 @@
+// Package eventhub shares consumers of the events repo between live subscribers:
+// one upstream consumer per topic (filter of the events) fans events out to subscribers
+// with bounded buffers, slow subscribers lose events by the drop policy.
+package eventhub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/pkg/logging"
+)
+
+// drop policies of the slow subscriber with full buffer
+const (
+	// DropOldest drops the oldest buffered event to put new one
+	DropOldest string = "drop_oldest"
+	// DropNewest drops new event, buffered events are kept
+	DropNewest string = "drop_newest"
+	// Disconnect closes events of the subscriber
+	Disconnect string = "disconnect"
+)
+
+// DefaultBuffer size of the buffer of the subscriber if not set.
+const DefaultBuffer int = 256
+
+// consumerPrefix prefix of the subscriber id of the upstream consumer.
+const consumerPrefix string = "hub:"
+
+var (
+	// ErrInvalid config malformed.
+	ErrInvalid = errors.New("invalid hub config")
+)
+
+// Config of the hub.
+type Config struct {
+	// Buffer size of the buffer of the each subscriber
+	Buffer int `mapstructure:"buffer"`
+	// Policy of the subscriber with full buffer, default drop_oldest
+	Policy string `mapstructure:"policy"`
+}
+
+// Topic filter of the upstream consumer, subscribers of the same topic share the consumer.
+type Topic struct {
+	LayoutID string
+	StoreID  string
+	Key      string
+	Kind     string
+	Severity string
+}
+
+func (t Topic) String() string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s", t.LayoutID, t.StoreID, t.Key, t.Kind, t.Severity)
+}
+
+// Hub fans out events of the upstream consumers to subscribers.
+type Hub struct {
+	repo domain.IEventRepo
+	cfg  Config
+	ctx  context.Context
+	mu   sync.Mutex
+	// topics guarded by mu
+	topics map[Topic]*upstream
+}
+
+// upstream consumer of the topic and its subscribers.
+type upstream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	// subs guarded by mu of the hub
+	subs map[*Subscriber]struct{}
+}
+
+// Subscriber receives events of the topic from the time of subscription.
+type Subscriber struct {
+	topic Topic
+	hub   *Hub
+	ch    chan domain.Event
+	// closed guarded by mu of the hub
+	closed  bool
+	dropped int64
+}
+
+// New builder for Hub, upstream consumers are stopped when ctx is done.
+func New(ctx context.Context, repo domain.IEventRepo, cfg Config) (*Hub, error) {
+	if cfg.Buffer == 0 {
+		cfg.Buffer = DefaultBuffer
+	}
+	if cfg.Buffer < 0 {
+		return nil, errors.Wrapf(ErrInvalid, "buffer %d is negative", cfg.Buffer)
+	}
+	switch cfg.Policy {
+	case "":
+		cfg.Policy = DropOldest
+	case DropOldest, DropNewest, Disconnect:
+	default:
+		return nil, errors.Wrapf(ErrInvalid, "unknown policy %q, allowed %s, %s, %s",
+			cfg.Policy, DropOldest, DropNewest, Disconnect)
+	}
+	return &Hub{repo: repo, cfg: cfg, ctx: ctx, topics: make(map[Topic]*upstream)}, nil
+}
+
+// Subscribe adds subscriber of the topic, the first subscriber starts upstream consumer,
+// consumer is started out of the lock, so subscribers of the other topics aren't blocked by the repo.
+func (h *Hub) Subscribe(t Topic) *Subscriber {
+	s := &Subscriber{topic: t, hub: h, ch: make(chan domain.Event, h.cfg.Buffer)}
+	h.mu.Lock()
+	u, ok := h.topics[t]
+	if !ok {
+		ctx, cancel := context.WithCancel(h.ctx)
+		u = &upstream{ctx: ctx, cancel: cancel, subs: make(map[*Subscriber]struct{})}
+		h.topics[t] = u
+		upstreams.Inc()
+	}
+	u.subs[s] = struct{}{}
+	subscribers.Inc()
+	h.mu.Unlock()
+	if !ok {
+		in := h.repo.FindConsumerChainEvents(consumerPrefix+t.String(), t.LayoutID, t.StoreID, t.Key, t.Kind, t.Severity,
+			time.Now(), u.ctx.Done())
+		go h.fanOut(u.ctx, t, u, in)
+	}
+	return s
+}
+
+// Events returns channel of the events, it is closed when subscriber is closed,
+// disconnected as slow or upstream consumer stopped.
+func (s *Subscriber) Events() <-chan domain.Event {
+	return s.ch
+}
+
+// Dropped returns count of the events dropped for the slow subscriber.
+func (s *Subscriber) Dropped() int64 {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.dropped
+}
+
+// Close removes subscriber, the last subscriber stops upstream consumer.
+func (s *Subscriber) Close() {
+	h := s.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s.closed {
+		return
+	}
+	u := h.topics[s.topic]
+	h.remove(u, s)
+	if u != nil && len(u.subs) == 0 {
+		u.cancel()
+		delete(h.topics, s.topic)
+		upstreams.Dec()
+	}
+}
+
+// remove closes subscriber, mu must be held.
+func (h *Hub) remove(u *upstream, s *Subscriber) {
+	s.closed = true
+	close(s.ch)
+	if u != nil {
+		delete(u.subs, s)
+	}
+	subscribers.Dec()
+}
+
+// fanOut sends events of the upstream consumer to subscribers, closes subscribers when consumer stops.
+func (h *Hub) fanOut(ctx context.Context, t Topic, u *upstream, in <-chan domain.Event) {
+	log := logging.FromContext(ctx)
+	defer func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		for s := range u.subs {
+			h.remove(u, s)
+		}
+		if h.topics[t] == u {
+			delete(h.topics, t)
+			upstreams.Dec()
+		}
+		u.cancel()
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, more := <-in:
+			if !more {
+				log.Warnf("upstream consumer of the topic %s stopped", t)
+				return
+			}
+			h.mu.Lock()
+			for s := range u.subs {
+				if !h.push(s, e) {
+					log.Warnf("slow subscriber of the topic %s disconnected, buffer %d", t, h.cfg.Buffer)
+					h.remove(u, s)
+					continue
+				}
+				lag.Observe(time.Since(createdAt(e)).Seconds())
+			}
+			h.mu.Unlock()
+		}
+	}
+}
+
+// createdAt returns time the event was stored at, event_time for the events without created_at.
+func createdAt(e domain.Event) time.Time {
+	if e.CreatedAt != nil {
+		return *e.CreatedAt
+	}
+	return e.EventTime
+}
+
+// push puts event to the buffer of the subscriber by the policy,
+// returns false if subscriber must be disconnected, mu must be held.
+func (h *Hub) push(s *Subscriber, e domain.Event) bool {
+	buffered.Observe(float64(len(s.ch)))
+	select {
+	case s.ch <- e:
+		return true
+	default:
+	}
+	s.dropped++
+	dropped.WithLabelValues(h.cfg.Policy).Inc()
+	switch h.cfg.Policy {
+	case DropNewest:
+		return true
+	case Disconnect:
+		return false
+	}
+	// only fan out puts events, so the freed place is not taken by others
+	select {
+	case <-s.ch:
+	default:
+	}
+	select {
+	case s.ch <- e:
+	default:
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package eventhub_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventhub"
+)
+
+// liveEventRepo counts consumers, sends events pushed by test to every running consumer,
+// start of the consumer of the layout "slow" signals to the slow channel and waits for it.
+type liveEventRepo struct {
+	mu        sync.Mutex
+	consumers map[string]chan domain.Event
+	started   int
+	slow      chan struct{}
+}
+
+func (r *liveEventRepo) FindChainEvents(from, to time.Time, layoutID, storeID, key, kind, severity string,
+	limit, offset int64) (domain.Events, int64, error) {
+	return domain.Events{}, 0, nil
+}
+
+func (r *liveEventRepo) FindConsumerChainEvents(subscriberID, layoutID, storeID, key, kind, severity string,
+	from time.Time, cancel <-chan struct{}) chan domain.Event {
+	if layoutID == "slow" {
+		r.slow <- struct{}{}
+		<-r.slow
+	}
+	ch := make(chan domain.Event)
+	r.mu.Lock()
+	r.started++
+	r.consumers[layoutID] = ch
+	r.mu.Unlock()
+	go func() {
+		<-cancel
+		r.mu.Lock()
+		if r.consumers[layoutID] == ch {
+			delete(r.consumers, layoutID)
+		}
+		r.mu.Unlock()
+	}()
+	return ch
+}
+
+func (r *liveEventRepo) push(layoutID, id string) {
+	r.mu.Lock()
+	ch := r.consumers[layoutID]
+	r.mu.Unlock()
+	ch <- domain.Event{ID: id, LayoutID: layoutID, EventTime: time.Now()}
+}
+
+func (r *liveEventRepo) running() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.consumers)
+}
+
+func newHub(t *testing.T, cfg eventhub.Config) (*eventhub.Hub, *liveEventRepo) {
+	repo := &liveEventRepo{consumers: make(map[string]chan domain.Event)}
+	h, err := eventhub.New(context.Background(), repo, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	return h, repo
+}
+
+func ids(s *eventhub.Subscriber, n int) []string {
+	var res []string
+	for i := 0; i < n; i++ {
+		select {
+		case e := <-s.Events():
+			res = append(res, e.ID)
+		case <-time.After(time.Second):
+			return res
+		}
+	}
+	return res
+}
+
+func TestHub_Subscribe(t *testing.T) {
+	h, repo := newHub(t, eventhub.Config{Buffer: 4})
+	a, b := h.Subscribe(eventhub.Topic{LayoutID: "10"}), h.Subscribe(eventhub.Topic{LayoutID: "10"})
+	c := h.Subscribe(eventhub.Topic{LayoutID: "20"})
+	if repo.started != 2 {
+		t.Fatalf("started %d consumers, want 1 per topic", repo.started)
+	}
+	repo.push("10", "1")
+	repo.push("20", "2")
+	if got := ids(a, 1); len(got) != 1 || got[0] != "1" {
+		t.Errorf("subscriber a got %v, want 1", got)
+	}
+	if got := ids(b, 1); len(got) != 1 || got[0] != "1" {
+		t.Errorf("subscriber b got %v, want 1", got)
+	}
+	if got := ids(c, 1); len(got) != 1 || got[0] != "2" {
+		t.Errorf("subscriber c got %v, want 2", got)
+	}
+
+	a.Close()
+	a.Close()
+	if repo.running() != 2 {
+		t.Errorf("running %d consumers after the first close, want 2", repo.running())
+	}
+	b.Close()
+	c.Close()
+	deadline := time.Now().Add(time.Second)
+	for repo.running() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if repo.running() != 0 {
+		t.Errorf("running %d consumers without subscribers, want 0", repo.running())
+	}
+	if _, more := <-b.Events(); more {
+		t.Error("events of the closed subscriber aren't closed")
+	}
+}
+
+func TestHub_SubscribeWhileConsumerStarts(t *testing.T) {
+	h, repo := newHub(t, eventhub.Config{Buffer: 4})
+	repo.slow = make(chan struct{})
+	started := make(chan *eventhub.Subscriber)
+	go func() {
+		started <- h.Subscribe(eventhub.Topic{LayoutID: "slow"})
+	}()
+	<-repo.slow
+	done := make(chan *eventhub.Subscriber)
+	go func() {
+		done <- h.Subscribe(eventhub.Topic{LayoutID: "10"})
+	}()
+	select {
+	case s := <-done:
+		s.Close()
+	case <-time.After(time.Second):
+		t.Fatal("subscribe is blocked by start of the consumer of the other topic")
+	}
+	repo.slow <- struct{}{}
+	(<-started).Close()
+}
+
+func TestHub_slowSubscriber(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   []string
+		closed bool
+	}{
+		{eventhub.DropOldest, []string{"3", "4"}, false},
+		{eventhub.DropNewest, []string{"1", "2"}, false},
+		{eventhub.Disconnect, []string{"1", "2"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			h, repo := newHub(t, eventhub.Config{Buffer: 2, Policy: tt.policy})
+			slow := h.Subscribe(eventhub.Topic{LayoutID: "10"})
+			fast := h.Subscribe(eventhub.Topic{LayoutID: "10"})
+			defer fast.Close()
+			defer slow.Close()
+			for _, id := range []string{"1", "2", "3", "4"} {
+				repo.push("10", id)
+				if got := ids(fast, 1); len(got) != 1 || got[0] != id {
+					t.Fatalf("fast subscriber got %v, want %s", got, id)
+				}
+			}
+			got := ids(slow, 2)
+			if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+				t.Errorf("slow subscriber got %v, want %v", got, tt.want)
+			}
+			if slow.Dropped() != 2 && !tt.closed {
+				t.Errorf("dropped %d, want 2", slow.Dropped())
+			}
+			select {
+			case _, more := <-slow.Events():
+				if more == tt.closed {
+					t.Errorf("slow subscriber closed = %v, want %v", !more, tt.closed)
+				}
+			case <-time.After(100 * time.Millisecond):
+				if tt.closed {
+					t.Error("slow subscriber isn't disconnected")
+				}
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, cfg := range []eventhub.Config{{Buffer: -1}, {Policy: "block"}} {
+		if _, err := eventhub.New(context.Background(), &liveEventRepo{}, cfg); err == nil {
+			t.Errorf("New(%+v) without error", cfg)
+		}
+	}
+}
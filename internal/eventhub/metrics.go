@@ -0,0 +1,42 @@
+package eventhub
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	upstreams = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "events_hub_upstreams",
+			Help: "Count of the upstream consumers of the events shared by subscribers",
+		},
+	)
+	subscribers = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "events_hub_subscribers",
+			Help: "Count of the subscribers of the events hub",
+		},
+	)
+	dropped = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "events_hub_dropped_total",
+			Help: "Count of the events dropped for the slow subscribers by policy: drop_oldest, drop_newest, disconnect",
+		},
+		[]string{"policy"},
+	)
+	lag = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "events_hub_lag_seconds",
+			Help:    "Lag of the events from created_at (event_time if not set) till put to the buffer of the subscriber",
+			Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+		},
+	)
+	buffered = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "events_hub_buffered",
+			Help:    "Count of the events waiting in the buffer of the subscriber when new event is put",
+			Buckets: []float64{0, 1, 4, 16, 64, 256, 1024},
+		},
+	)
+)
@@ -0,0 +1,117 @@
+// Package disk implements eventstate.RepoInterface in the embedded on-disk database.
+package disk
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/boltdb"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstate"
+)
+
+var bucketEvents = []byte("events")
+
+// Repo on-disk storage of the events with state.
+type Repo struct {
+	db *bolt.DB
+}
+
+// New builder for Repo, opens or creates database file by path.
+func New(path string) (*Repo, error) {
+	db, err := boltdb.Open(path, bucketEvents)
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{db: db}, nil
+}
+
+// Get returns event by id, if not exists returns eventstate.ErrNotFound.
+func (r *Repo) Get(ctx context.Context, id string) (*domain.Event, error) {
+	var e *domain.Event
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		e, err = get(tx.Bucket(bucketEvents), id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Add adds event, if exists returns eventstate.ErrExists.
+func (r *Repo) Add(ctx context.Context, e *domain.Event) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketEvents)
+		if b.Get([]byte(e.ID)) != nil {
+			return eventstate.ErrExists
+		}
+		return put(b, e)
+	})
+}
+
+// Update changes event by fn in the single transaction,
+// if not exists returns eventstate.ErrNotFound.
+func (r *Repo) Update(ctx context.Context, id string, fn func(e *domain.Event) error) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketEvents)
+		e, err := get(b, id)
+		if err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+		return put(b, e)
+	})
+}
+
+// Find returns events matching query ordered by event_time descending.
+func (r *Repo) Find(ctx context.Context, q eventstate.Query) (domain.Events, error) {
+	res := make(domain.Events, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketEvents).ForEach(func(_, v []byte) error {
+			e := domain.Event{}
+			if err := e.UnmarshalJSON(v); err != nil {
+				return errors.Wrap(err, "unmarshal event failed")
+			}
+			if q.Match(e) {
+				res = append(res, e)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	eventstate.SortDesc(res)
+	return res, nil
+}
+
+// Close closes database file.
+func (r *Repo) Close() error {
+	return r.db.Close()
+}
+
+func get(b *bolt.Bucket, id string) (*domain.Event, error) {
+	v := b.Get([]byte(id))
+	if v == nil {
+		return nil, eventstate.ErrNotFound
+	}
+	e := &domain.Event{}
+	if err := e.UnmarshalJSON(v); err != nil {
+		return nil, errors.Wrap(err, "unmarshal event failed")
+	}
+	return e, nil
+}
+
+func put(b *bolt.Bucket, e *domain.Event) error {
+	bts, err := e.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(err, "marshal event failed")
+	}
+	return b.Put([]byte(e.ID), bts)
+}
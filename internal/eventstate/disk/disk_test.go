@@ -0,0 +1,67 @@
+package disk_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstate"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstate/disk"
+)
+
+func TestRepo_UpdateReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventstate")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir error, %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.db")
+	ctx := context.Background()
+	r, err := disk.New(path)
+	if err != nil {
+		t.Fatalf("disk.New error, %s", err)
+	}
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i, id := range []string{"1", "2"} {
+		e, err := eventstate.NewEvent(domain.Event{ID: id, Key: "door.open", Kind: eventstate.KindSystem,
+			EventTime: now.Add(time.Duration(i) * time.Minute)}, "op", now)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		if err := r.Add(ctx, &e); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	if err := r.Add(ctx, &domain.Event{ID: "1"}); !errors.Is(err, eventstate.ErrExists) {
+		t.Errorf("Repo.Add() twice error = %v, want %v", err, eventstate.ErrExists)
+	}
+	err = r.Update(ctx, "1", func(e *domain.Event) error {
+		return eventstate.Apply(e, domain.EventTransition{Action: eventstate.ActionResolve, Actor: "op", Time: now})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	reopened, err := disk.New(path)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	defer reopened.Close()
+	events, err := reopened.Find(ctx, eventstate.Query{Statuses: []string{domain.EventStatusResolved}, CreatedOnly: true})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if len(events) != 1 || events[0].ID != "1" || len(events[0].State.History) != 2 {
+		t.Fatalf("Repo.Find() = %+v, want resolved event 1", events)
+	}
+	if _, err := reopened.Get(ctx, "3"); !errors.Is(err, eventstate.ErrNotFound) {
+		t.Errorf("Repo.Get() of unknown error = %v, want %v", err, eventstate.ErrNotFound)
+	}
+}
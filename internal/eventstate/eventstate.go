@@ -0,0 +1,236 @@
+// Package eventstate keeps lifecycle of the events: events created by users and systems through API
+// and transitions acknowledge, assign, comment, resolve made by actors.
+// Events of the events database are read-only, so their states are stored here with the snapshot of the event.
+package eventstate
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+)
+
+// actions of the event lifecycle
+const (
+	ActionCreate      string = "create"
+	ActionAcknowledge string = "acknowledge"
+	ActionAssign      string = "assign"
+	ActionComment     string = "comment"
+	ActionResolve     string = "resolve"
+)
+
+// kinds and severities of the events created through API
+const (
+	KindUser      string = "user"
+	KindSystem    string = "system"
+	SeverityInfo  string = "info"
+	SeverityWarn  string = "warn"
+	SeverityAlarm string = "alarm"
+)
+
+const (
+	idBytes         int    = 12
+	idPrefixCreated string = "api-"
+	maxMessageSize  int    = 4096
+	maxCommentSize  int    = 4096
+)
+
+var (
+	// ErrNotFound event not exists.
+	ErrNotFound = errors.New("event not found")
+	// ErrExists event with the same id exists already.
+	ErrExists = errors.New("event already exists")
+	// ErrInvalid event or transition malformed.
+	ErrInvalid = errors.New("invalid event")
+	// ErrTransition transition isn't allowed in the current status.
+	ErrTransition = errors.New("transition not allowed")
+)
+
+// Query filter of the stored events, empty fields match any value.
+type Query struct {
+	From     time.Time
+	To       time.Time
+	LayoutID string
+	StoreID  string
+	Key      string
+	Kind     string
+	Severity string
+	// Statuses of the lifecycle, empty matches any status
+	Statuses []string
+	// CreatedOnly matches only events created through API
+	CreatedOnly bool
+}
+
+// RepoInterface storage of the events with state.
+type RepoInterface interface {
+	// Get returns event by id, if not exists returns ErrNotFound
+	Get(ctx context.Context, id string) (*domain.Event, error)
+	// Add adds event, if exists returns ErrExists
+	Add(ctx context.Context, e *domain.Event) error
+	// Update changes event by fn atomically, if not exists returns ErrNotFound
+	Update(ctx context.Context, id string, fn func(e *domain.Event) error) error
+	// Find returns events matching query ordered by event_time descending
+	Find(ctx context.Context, q Query) (domain.Events, error)
+}
+
+// Match reports whether event matches query.
+func (q Query) Match(e domain.Event) bool {
+	if (!q.From.IsZero() && e.EventTime.Before(q.From)) || (!q.To.IsZero() && e.EventTime.After(q.To)) {
+		return false
+	}
+	if q.CreatedOnly && !IsCreated(e) {
+		return false
+	}
+	for _, f := range []struct{ want, got string }{
+		{q.LayoutID, e.LayoutID}, {q.StoreID, e.StoreID}, {q.Key, e.Key}, {q.Kind, e.Kind}, {q.Severity, e.Severity},
+	} {
+		if f.want != "" && f.want != f.got {
+			return false
+		}
+	}
+	return MatchStatus(q.Statuses, e)
+}
+
+// MatchStatus reports whether status of the event is one of statuses, empty statuses match any.
+func MatchStatus(statuses []string, e domain.Event) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	status := e.Status()
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateStatuses checks statuses of the filter.
+func ValidateStatuses(statuses []string) error {
+	for _, s := range statuses {
+		switch s {
+		case domain.EventStatusNew, domain.EventStatusAcknowledged, domain.EventStatusAssigned, domain.EventStatusResolved:
+		default:
+			return errors.Wrapf(ErrInvalid, "unknown status %s, allowed %s, %s, %s, %s", s, domain.EventStatusNew,
+				domain.EventStatusAcknowledged, domain.EventStatusAssigned, domain.EventStatusResolved)
+		}
+	}
+	return nil
+}
+
+// IsCreated reports whether event was created through API.
+func IsCreated(e domain.Event) bool {
+	return e.State != nil && len(e.State.History) > 0 && e.State.History[0].Action == ActionCreate
+}
+
+// SortDesc sorts events by event_time descending, the newest first.
+func SortDesc(events domain.Events) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].EventTime.Equal(events[j].EventTime) {
+			return events[i].ID > events[j].ID
+		}
+		return events[i].EventTime.After(events[j].EventTime)
+	})
+}
+
+// NewEvent validates event created by actor and sets its id, time and state.
+func NewEvent(e domain.Event, actor string, now time.Time) (domain.Event, error) {
+	if e.Key == "" {
+		return e, errors.Wrap(ErrInvalid, "key is empty")
+	}
+	if e.Kind != KindUser && e.Kind != KindSystem {
+		return e, errors.Wrapf(ErrInvalid, "kind %q not allowed, need %s or %s", e.Kind, KindUser, KindSystem)
+	}
+	switch e.Severity {
+	case "":
+		e.Severity = SeverityInfo
+	case SeverityInfo, SeverityWarn, SeverityAlarm:
+	default:
+		return e, errors.Wrapf(ErrInvalid, "severity %q not allowed, need %s, %s or %s",
+			e.Severity, SeverityInfo, SeverityWarn, SeverityAlarm)
+	}
+	if len(e.Message) > maxMessageSize {
+		return e, errors.Wrapf(ErrInvalid, "message is longer than %d", maxMessageSize)
+	}
+	if actor == "" {
+		return e, errors.Wrap(ErrInvalid, "actor is empty")
+	}
+	if e.ID == "" {
+		id, err := randomHex(idBytes)
+		if err != nil {
+			return e, errors.WithMessage(err, "generate id failed")
+		}
+		e.ID = idPrefixCreated + id
+	}
+	if e.EventTime.IsZero() {
+		e.EventTime = now
+	}
+	created := now
+	e.CreatedAt = &created
+	e.Creator = actor
+	t := domain.EventTransition{Action: ActionCreate, Actor: actor, Time: now}
+	e.State = &domain.EventState{Status: domain.EventStatusNew, UpdatedAt: now, History: []domain.EventTransition{t}}
+	e.Change = &t
+	return e, nil
+}
+
+// Apply applies transition to the state of the event:
+// acknowledge only new event, assign and resolve not resolved one, comment any.
+func Apply(e *domain.Event, t domain.EventTransition) error {
+	if t.Actor == "" {
+		return errors.Wrap(ErrInvalid, "actor is empty")
+	}
+	if len(t.Comment) > maxCommentSize {
+		return errors.Wrapf(ErrInvalid, "comment is longer than %d", maxCommentSize)
+	}
+	status := e.Status()
+	state := domain.EventState{Status: status}
+	if e.State != nil {
+		state = *e.State
+		state.History = append(make([]domain.EventTransition, 0, len(e.State.History)+1), e.State.History...)
+	}
+	switch t.Action {
+	case ActionAcknowledge:
+		if status != domain.EventStatusNew {
+			return errors.Wrapf(ErrTransition, "can't acknowledge %s event", status)
+		}
+		state.Status = domain.EventStatusAcknowledged
+	case ActionAssign:
+		if t.Assignee == "" {
+			return errors.Wrap(ErrInvalid, "assignee is empty")
+		}
+		if status == domain.EventStatusResolved {
+			return errors.Wrapf(ErrTransition, "can't assign %s event", status)
+		}
+		state.Status = domain.EventStatusAssigned
+		state.Assignee = t.Assignee
+	case ActionComment:
+		if t.Comment == "" {
+			return errors.Wrap(ErrInvalid, "comment is empty")
+		}
+	case ActionResolve:
+		if status == domain.EventStatusResolved {
+			return errors.Wrapf(ErrTransition, "can't resolve %s event", status)
+		}
+		state.Status = domain.EventStatusResolved
+	default:
+		return errors.Wrapf(ErrInvalid, "unknown action %s", t.Action)
+	}
+	state.UpdatedAt = t.Time
+	state.History = append(state.History, t)
+	e.State = &state
+	return nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
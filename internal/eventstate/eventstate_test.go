@@ -0,0 +1,221 @@
+package eventstate_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstate"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstate/mem"
+)
+
+var t0 = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeEventRepo database events ordered by event_time descending and live channel of the layout 10.
+type fakeEventRepo struct {
+	stored domain.Events
+	live   chan domain.Event
+}
+
+func (r *fakeEventRepo) FindChainEvents(from, to time.Time, layoutID, storeID, key, kind, severity string,
+	limit, offset int64) (domain.Events, int64, error) {
+	found := make(domain.Events, 0)
+	for _, e := range r.stored {
+		if !e.EventTime.Before(from) && !e.EventTime.After(to) && (layoutID == "" || e.LayoutID == layoutID) {
+			found = append(found, e)
+		}
+	}
+	total := int64(len(found))
+	if offset >= total {
+		return domain.Events{}, total, nil
+	}
+	if offset+limit < total {
+		return found[offset : offset+limit], total, nil
+	}
+	return found[offset:], total, nil
+}
+
+func (r *fakeEventRepo) FindConsumerChainEvents(subscriberID, layoutID, storeID, key, kind, severity string,
+	from time.Time, cancel <-chan struct{}) chan domain.Event {
+	if layoutID != "10" {
+		return make(chan domain.Event)
+	}
+	return r.live
+}
+
+// dbEvents returns n events of the layout 10 every minute, the newest first.
+func dbEvents(n int) domain.Events {
+	events := make(domain.Events, 0, n)
+	for i := n; i > 0; i-- {
+		events = append(events, domain.Event{ID: fmt.Sprint(i), Key: "queue.threshold.exceeded", Kind: "business",
+			Severity: "alarm", EventTime: t0.Add(time.Duration(i) * time.Minute), LayoutID: "10"})
+	}
+	return events
+}
+
+func ids(events domain.Events) string {
+	res := ""
+	for _, e := range events {
+		res += e.ID + ","
+	}
+	return res
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		t       domain.EventTransition
+		want    string
+		wantErr error
+	}{
+		{"acknowledge", domain.EventStatusNew, domain.EventTransition{Action: eventstate.ActionAcknowledge, Actor: "op"},
+			domain.EventStatusAcknowledged, nil},
+		{"acknowledge_twice", domain.EventStatusAcknowledged,
+			domain.EventTransition{Action: eventstate.ActionAcknowledge, Actor: "op"}, "", eventstate.ErrTransition},
+		{"assign", domain.EventStatusAcknowledged,
+			domain.EventTransition{Action: eventstate.ActionAssign, Actor: "op", Assignee: "ivanov"}, domain.EventStatusAssigned, nil},
+		{"assign_without_assignee", domain.EventStatusNew,
+			domain.EventTransition{Action: eventstate.ActionAssign, Actor: "op"}, "", eventstate.ErrInvalid},
+		{"comment_resolved", domain.EventStatusResolved,
+			domain.EventTransition{Action: eventstate.ActionComment, Actor: "op", Comment: "done"}, domain.EventStatusResolved, nil},
+		{"resolve", domain.EventStatusAssigned, domain.EventTransition{Action: eventstate.ActionResolve, Actor: "op"},
+			domain.EventStatusResolved, nil},
+		{"resolve_twice", domain.EventStatusResolved, domain.EventTransition{Action: eventstate.ActionResolve, Actor: "op"},
+			"", eventstate.ErrTransition},
+		{"without_actor", domain.EventStatusNew, domain.EventTransition{Action: eventstate.ActionResolve}, "", eventstate.ErrInvalid},
+		{"unknown", domain.EventStatusNew, domain.EventTransition{Action: "close", Actor: "op"}, "", eventstate.ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := domain.Event{ID: "1"}
+			if tt.status != domain.EventStatusNew {
+				e.State = &domain.EventState{Status: tt.status}
+			}
+			err := eventstate.Apply(&e, tt.t)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Apply() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if e.Status() != tt.status {
+					t.Errorf("failed Apply() changed status to %s", e.Status())
+				}
+				return
+			}
+			if e.Status() != tt.want || e.State.History[len(e.State.History)-1].Action != tt.t.Action {
+				t.Errorf("Apply() state = %+v, want %s with history", e.State, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventRepo_CreateFind(t *testing.T) {
+	ctx := context.Background()
+	r := eventstate.New(&fakeEventRepo{stored: dbEvents(5)}, mem.New())
+	if _, err := r.Create(ctx, domain.Event{Key: "door.open", Kind: "business"}, "op"); !errors.Is(err, eventstate.ErrInvalid) {
+		t.Errorf("Create() of the business event error = %v, want %v", err, eventstate.ErrInvalid)
+	}
+	created, err := r.Create(ctx, domain.Event{ID: "c1", Key: "door.open", Kind: eventstate.KindUser,
+		EventTime: t0.Add(150 * time.Second), LayoutID: "10"}, "op")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if created.Severity != eventstate.SeverityInfo || created.Creator != "op" || created.Status() != domain.EventStatusNew {
+		t.Errorf("Create() = %+v, want info event of op in new status", created)
+	}
+	if _, err := r.Create(ctx, created, "op"); !errors.Is(err, eventstate.ErrExists) {
+		t.Errorf("Create() twice error = %v, want %v", err, eventstate.ErrExists)
+	}
+	if _, err := r.Transition(ctx, "4", t0.Add(4*time.Minute),
+		domain.EventTransition{Action: eventstate.ActionAcknowledge, Actor: "op"}); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	events, total, err := r.FindChainEvents(t0, t0.Add(time.Hour), "10", "", "", "", "", 3, 1)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if total != 6 || ids(events) != "4,3,c1," || events[0].Status() != domain.EventStatusAcknowledged {
+		t.Errorf("FindChainEvents() = %s total %d, want 4,3,c1 of 6 with acknowledged 4", ids(events), total)
+	}
+	for _, tt := range []struct {
+		statuses []string
+		want     string
+	}{
+		{[]string{domain.EventStatusAcknowledged}, "4,"},
+		{[]string{domain.EventStatusNew}, "5,3,c1,2,1,"},
+		{[]string{domain.EventStatusNew, domain.EventStatusAcknowledged}, "5,4,3,c1,2,1,"},
+	} {
+		events, total, err := r.FindChainEventsByStatus(t0, t0.Add(time.Hour), "", "", "", "", "", tt.statuses, 20, 0)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		if ids(events) != tt.want || total != int64(len(events)) {
+			t.Errorf("FindChainEventsByStatus(%v) = %s total %d, want %s", tt.statuses, ids(events), total, tt.want)
+		}
+	}
+	if _, _, err := r.FindChainEventsByStatus(t0, t0.Add(time.Hour), "", "", "", "", "", []string{"closed"}, 20, 0); !errors.Is(err, eventstate.ErrInvalid) {
+		t.Errorf("FindChainEventsByStatus() with unknown status error = %v, want %v", err, eventstate.ErrInvalid)
+	}
+}
+
+func TestEventRepo_Transition(t *testing.T) {
+	ctx := context.Background()
+	r := eventstate.New(&fakeEventRepo{stored: dbEvents(3)}, mem.New())
+	assign := domain.EventTransition{Action: eventstate.ActionAssign, Actor: "op", Assignee: "ivanov"}
+	if _, err := r.Transition(ctx, "2", time.Time{}, assign); !errors.Is(err, eventstate.ErrNotFound) {
+		t.Errorf("Transition() without event_time error = %v, want %v", err, eventstate.ErrNotFound)
+	}
+	if _, err := r.Transition(ctx, "2", t0.Add(time.Hour), assign); !errors.Is(err, eventstate.ErrNotFound) {
+		t.Errorf("Transition() with wrong event_time error = %v, want %v", err, eventstate.ErrNotFound)
+	}
+	e, err := r.Transition(ctx, "2", t0.Add(2*time.Minute), assign)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if e.Status() != domain.EventStatusAssigned || e.State.Assignee != "ivanov" || e.Change == nil || e.Key == "" {
+		t.Errorf("Transition() = %+v, want assigned snapshot with change", e)
+	}
+	// stored event is found without event_time
+	resolve := domain.EventTransition{Action: eventstate.ActionResolve, Actor: "ivanov"}
+	if e, err = r.Transition(ctx, "2", time.Time{}, resolve); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if e.Status() != domain.EventStatusResolved || len(e.State.History) != 2 {
+		t.Errorf("Transition() = %+v, want resolved with 2 transitions", e.State)
+	}
+	if _, err := r.Transition(ctx, "2", time.Time{}, resolve); !errors.Is(err, eventstate.ErrTransition) {
+		t.Errorf("Transition() of resolved error = %v, want %v", err, eventstate.ErrTransition)
+	}
+}
+
+func TestEventRepo_FindConsumerChainEvents(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeEventRepo{stored: dbEvents(2), live: make(chan domain.Event)}
+	r := eventstate.New(db, mem.New())
+	cancel := make(chan struct{})
+	defer close(cancel)
+	ch := r.FindConsumerChainEvents("test", "10", "", "", "", "", t0, cancel)
+	other := r.FindConsumerChainEvents("other", "20", "", "", "", "", t0, cancel)
+
+	if _, err := r.Transition(ctx, "1", t0.Add(time.Minute),
+		domain.EventTransition{Action: eventstate.ActionAcknowledge, Actor: "op"}); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if e := <-ch; e.ID != "1" || e.Change == nil || e.Change.Action != eventstate.ActionAcknowledge {
+		t.Errorf("got %+v, want change of the event 1", e)
+	}
+	// live event of the database gets its stored state
+	db.live <- domain.Event{ID: "1", EventTime: t0.Add(time.Minute), LayoutID: "10"}
+	if e := <-ch; e.Change != nil || e.Status() != domain.EventStatusAcknowledged {
+		t.Errorf("got %+v, want live event with acknowledged state", e)
+	}
+	select {
+	case e := <-other:
+		t.Errorf("consumer of the layout 20 got %+v", e)
+	default:
+	}
+}
@@ -0,0 +1,73 @@
+// Package mem implements eventstate.RepoInterface in the process memory,
+// events and states are lost on restart of the service.
+package mem
+
+import (
+	"context"
+	"sync"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstate"
+)
+
+// Repo memory storage of the events with state.
+type Repo struct {
+	mu     sync.RWMutex
+	events map[string]domain.Event
+}
+
+// New builder for Repo.
+func New() *Repo {
+	return &Repo{events: make(map[string]domain.Event)}
+}
+
+// Get returns event by id, if not exists returns eventstate.ErrNotFound.
+func (r *Repo) Get(ctx context.Context, id string) (*domain.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.events[id]
+	if !ok {
+		return nil, eventstate.ErrNotFound
+	}
+	return &e, nil
+}
+
+// Add adds event, if exists returns eventstate.ErrExists.
+func (r *Repo) Add(ctx context.Context, e *domain.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.events[e.ID]; ok {
+		return eventstate.ErrExists
+	}
+	r.events[e.ID] = *e
+	return nil
+}
+
+// Update changes event by fn, if not exists returns eventstate.ErrNotFound.
+func (r *Repo) Update(ctx context.Context, id string, fn func(e *domain.Event) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.events[id]
+	if !ok {
+		return eventstate.ErrNotFound
+	}
+	if err := fn(&e); err != nil {
+		return err
+	}
+	r.events[id] = e
+	return nil
+}
+
+// Find returns events matching query ordered by event_time descending.
+func (r *Repo) Find(ctx context.Context, q eventstate.Query) (domain.Events, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make(domain.Events, 0)
+	for _, e := range r.events {
+		if q.Match(e) {
+			res = append(res, e)
+		}
+	}
+	eventstate.SortDesc(res)
+	return res, nil
+}
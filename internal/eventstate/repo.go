@@ -0,0 +1,298 @@
+package eventstate
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+)
+
+const (
+	findPageSize int64 = 200
+	// maxScanEvents limits events of the database scanned by the filter with new status
+	maxScanEvents int64 = 10000
+	// lookupWindow time range around event_time to find the database event by id
+	lookupWindow   time.Duration = time.Second
+	consumerBuffer int           = 64
+)
+
+// ErrScanLimit status filter needs to scan too many events of the database.
+var ErrScanLimit = errors.New("too many events to filter by status, narrow from - to")
+
+// EventRepo domain.IEventRepo with states of the events and events created through API,
+// created events and changes of the states are sent to the consumers of the events.
+type EventRepo struct {
+	next  domain.IEventRepo
+	store RepoInterface
+	// tmu serializes transitions, the first transition of the database event adds its snapshot
+	tmu       sync.Mutex
+	mu        sync.RWMutex
+	consumers map[chan domain.Event]Query
+}
+
+var _ domain.IEventRepo = (*EventRepo)(nil)
+
+// New builder for EventRepo, next is the events database.
+func New(next domain.IEventRepo, store RepoInterface) *EventRepo {
+	return &EventRepo{next: next, store: store, consumers: make(map[chan domain.Event]Query)}
+}
+
+// FindChainEvents returns events of the database and created ones ordered by event_time descending,
+// events have their states.
+func (r *EventRepo) FindChainEvents(from, to time.Time, layoutID, storeID, key, kind, severity string,
+	limit, offset int64) (domain.Events, int64, error) {
+	//
+	created, err := r.store.Find(context.Background(), Query{From: from, To: to, LayoutID: layoutID,
+		StoreID: storeID, Key: key, Kind: kind, Severity: severity, CreatedOnly: true})
+	if err != nil {
+		return nil, 0, errors.WithMessage(err, "find created events failed")
+	}
+	if len(created) == 0 {
+		events, total, err := r.next.FindChainEvents(from, to, layoutID, storeID, key, kind, severity, limit, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		return r.withStates(events), total, nil
+	}
+	// page of the merged events is within the first offset+limit events of the both sources
+	events, total, err := r.next.FindChainEvents(from, to, layoutID, storeID, key, kind, severity, offset+limit, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	merged := append(r.withStates(events), created...)
+	SortDesc(merged)
+	return page(merged, limit, offset), total + int64(len(created)), nil
+}
+
+// FindChainEventsByStatus same as FindChainEvents with filter by statuses of the lifecycle,
+// filter with new status scans events of the database, at most maxScanEvents.
+func (r *EventRepo) FindChainEventsByStatus(from, to time.Time, layoutID, storeID, key, kind, severity string,
+	statuses []string, limit, offset int64) (domain.Events, int64, error) {
+	//
+	if len(statuses) == 0 {
+		return r.FindChainEvents(from, to, layoutID, storeID, key, kind, severity, limit, offset)
+	}
+	if err := ValidateStatuses(statuses); err != nil {
+		return nil, 0, err
+	}
+	q := Query{From: from, To: to, LayoutID: layoutID, StoreID: storeID, Key: key, Kind: kind,
+		Severity: severity, Statuses: statuses}
+	if !MatchStatus(statuses, domain.Event{}) {
+		// events not in the new status are stored all
+		events, err := r.store.Find(context.Background(), q)
+		if err != nil {
+			return nil, 0, errors.WithMessage(err, "find events failed")
+		}
+		return page(events, limit, offset), int64(len(events)), nil
+	}
+	matched := make(domain.Events, 0)
+	for off := int64(0); ; off += findPageSize {
+		if off >= maxScanEvents {
+			return nil, 0, ErrScanLimit
+		}
+		events, total, err := r.next.FindChainEvents(from, to, layoutID, storeID, key, kind, severity, findPageSize, off)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, e := range r.withStates(events) {
+			if MatchStatus(statuses, e) {
+				matched = append(matched, e)
+			}
+		}
+		if len(events) == 0 || off+int64(len(events)) >= total {
+			break
+		}
+	}
+	q.CreatedOnly = true
+	created, err := r.store.Find(context.Background(), q)
+	if err != nil {
+		return nil, 0, errors.WithMessage(err, "find created events failed")
+	}
+	matched = append(matched, created...)
+	SortDesc(matched)
+	return page(matched, limit, offset), int64(len(matched)), nil
+}
+
+// FindConsumerChainEvents returns events of the database with their states merged with created events
+// and changes of the states, slow consumer misses changes of the states.
+func (r *EventRepo) FindConsumerChainEvents(subscriberID, layoutID, storeID, key, kind, severity string,
+	from time.Time, cancel <-chan struct{}) chan domain.Event {
+	//
+	in := r.next.FindConsumerChainEvents(subscriberID, layoutID, storeID, key, kind, severity, from, cancel)
+	changes := make(chan domain.Event, consumerBuffer)
+	r.mu.Lock()
+	r.consumers[changes] = Query{LayoutID: layoutID, StoreID: storeID, Key: key, Kind: kind, Severity: severity}
+	r.mu.Unlock()
+	out := make(chan domain.Event)
+	go func() {
+		defer close(out)
+		defer func() {
+			r.mu.Lock()
+			delete(r.consumers, changes)
+			r.mu.Unlock()
+		}()
+		for {
+			var e domain.Event
+			select {
+			case <-cancel:
+				return
+			case ev, more := <-in:
+				if !more {
+					return
+				}
+				e = r.withState(ev)
+			case e = <-changes:
+			}
+			select {
+			case <-cancel:
+				return
+			case out <- e:
+			}
+		}
+	}()
+	return out
+}
+
+// Get returns stored event by id, if not exists returns ErrNotFound.
+func (r *EventRepo) Get(ctx context.Context, id string) (*domain.Event, error) {
+	return r.store.Get(ctx, id)
+}
+
+// Find returns stored event by id or the database event found by id near at,
+// if not exists returns ErrNotFound.
+func (r *EventRepo) Find(ctx context.Context, id string, at time.Time) (domain.Event, error) {
+	e, err := r.store.Get(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return r.lookup(id, at)
+	}
+	if err != nil {
+		return domain.Event{}, err
+	}
+	return *e, nil
+}
+
+// FindCreated returns events created through API matching query.
+func (r *EventRepo) FindCreated(ctx context.Context, q Query) (domain.Events, error) {
+	q.CreatedOnly = true
+	return r.store.Find(ctx, q)
+}
+
+// Create adds event created by actor, returns it with id and state.
+func (r *EventRepo) Create(ctx context.Context, e domain.Event, actor string) (domain.Event, error) {
+	e, err := NewEvent(e, actor, time.Now())
+	if err != nil {
+		return e, err
+	}
+	stored := e
+	stored.Change = nil
+	if err := r.store.Add(ctx, &stored); err != nil {
+		return e, err
+	}
+	r.notify(e)
+	return e, nil
+}
+
+// Transition applies transition to the event, the database event is found by id near at,
+// returns event with the new state.
+func (r *EventRepo) Transition(ctx context.Context, id string, at time.Time,
+	t domain.EventTransition) (domain.Event, error) {
+	//
+	if t.Time.IsZero() {
+		t.Time = time.Now()
+	}
+	r.tmu.Lock()
+	defer r.tmu.Unlock()
+	var res domain.Event
+	err := r.store.Update(ctx, id, func(e *domain.Event) error {
+		if err := Apply(e, t); err != nil {
+			return err
+		}
+		res = *e
+		return nil
+	})
+	if errors.Is(err, ErrNotFound) {
+		e, err := r.lookup(id, at)
+		if err != nil {
+			return res, err
+		}
+		if err := Apply(&e, t); err != nil {
+			return res, err
+		}
+		if err := r.store.Add(ctx, &e); err != nil {
+			return res, err
+		}
+		res = e
+	} else if err != nil {
+		return res, err
+	}
+	res.Change = &t
+	r.notify(res)
+	return res, nil
+}
+
+// lookup finds event of the database by id and its time.
+func (r *EventRepo) lookup(id string, at time.Time) (domain.Event, error) {
+	if at.IsZero() {
+		return domain.Event{}, errors.Wrapf(ErrNotFound, "event %s not stored, event_time is required to find it", id)
+	}
+	from, to := at.Add(-lookupWindow), at.Add(lookupWindow)
+	for off := int64(0); off < maxScanEvents; off += findPageSize {
+		events, total, err := r.next.FindChainEvents(from, to, "", "", "", "", "", findPageSize, off)
+		if err != nil {
+			return domain.Event{}, errors.WithMessage(err, "find event failed")
+		}
+		for _, e := range events {
+			if e.ID == id {
+				return e, nil
+			}
+		}
+		if len(events) == 0 || off+int64(len(events)) >= total {
+			break
+		}
+	}
+	return domain.Event{}, errors.Wrapf(ErrNotFound, "event %s at %s", id, at.Format(time.RFC3339Nano))
+}
+
+// notify sends event to the consumers with matching filters without waiting.
+func (r *EventRepo) notify(e domain.Event) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for ch, q := range r.consumers {
+		if !q.Match(e) {
+			continue
+		}
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// withState returns event with its stored state.
+func (r *EventRepo) withState(e domain.Event) domain.Event {
+	stored, err := r.store.Get(context.Background(), e.ID)
+	if err == nil {
+		e.State = stored.State
+	}
+	return e
+}
+
+func (r *EventRepo) withStates(events domain.Events) domain.Events {
+	for i := range events {
+		events[i] = r.withState(events[i])
+	}
+	return events
+}
+
+func page(events domain.Events, limit, offset int64) domain.Events {
+	if offset >= int64(len(events)) {
+		return domain.Events{}
+	}
+	if limit > 0 && offset+limit < int64(len(events)) {
+		return events[offset : offset+limit]
+	}
+	return events[offset:]
+}
@@ -0,0 +1,233 @@
+// Package eventstats aggregates events by dimensions: key, kind, severity, layout, store,
+// param of the source and time bucket, with count, first and last times and mean time to resolve.
+package eventstats
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstate"
+)
+
+// dimensions of the grouping
+const (
+	DimKey      string = "key"
+	DimKind     string = "kind"
+	DimSeverity string = "severity"
+	DimLayout   string = "layout"
+	DimStore    string = "store"
+	DimTime     string = "time"
+	// DimParamPrefix prefix of the dimension by param of the source, e.g. param:device_id
+	DimParamPrefix string = "param:"
+)
+
+// time buckets
+const (
+	BucketHour  string = "hour"
+	BucketDay   string = "day"
+	BucketWeek  string = "week"
+	BucketMonth string = "month"
+)
+
+// sort orders of the groups
+const (
+	SortCount string = "count"
+	SortGroup string = "group"
+	SortMTTR  string = "mttr"
+)
+
+var (
+	// ErrInvalid query malformed.
+	ErrInvalid = errors.New("invalid stats query")
+)
+
+var (
+	dimensions = []string{DimKey, DimKind, DimSeverity, DimLayout, DimStore, DimTime, DimParamPrefix + "<name>"}
+	buckets    = []string{BucketHour, BucketDay, BucketWeek, BucketMonth}
+	sorts      = []string{SortCount, SortGroup, SortMTTR}
+)
+
+// Query of the aggregation.
+type Query struct {
+	GroupBy []string
+	// Bucket size of the time dimension, default day
+	Bucket string
+	// Loc location of the time buckets, default local
+	Loc *time.Location
+	// Sort order of the groups: count descending (default), group ascending, mttr descending
+	Sort string
+}
+
+// Group aggregates of the events with the same values of the dimensions.
+type Group struct {
+	Group    map[string]string `json:"group"`
+	Count    int64             `json:"count"`
+	First    time.Time         `json:"first"`
+	Last     time.Time         `json:"last"`
+	Resolved int64             `json:"resolved"`
+	// MTTR mean time to resolve of the resolved events in seconds
+	MTTR *float64 `json:"mttr_seconds,omitempty"`
+
+	ttr time.Duration
+	key string
+}
+
+// Validate checks dimensions, bucket and sort of the query.
+func (q Query) Validate() error {
+	seen := make(map[string]bool, len(q.GroupBy))
+	for _, d := range q.GroupBy {
+		switch {
+		case d == DimKey, d == DimKind, d == DimSeverity, d == DimLayout, d == DimStore, d == DimTime:
+		case strings.HasPrefix(d, DimParamPrefix) && len(d) > len(DimParamPrefix):
+		default:
+			return errors.Wrapf(ErrInvalid, "unknown group_by %q, allowed %v", d, dimensions)
+		}
+		if seen[d] {
+			return errors.Wrapf(ErrInvalid, "group_by %s repeated", d)
+		}
+		seen[d] = true
+	}
+	if q.Bucket != "" && !contains(buckets, q.Bucket) {
+		return errors.Wrapf(ErrInvalid, "unknown bucket %q, allowed %v", q.Bucket, buckets)
+	}
+	if q.Sort != "" && !contains(sorts, q.Sort) {
+		return errors.Wrapf(ErrInvalid, "unknown sort %q, allowed %v", q.Sort, sorts)
+	}
+	return nil
+}
+
+// Aggregate groups events by dimensions of the query, without dimensions returns single group of the all events.
+func Aggregate(events domain.Events, q Query) ([]*Group, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	if q.Loc == nil {
+		q.Loc = time.Local
+	}
+	groups := make(map[string]*Group)
+	var res []*Group
+	for _, e := range events {
+		values := make([]string, len(q.GroupBy))
+		for i, d := range q.GroupBy {
+			values[i] = q.value(e, d)
+		}
+		key := strings.Join(values, "\x00")
+		g, ok := groups[key]
+		if !ok {
+			g = &Group{Group: make(map[string]string, len(q.GroupBy)), First: e.EventTime, Last: e.EventTime, key: key}
+			for i, d := range q.GroupBy {
+				g.Group[d] = values[i]
+			}
+			groups[key] = g
+			res = append(res, g)
+		}
+		g.add(e)
+	}
+	for _, g := range res {
+		if g.Resolved > 0 {
+			mttr := (g.ttr / time.Duration(g.Resolved)).Seconds()
+			g.MTTR = &mttr
+		}
+	}
+	sortGroups(res, q.Sort)
+	return res, nil
+}
+
+func (g *Group) add(e domain.Event) {
+	g.Count++
+	if e.EventTime.Before(g.First) {
+		g.First = e.EventTime
+	}
+	if e.EventTime.After(g.Last) {
+		g.Last = e.EventTime
+	}
+	if at, ok := ResolvedAt(e); ok && !at.Before(e.EventTime) {
+		g.Resolved++
+		g.ttr += at.Sub(e.EventTime)
+	}
+}
+
+// ResolvedAt returns time of the last resolve of the resolved event.
+func ResolvedAt(e domain.Event) (time.Time, bool) {
+	if e.Status() != domain.EventStatusResolved {
+		return time.Time{}, false
+	}
+	for i := len(e.State.History) - 1; i >= 0; i-- {
+		if e.State.History[i].Action == eventstate.ActionResolve {
+			return e.State.History[i].Time, true
+		}
+	}
+	return e.State.UpdatedAt, true
+}
+
+// value returns value of the dimension of the event.
+func (q Query) value(e domain.Event, d string) string {
+	switch d {
+	case DimKey:
+		return e.Key
+	case DimKind:
+		return e.Kind
+	case DimSeverity:
+		return e.Severity
+	case DimLayout:
+		return e.LayoutID
+	case DimStore:
+		return e.StoreID
+	case DimTime:
+		return Bucket(e.EventTime.In(q.Loc), q.Bucket).Format(time.RFC3339)
+	}
+	if e.Source == nil {
+		return ""
+	}
+	return e.Source.GetParamByName(strings.TrimPrefix(d, DimParamPrefix))
+}
+
+// Bucket returns begin of the bucket of t in location of t, week begins on monday, empty bucket - day.
+func Bucket(t time.Time, bucket string) time.Time {
+	y, m, d := t.Date()
+	switch bucket {
+	case BucketHour:
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+	case BucketWeek:
+		shift := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-shift, 0, 0, 0, 0, t.Location())
+	case BucketMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func sortGroups(gs []*Group, order string) {
+	sort.SliceStable(gs, func(i, j int) bool {
+		a, b := gs[i], gs[j]
+		switch order {
+		case SortGroup:
+			return a.key < b.key
+		case SortMTTR:
+			if (a.MTTR == nil) != (b.MTTR == nil) {
+				return a.MTTR != nil
+			}
+			if a.MTTR != nil && *a.MTTR != *b.MTTR {
+				return *a.MTTR > *b.MTTR
+			}
+		default:
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+		}
+		return a.key < b.key
+	})
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package eventstats_test
+
+import (
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstats"
+)
+
+// monday
+var t0 = time.Date(2021, 6, 7, 12, 0, 0, 0, time.UTC)
+
+func event(id, store, device string, d time.Duration, resolvedAfter time.Duration) domain.Event {
+	e := domain.Event{ID: id, Key: "queue.threshold.exceeded", Severity: "alarm", LayoutID: "10", StoreID: store,
+		EventTime: t0.Add(d), Source: &domain.Source{Params: []domain.Param{{Name: "device_id", Value: device}}}}
+	if resolvedAfter > 0 {
+		at := e.EventTime.Add(resolvedAfter)
+		e.State = &domain.EventState{Status: domain.EventStatusResolved, UpdatedAt: at,
+			History: []domain.EventTransition{{Action: "acknowledge", Time: e.EventTime}, {Action: "resolve", Time: at}}}
+	}
+	return e
+}
+
+var events = domain.Events{
+	event("1", "s1", "d1", 0, 10*time.Minute),
+	event("2", "s1", "d1", 24*time.Hour, 20*time.Minute),
+	event("3", "s1", "d2", 8*24*time.Hour, 0),
+	event("4", "s2", "d3", time.Hour, 0),
+}
+
+func TestAggregate(t *testing.T) {
+	groups, err := eventstats.Aggregate(events, eventstats.Query{GroupBy: []string{"store", "time"}, Bucket: "week", Loc: time.UTC})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if len(groups) != 3 {
+		t.Fatalf("Aggregate() = %d groups, want 3", len(groups))
+	}
+	g := groups[0]
+	if g.Group["store"] != "s1" || g.Group["time"] != "2021-06-07T00:00:00Z" || g.Count != 2 || g.Resolved != 2 ||
+		g.MTTR == nil || *g.MTTR != 900 || !g.First.Equal(t0) || !g.Last.Equal(t0.Add(24*time.Hour)) {
+		t.Errorf("the first group %+v, want 2 events of s1 in the week of 2021-06-07 with mttr 15m", g)
+	}
+	// groups with equal count are ordered by values
+	if groups[1].Group["time"] != "2021-06-14T00:00:00Z" || groups[2].Group["store"] != "s2" {
+		t.Errorf("groups %+v, %+v, want s1 of the next week, then s2", groups[1], groups[2])
+	}
+
+	top, err := eventstats.Aggregate(events, eventstats.Query{GroupBy: []string{"param:device_id"}, Sort: "mttr"})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if len(top) != 3 || top[0].Group["param:device_id"] != "d1" || top[0].Count != 2 || top[1].MTTR != nil {
+		t.Errorf("groups by device %+v, want d1 with mttr first", top)
+	}
+
+	all, err := eventstats.Aggregate(events, eventstats.Query{})
+	if err != nil || len(all) != 1 || all[0].Count != 4 || len(all[0].Group) != 0 {
+		t.Errorf("Aggregate() without group_by = %+v, %v, want single group of 4 events", all, err)
+	}
+
+	for _, q := range []eventstats.Query{{GroupBy: []string{"device"}}, {GroupBy: []string{"param:"}},
+		{GroupBy: []string{"key", "key"}}, {Bucket: "year"}, {Sort: "noise"}} {
+		if _, err := eventstats.Aggregate(events, q); err == nil {
+			t.Errorf("Aggregate(%+v) without error", q)
+		}
+	}
+}
+
+func TestBucket(t *testing.T) {
+	sunday := time.Date(2021, 6, 13, 23, 30, 0, 0, time.UTC)
+	tests := map[string]string{
+		"hour":  "2021-06-13T23:00:00Z",
+		"day":   "2021-06-13T00:00:00Z",
+		"week":  "2021-06-07T00:00:00Z",
+		"month": "2021-06-01T00:00:00Z",
+	}
+	for bucket, want := range tests {
+		if got := eventstats.Bucket(sunday, bucket).Format(time.RFC3339); got != want {
+			t.Errorf("Bucket(%s) = %s, want %s", bucket, got, want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package fakecommonapi
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Command name of the subcommand of the layoutconfig.api.
+const Command = "fake-commonapi"
+
+// Main runs fake commonapi until ctx done, args are flags of the subcommand.
+func Main(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet(Command, flag.ContinueOnError)
+	addr := fs.String("addr", ":7001", "host:port of the fake commonapi")
+	path := fs.String("config", "fake-commonapi.yaml", "yaml file with token, projects and failures")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := LoadConfig(*path)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Addr: *addr, Handler: New(cfg)}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+	fmt.Printf("fake commonapi with %d projects listens on %s\n", len(cfg.Projects), *addr)
+	select {
+	case err := <-errs:
+		return errors.Wrap(err, "listen failed")
+	case <-ctx.Done():
+	}
+	sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(sctx)
+}
@@ -0,0 +1,189 @@
+// Package fakecommonapi local stand-in of the commonapi for development and tests,
+// serves projects (/v2/projects/:id) and health check (/health) with token auth,
+// failures of the requests can be injected.
+package fakecommonapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/commonapiclient"
+)
+
+// AnyProject matches requests of the all projects and health check in Failure.
+const AnyProject = "*"
+
+// Failure injected failure of the requests.
+type Failure struct {
+	Project string        `mapstructure:"project" json:"project"` // project id, AnyProject - all requests
+	Status  int           `mapstructure:"status" json:"status"`   // http status of the response, 0 - only delay
+	Times   int           `mapstructure:"times" json:"times"`     // count of the failed requests, 0 - forever
+	Delay   time.Duration `mapstructure:"delay" json:"delay"`     // delay of the response
+}
+
+// Config content of the file with projects.
+type Config struct {
+	Token    string                    `mapstructure:"token"`
+	Projects []commonapiclient.Project `mapstructure:"projects"`
+	Failures []Failure                 `mapstructure:"failures"`
+}
+
+// Server fake commonapi, implements http.Handler.
+type Server struct {
+	mu       sync.Mutex
+	token    string
+	projects map[string]commonapiclient.Project
+	failures []*Failure
+	requests map[string]int
+}
+
+// New builder for Server, empty token disables auth.
+func New(cfg Config) *Server {
+	s := &Server{
+		token:    cfg.Token,
+		projects: make(map[string]commonapiclient.Project, len(cfg.Projects)),
+		requests: make(map[string]int),
+	}
+	for _, p := range cfg.Projects {
+		s.SetProject(p)
+	}
+	for _, f := range cfg.Failures {
+		s.Fail(f)
+	}
+	return s
+}
+
+// LoadConfig reads projects, token and failures from the yaml file.
+func LoadConfig(path string) (Config, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return Config{}, errors.Wrapf(err, "read %s failed", path)
+	}
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return Config{}, errors.Wrapf(err, "parse %s failed", path)
+	}
+	return cfg, nil
+}
+
+// SetProject adds or replaces project.
+func (s *Server) SetProject(p commonapiclient.Project) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.projects[projectID(p)] = p
+}
+
+// DelProject removes project, it responds 404.
+func (s *Server) DelProject(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.projects, id)
+}
+
+// Fail injects failure, failures are applied in order of the injection.
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &f)
+}
+
+// Recover removes all injected failures.
+func (s *Server) Recover() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// Requests returns count of the requests of the project, AnyProject - health checks.
+func (s *Server) Requests(id string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[id]
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, errResponse{Message: "method not allowed"})
+		return
+	}
+	id := AnyProject
+	switch {
+	case r.URL.Path == "/health":
+	case strings.HasPrefix(r.URL.Path, "/v2/projects/"):
+		id = strings.TrimPrefix(r.URL.Path, "/v2/projects/")
+	default:
+		writeJSON(w, http.StatusNotFound, errResponse{Message: "not found"})
+		return
+	}
+	status, delay := s.failure(id)
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if status != 0 {
+		writeJSON(w, status, errResponse{Message: "injected failure"})
+		return
+	}
+	if id == AnyProject {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		return
+	}
+	if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+		writeJSON(w, http.StatusUnauthorized, errResponse{Message: "invalid token"})
+		return
+	}
+	s.mu.Lock()
+	p, ok := s.projects[id]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errResponse{Message: "project " + id + " not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// failure counts request and returns status and delay of the first matching failure.
+func (s *Server) failure(id string) (int, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[id]++
+	for i, f := range s.failures {
+		if f.Project != AnyProject && f.Project != id {
+			continue
+		}
+		if f.Times > 0 {
+			if f.Times--; f.Times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+		return f.Status, f.Delay
+	}
+	return 0, 0
+}
+
+type errResponse struct {
+	Message string `json:"message"`
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func projectID(p commonapiclient.Project) string {
+	return strconv.FormatInt(p.ID, 10)
+}
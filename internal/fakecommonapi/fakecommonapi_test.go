@@ -0,0 +1,68 @@
+package fakecommonapi_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/commonapiclient"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/fakecommonapi"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := fakecommonapi.LoadConfig("testdata/projects.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if len(cfg.Projects) != 2 || cfg.Projects[1].DBName != "retail" || cfg.Projects[1].TypeName != "CountMax PostgreSQL" {
+		t.Errorf("LoadConfig().Projects = %+v", cfg.Projects)
+	}
+	if len(cfg.Failures) != 1 || cfg.Failures[0].Status != 503 || cfg.Failures[0].Times != 2 {
+		t.Errorf("LoadConfig().Failures = %+v", cfg.Failures)
+	}
+}
+
+func TestServer(t *testing.T) {
+	cfg, err := fakecommonapi.LoadConfig("testdata/projects.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	fake := fakecommonapi.New(cfg)
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	ctx := context.Background()
+	api, err := commonapiclient.New(commonapiclient.Config{
+		URL: srv.URL, Token: cfg.Token, Timeout: time.Second, RetryWait: time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if err := api.Health(ctx); err != nil {
+		t.Fatalf("API.Health() unexpected error, %s", err)
+	}
+	// two injected failures are retried
+	res := api.GetConnections(ctx, []string{"1000001", "1000002", "1"})
+	if res[0].Err != nil || res[1].Err != nil || res[2].Err == nil {
+		t.Fatalf("API.GetConnections() = %+v, want error only for absent project", res)
+	}
+	if n := fake.Requests("1000002"); n != 3 {
+		t.Errorf("Server.Requests(1000002) = %d, want 3", n)
+	}
+
+	bad, err := commonapiclient.New(commonapiclient.Config{URL: srv.URL, Token: "bad", Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if _, err := bad.GetConnection(ctx, "1000001"); err == nil {
+		t.Error("API.GetConnection() with bad token error = nil, want error")
+	}
+
+	fake.Fail(fakecommonapi.Failure{Project: fakecommonapi.AnyProject, Status: 502})
+	if err := api.Health(ctx); err == nil {
+		t.Error("API.Health() error = nil while commonapi is down")
+	}
+	fake.Recover()
+	if err := api.Health(ctx); err != nil {
+		t.Errorf("API.Health() after recover unexpected error, %s", err)
+	}
+}
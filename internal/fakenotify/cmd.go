@@ -0,0 +1,51 @@
+package fakenotify
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Command name of the subcommand of the layoutconfig.api.
+const Command = "fake-notify"
+
+// Main runs fake SMTP server and bot API until ctx done, args are flags of the subcommand.
+func Main(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet(Command, flag.ContinueOnError)
+	addr := fs.String("addr", ":7002", "host:port of the fake bot API and /messages")
+	smtpAddr := fs.String("smtp", ":2525", "host:port of the fake SMTP server")
+	token := fs.String("token", "", "token of the bot, empty - any")
+	username := fs.String("username", "", "SMTP user, empty - any")
+	password := fs.String("password", "", "SMTP password")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	fake := New(Config{Token: *token, Username: *username, Password: *password})
+	l, err := net.Listen("tcp", *smtpAddr)
+	if err != nil {
+		return errors.Wrap(err, "smtp listen failed")
+	}
+	defer l.Close()
+	srv := &http.Server{Addr: *addr, Handler: fake}
+	errs := make(chan error, 2)
+	go func() {
+		errs <- errors.Wrap(fake.ServeSMTP(l), "smtp serve failed")
+	}()
+	go func() {
+		errs <- errors.Wrap(srv.ListenAndServe(), "listen failed")
+	}()
+	fmt.Printf("fake notify smtp listens on %s, bot api and /messages on %s\n", *smtpAddr, *addr)
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(sctx)
+}
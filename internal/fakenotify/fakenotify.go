@@ -0,0 +1,304 @@
+// Package fakenotify local stand-in of the SMTP server and messenger bot API for development and tests,
+// received mails and bot messages are kept in memory and listed by /messages,
+// failures of the bot API can be injected.
+package fakenotify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const hostname string = "fake-notify"
+
+// Config credentials of the fake services, empty values disable auth.
+type Config struct {
+	Token    string // token of the bot
+	Username string // SMTP user
+	Password string // SMTP password
+}
+
+// Mail message received by SMTP.
+type Mail struct {
+	From    string   `json:"from"`
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`
+	Body    string   `json:"body"`
+}
+
+// BotMessage message received by bot API method sendMessage.
+type BotMessage struct {
+	ChatID string `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+// Messages received mails and bot messages.
+type Messages struct {
+	Mails []Mail       `json:"mails"`
+	Bot   []BotMessage `json:"bot"`
+}
+
+// Server fake SMTP server and bot API, implements http.Handler for the bot API.
+type Server struct {
+	cfg      Config
+	mu       sync.Mutex
+	messages Messages
+	// botFailures count of the next failed sendMessage
+	botFailures int
+}
+
+// New builder for Server.
+func New(cfg Config) *Server {
+	return &Server{cfg: cfg}
+}
+
+// Messages returns copy of the received messages.
+func (s *Server) Messages() Messages {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Messages{
+		Mails: append([]Mail(nil), s.messages.Mails...),
+		Bot:   append([]BotMessage(nil), s.messages.Bot...),
+	}
+}
+
+// Reset removes received messages and injected failures.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = Messages{}
+	s.botFailures = 0
+}
+
+// FailBot injects failure of the next times calls of sendMessage.
+func (s *Server) FailBot(times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.botFailures = times
+}
+
+// ServeHTTP implements http.Handler: /bot<token>/sendMessage, /bot<token>/getMe, /messages.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/messages" {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, s.Messages())
+		case http.MethodDelete:
+			s.Reset()
+			writeJSON(w, http.StatusOK, botResponse{OK: true})
+		default:
+			writeJSON(w, http.StatusMethodNotAllowed, botResponse{Description: "method not allowed"})
+		}
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/bot") {
+		writeJSON(w, http.StatusNotFound, botResponse{Description: "Not Found"})
+		return
+	}
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/bot"), "/", 2)
+	if len(parts) != 2 || (s.cfg.Token != "" && parts[0] != s.cfg.Token) {
+		writeJSON(w, http.StatusUnauthorized, botResponse{Description: "Unauthorized"})
+		return
+	}
+	switch parts[1] {
+	case "getMe":
+		writeJSON(w, http.StatusOK, botResponse{OK: true, Result: map[string]interface{}{
+			"id": 1, "is_bot": true, "username": "fake_notify_bot"}})
+	case "sendMessage":
+		s.sendMessage(w, r)
+	default:
+		writeJSON(w, http.StatusNotFound, botResponse{Description: "Not Found: method not found"})
+	}
+}
+
+type botResponse struct {
+	OK          bool        `json:"ok"`
+	Description string      `json:"description,omitempty"`
+	Result      interface{} `json:"result,omitempty"`
+}
+
+func (s *Server) sendMessage(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ChatID json.RawMessage `json:"chat_id"`
+		Text   string          `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.ChatID) == 0 || req.Text == "" {
+		writeJSON(w, http.StatusBadRequest, botResponse{Description: "Bad Request: chat_id and text are required"})
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.botFailures > 0 {
+		s.botFailures--
+		writeJSON(w, http.StatusTooManyRequests, botResponse{Description: "Too Many Requests: injected failure"})
+		return
+	}
+	// chat_id is number or string
+	chatID := strings.Trim(string(req.ChatID), `"`)
+	s.messages.Bot = append(s.messages.Bot, BotMessage{ChatID: chatID, Text: req.Text})
+	writeJSON(w, http.StatusOK, botResponse{OK: true, Result: map[string]interface{}{
+		"message_id": len(s.messages.Bot), "text": req.Text}})
+}
+
+// ServeSMTP accepts SMTP connections until listener is closed.
+func (s *Server) ServeSMTP(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.session(conn)
+	}
+}
+
+// session serves commands HELO, EHLO, AUTH PLAIN, MAIL, RCPT, DATA, RSET, NOOP, QUIT.
+func (s *Server) session(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	reply := func(format string, args ...interface{}) bool {
+		return tp.PrintfLine(format, args...) == nil
+	}
+	var m Mail
+	reply("220 %s ESMTP", hostname)
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg := line, ""
+		if i := strings.IndexByte(line, ' '); i > 0 {
+			cmd, arg = line[:i], strings.TrimSpace(line[i+1:])
+		}
+		ok := true
+		switch strings.ToUpper(cmd) {
+		case "HELO":
+			ok = reply("250 %s", hostname)
+		case "EHLO":
+			ok = reply("250-%s", hostname) && reply("250 AUTH PLAIN")
+		case "AUTH":
+			ok = s.auth(tp, arg)
+		case "MAIL":
+			m = Mail{From: address(arg)}
+			ok = reply("250 OK")
+		case "RCPT":
+			m.To = append(m.To, address(arg))
+			ok = reply("250 OK")
+		case "DATA":
+			if len(m.To) == 0 {
+				ok = reply("503 RCPT first")
+				break
+			}
+			if !reply("354 end data with <CR><LF>.<CR><LF>") {
+				return
+			}
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			if err := parseMail(&m, data); err != nil {
+				ok = reply("554 %s", err)
+				break
+			}
+			s.mu.Lock()
+			s.messages.Mails = append(s.messages.Mails, m)
+			s.mu.Unlock()
+			m = Mail{}
+			ok = reply("250 OK queued")
+		case "RSET":
+			m = Mail{}
+			ok = reply("250 OK")
+		case "NOOP":
+			ok = reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			ok = reply("502 command not implemented")
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// auth checks AUTH PLAIN credentials, initial response may be sent in the next line.
+func (s *Server) auth(tp *textproto.Conn, arg string) bool {
+	fields := strings.Fields(arg)
+	if len(fields) == 0 || strings.ToUpper(fields[0]) != "PLAIN" {
+		return tp.PrintfLine("504 only PLAIN supported") == nil
+	}
+	resp := ""
+	if len(fields) > 1 {
+		resp = fields[1]
+	} else {
+		if tp.PrintfLine("334 ") != nil {
+			return false
+		}
+		var err error
+		if resp, err = tp.ReadLine(); err != nil {
+			return false
+		}
+	}
+	raw, err := base64.StdEncoding.DecodeString(resp)
+	creds := strings.Split(string(raw), "\x00")
+	if err != nil || len(creds) != 3 {
+		return tp.PrintfLine("501 malformed credentials") == nil
+	}
+	if s.cfg.Username != "" && (creds[1] != s.cfg.Username || creds[2] != s.cfg.Password) {
+		return tp.PrintfLine("535 authentication failed") == nil
+	}
+	return tp.PrintfLine("235 authenticated") == nil
+}
+
+// parseMail fills subject and decoded text body of the mail.
+func parseMail(m *Mail, data []byte) error {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "parse mail failed")
+	}
+	if m.Subject, err = new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); err != nil {
+		return errors.Wrap(err, "decode subject failed")
+	}
+	var body io.Reader = msg.Body
+	switch strings.ToLower(msg.Header.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return errors.Wrap(err, "decode body failed")
+	}
+	m.Body = string(b)
+	return nil
+}
+
+// address returns address of the argument like FROM:<user@host>.
+func address(arg string) string {
+	if i := strings.IndexByte(arg, ':'); i >= 0 {
+		arg = strings.TrimSpace(arg[i+1:])
+	}
+	if i := strings.IndexByte(arg, ' '); i >= 0 {
+		arg = arg[:i]
+	}
+	return strings.Trim(arg, "<>")
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
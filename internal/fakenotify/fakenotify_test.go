@@ -0,0 +1,71 @@
+package fakenotify_test
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/fakenotify"
+)
+
+func TestServer(t *testing.T) {
+	fake := fakenotify.New(fakenotify.Config{Token: "secret", Username: "user", Password: "pass"})
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	defer l.Close()
+	go func() { _ = fake.ServeSMTP(l) }()
+
+	msg := "Subject: =?utf-8?q?=D0=A2=D0=B5=D1=81=D1=82?=\r\nContent-Transfer-Encoding: base64\r\n\r\n0YLQtdC60YHRgg==\r\n"
+	if err := smtp.SendMail(l.Addr().String(), smtp.PlainAuth("", "user", "wrong", "127.0.0.1"), "a@local",
+		[]string{"b@local"}, []byte(msg)); err == nil {
+		t.Error("SendMail() with wrong password without error")
+	}
+	if err := smtp.SendMail(l.Addr().String(), smtp.PlainAuth("", "user", "pass", "127.0.0.1"), "a@local",
+		[]string{"b@local"}, []byte(msg)); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	send := func(token string) int {
+		resp, err := http.Post(srv.URL+"/bot"+token+"/sendMessage", "application/json",
+			strings.NewReader(`{"chat_id":100,"text":"hello"}`))
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := send("wrong"); code != http.StatusUnauthorized {
+		t.Errorf("sendMessage with wrong token status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	fake.FailBot(1)
+	if code := send("secret"); code != http.StatusTooManyRequests {
+		t.Errorf("sendMessage with injected failure status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+	if code := send("secret"); code != http.StatusOK {
+		t.Errorf("sendMessage status = %d, want %d", code, http.StatusOK)
+	}
+
+	resp, err := http.Get(srv.URL + "/messages")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	defer resp.Body.Close()
+	var msgs fakenotify.Messages
+	if err := json.NewDecoder(resp.Body).Decode(&msgs); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if len(msgs.Mails) != 1 || msgs.Mails[0].Subject != "Тест" || msgs.Mails[0].Body != "текст" || msgs.Mails[0].To[0] != "b@local" {
+		t.Errorf("mails %+v, want single decoded mail", msgs.Mails)
+	}
+	if len(msgs.Bot) != 1 || msgs.Bot[0].ChatID != "100" || msgs.Bot[0].Text != "hello" {
+		t.Errorf("bot messages %+v, want single message", msgs.Bot)
+	}
+}
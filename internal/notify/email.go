@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	base64Line     = 76
+)
+
+// EmailConfig settings of the SMTP server.
+type EmailConfig struct {
+	// Addr host:port of the SMTP server, empty - email disabled
+	Addr     string        `mapstructure:"addr"`
+	Username string        `mapstructure:"username"`
+	Password string        `mapstructure:"password"`
+	From     string        `mapstructure:"from"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+// Email sends messages by SMTP, STARTTLS is used if server supports it.
+type Email struct {
+	cfg  EmailConfig
+	host string
+}
+
+// NewEmail builder for Email.
+func NewEmail(cfg EmailConfig) *Email {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		host = cfg.Addr
+	}
+	return &Email{cfg: cfg, host: host}
+}
+
+// Name implements Channel.
+func (e *Email) Name() string { return ChannelEmail }
+
+// Address implements Channel.
+func (e *Email) Address(r Recipient) string { return r.Email }
+
+// Send implements Channel.
+func (e *Email) Send(ctx context.Context, to string, m Message) error {
+	deadline := time.Now().Add(e.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn, err := (&net.Dialer{Deadline: deadline}).DialContext(ctx, "tcp", e.cfg.Addr)
+	if err != nil {
+		return errors.Wrapf(err, "dial %s failed", e.cfg.Addr)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	c, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, "smtp hello failed")
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: e.host}); err != nil {
+			return errors.Wrap(err, "smtp starttls failed")
+		}
+	}
+	if e.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.host)); err != nil {
+			return errors.Wrap(err, "smtp auth failed")
+		}
+	}
+	if err := c.Mail(e.cfg.From); err != nil {
+		return errors.Wrap(err, "smtp mail failed")
+	}
+	if err := c.Rcpt(to); err != nil {
+		return errors.Wrapf(err, "smtp rcpt %s failed", to)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return errors.Wrap(err, "smtp data failed")
+	}
+	if _, err := w.Write(e.compose(to, m, time.Now())); err != nil {
+		return errors.Wrap(err, "smtp write failed")
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "smtp send failed")
+	}
+	return c.Quit()
+}
+
+// compose makes utf-8 plain text mail with base64 body.
+func (e *Email) compose(to string, m Message, now time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	body := base64.StdEncoding.EncodeToString([]byte(m.Body))
+	for len(body) > base64Line {
+		b.WriteString(body[:base64Line] + "\r\n")
+		body = body[base64Line:]
+	}
+	b.WriteString(body + "\r\n")
+	return b.Bytes()
+}
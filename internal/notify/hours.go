@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+)
+
+const (
+	day         = 24 * time.Hour
+	behaviorTTL = 5 * time.Minute
+)
+
+var clockLayouts = []string{"15:04:05", "15:04"}
+
+// Window daily interval of the time of day, From > To wraps midnight, From == To - whole day.
+type Window struct {
+	From time.Duration
+	To   time.Duration
+	Loc  *time.Location
+}
+
+// ParseWindow parses interval like 22:00-08:00 in the time zone, empty zone - local.
+func ParseWindow(s, zone string) (Window, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return Window{}, errors.Wrapf(ErrInvalid, "hours %q must be like 22:00-08:00", s)
+	}
+	return NewWindow(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), zone)
+}
+
+// NewWindow makes window from open to close clocks like 10:00:00 in the time zone, empty zone - local.
+func NewWindow(open, close, zone string) (Window, error) {
+	from, err := parseClock(open)
+	if err != nil {
+		return Window{}, err
+	}
+	to, err := parseClock(close)
+	if err != nil {
+		return Window{}, err
+	}
+	loc := time.Local
+	if zone != "" {
+		if loc, err = time.LoadLocation(zone); err != nil {
+			return Window{}, errors.Wrapf(ErrInvalid, "time zone %q, %s", zone, err)
+		}
+	}
+	return Window{From: from, To: to, Loc: loc}, nil
+}
+
+// Contains reports whether t is within window.
+func (w Window) Contains(t time.Time) bool {
+	if w.Loc != nil {
+		t = t.In(w.Loc)
+	}
+	d := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+	switch {
+	case w.From == w.To:
+		return true
+	case w.From < w.To:
+		return d >= w.From && d < w.To
+	default:
+		return d >= w.From || d < w.To
+	}
+}
+
+func parseClock(s string) (time.Duration, error) {
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+				time.Duration(t.Second())*time.Second, nil
+		}
+	}
+	return 0, errors.Wrapf(ErrInvalid, "time %q must be like 10:00 or 10:00:00", s)
+}
+
+// Repos finds repo of the layout.
+type Repos interface {
+	RepoByID(layoutID string) (domain.LayoutRepo, bool)
+}
+
+type hoursEntry struct {
+	window  *Window
+	expires time.Time
+}
+
+// businessHours caches business hours Open/Close of the behaviors of the layouts.
+type businessHours struct {
+	repos   Repos
+	mu      sync.Mutex
+	entries map[string]hoursEntry
+}
+
+func newBusinessHours(repos Repos) *businessHours {
+	return &businessHours{repos: repos, entries: make(map[string]hoursEntry)}
+}
+
+// window returns business hours of the layout, nil - layout works around the clock.
+func (b *businessHours) window(ctx context.Context, layoutID string, now time.Time) (*Window, error) {
+	b.mu.Lock()
+	e, ok := b.entries[layoutID]
+	b.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.window, nil
+	}
+	if b.repos == nil {
+		return nil, nil
+	}
+	repo, ok := b.repos.RepoByID(layoutID)
+	if !ok {
+		return nil, errors.Errorf("repo of the layout %s not found", layoutID)
+	}
+	bhv, err := repo.FindBehaviorByLayoutID(ctx, layoutID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "repo.FindBehaviorByLayoutID(%s) failed", layoutID)
+	}
+	var w *Window
+	if bhv != nil && bhv.Open != "" && bhv.Close != "" {
+		bw, err := NewWindow(bhv.Open, bhv.Close, bhv.TimeZone)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "behavior of the layout %s", layoutID)
+		}
+		w = &bw
+	}
+	b.mu.Lock()
+	b.entries[layoutID] = hoursEntry{window: w, expires: now.Add(behaviorTTL)}
+	b.mu.Unlock()
+	return w, nil
+}
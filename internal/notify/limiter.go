@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter sliding window limit of the messages by key.
+type Limiter struct {
+	period time.Duration
+	mu     sync.Mutex
+	sent   map[string][]time.Time
+}
+
+// NewLimiter builder for Limiter.
+func NewLimiter(period time.Duration) *Limiter {
+	return &Limiter{period: period, sent: make(map[string][]time.Time)}
+}
+
+// Allow reports whether message of the key is allowed at now and counts it,
+// limit <= 0 or zero period - unlimited.
+func (l *Limiter) Allow(key string, limit int, now time.Time) bool {
+	if limit <= 0 || l.period <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	since := now.Add(-l.period)
+	sent := l.sent[key]
+	i := 0
+	for i < len(sent) && !sent[i].After(since) {
+		i++
+	}
+	sent = sent[i:]
+	if len(sent) >= limit {
+		l.sent[key] = sent
+		return false
+	}
+	l.sent[key] = append(sent, now)
+	return true
+}
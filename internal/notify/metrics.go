@@ -0,0 +1,16 @@
+package notify
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	notifications = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notify_messages_total",
+			Help: "Count of the notifications about events by channel and result: sent, failed, quiet, limited",
+		},
+		[]string{"channel", "result"},
+	)
+)
@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstate"
+	"git.countmax.ru/countmax/pkg/logging"
+)
+
+type rule struct {
+	Rule
+	tpl *Templates
+}
+
+type recipient struct {
+	Recipient
+	quiet *Window
+}
+
+// Notifier routes events by rules to the channels of the recipients.
+type Notifier struct {
+	rules      []rule
+	recipients map[string]recipient
+	channels   map[string]Channel
+	hours      *businessHours
+	limiter    *Limiter
+	rateLimit  int
+	now        func() time.Time
+}
+
+// New builder for Notifier, repos provide business hours of the layouts, channels of the rules must be passed.
+func New(cfg Config, repos Repos, channels ...Channel) (*Notifier, error) {
+	n := &Notifier{
+		recipients: make(map[string]recipient, len(cfg.Recipients)),
+		channels:   make(map[string]Channel, len(channels)),
+		hours:      newBusinessHours(repos),
+		limiter:    NewLimiter(cfg.RatePeriod),
+		rateLimit:  cfg.RateLimit,
+		now:        time.Now,
+	}
+	var names []string
+	for _, ch := range channels {
+		n.channels[ch.Name()] = ch
+		names = append(names, ch.Name())
+	}
+	for _, r := range cfg.Recipients {
+		if r.Name == "" {
+			return nil, errors.Wrap(ErrInvalid, "recipient without name")
+		}
+		rcp := recipient{Recipient: r}
+		if r.QuietHours != "" {
+			w, err := ParseWindow(r.QuietHours, r.TimeZone)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "quiet hours of the recipient %s", r.Name)
+			}
+			rcp.quiet = &w
+		}
+		n.recipients[r.Name] = rcp
+	}
+	for i, r := range cfg.Rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule%d", i+1)
+		}
+		if r.Hours != HoursAny && r.Hours != HoursBusiness && r.Hours != HoursOff {
+			return nil, errors.Wrapf(ErrInvalid, "hours %q of the rule %s, allowed business, off", r.Hours, r.Name)
+		}
+		if len(r.Recipients) == 0 {
+			return nil, errors.Wrapf(ErrInvalid, "rule %s without recipients", r.Name)
+		}
+		for _, name := range r.Recipients {
+			if _, ok := n.recipients[name]; !ok {
+				return nil, errors.Wrapf(ErrInvalid, "unknown recipient %s of the rule %s", name, r.Name)
+			}
+		}
+		if len(r.Channels) == 0 {
+			r.Channels = names
+		}
+		for _, name := range r.Channels {
+			if _, ok := n.channels[name]; !ok {
+				return nil, errors.Wrapf(ErrInvalid, "channel %s of the rule %s isn't configured", name, r.Name)
+			}
+		}
+		subject, body := r.Subject, r.Body
+		if subject == "" {
+			subject = cfg.Subject
+		}
+		if body == "" {
+			body = cfg.Body
+		}
+		tpl, err := ParseTemplates(r.Name, subject, body)
+		if err != nil {
+			return nil, err
+		}
+		n.rules = append(n.rules, rule{Rule: r, tpl: tpl})
+	}
+	return n, nil
+}
+
+// Run notifies about events of the channel until it's closed.
+func (n *Notifier) Run(ctx context.Context, in <-chan domain.Event) {
+	log := logging.FromContext(ctx)
+	log.Debugf("start notifications with %d rules", len(n.rules))
+	defer log.Debug("stop notifications")
+	for e := range in {
+		if err := n.Notify(ctx, e); err != nil {
+			log.Errorf("notify about event %s error, %s", e.ID, err)
+		}
+	}
+}
+
+// Notify sends new event to the recipients of the matching rules, recipient gets single message by channel,
+// changes of the states of the events are skipped. Returns the first error of the sending.
+func (n *Notifier) Notify(ctx context.Context, e domain.Event) error {
+	if e.Change != nil && e.Change.Action != eventstate.ActionCreate {
+		return nil
+	}
+	log := logging.FromContext(ctx)
+	now := n.now()
+	notified := make(map[string]bool)
+	var first error
+	for _, r := range n.rules {
+		if !n.match(ctx, r, e, now) {
+			continue
+		}
+		for _, name := range r.Recipients {
+			rcp := n.recipients[name]
+			for _, chName := range r.Channels {
+				ch := n.channels[chName]
+				to := ch.Address(rcp.Recipient)
+				key := chName + ":" + name
+				if to == "" || notified[key] {
+					continue
+				}
+				notified[key] = true
+				err := n.send(ctx, r, rcp, ch, to, e, now)
+				if err != nil {
+					log.Errorf("notify %s by %s about event %s error, %s", name, chName, e.ID, err)
+					if first == nil {
+						first = err
+					}
+				}
+			}
+		}
+	}
+	return first
+}
+
+// match reports whether event matches rule, business hours are checked at now,
+// layout with unknown hours is treated as working.
+func (n *Notifier) match(ctx context.Context, r rule, e domain.Event, now time.Time) bool {
+	if !matchAny(r.Layouts, e.LayoutID) || !matchAny(r.Stores, e.StoreID) ||
+		!matchAny(r.Keys, e.Key) || !matchAny(r.Severities, e.Severity) {
+		return false
+	}
+	if r.Hours == HoursAny {
+		return true
+	}
+	w, err := n.hours.window(ctx, e.LayoutID, now)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("business hours of the layout %s error, %s", e.LayoutID, err)
+	}
+	open := w == nil || w.Contains(now)
+	return open == (r.Hours == HoursBusiness)
+}
+
+// send renders and sends message if recipient isn't in quiet hours and within rate limit.
+func (n *Notifier) send(ctx context.Context, r rule, rcp recipient, ch Channel, to string, e domain.Event,
+	now time.Time) error {
+	//
+	if rcp.quiet != nil && rcp.quiet.Contains(now) {
+		notifications.WithLabelValues(ch.Name(), resultQuiet).Inc()
+		return nil
+	}
+	limit := n.rateLimit
+	if rcp.RateLimit > 0 {
+		limit = rcp.RateLimit
+	}
+	if !n.limiter.Allow(ch.Name()+":"+rcp.Name, limit, now) {
+		notifications.WithLabelValues(ch.Name(), resultLimited).Inc()
+		logging.FromContext(ctx).Warnf("notification of %s by %s about event %s dropped by rate limit", rcp.Name, ch.Name(), e.ID)
+		return nil
+	}
+	d := Data{Event: e, Rule: r.Name, Recipient: rcp.Name}
+	if e.Source != nil {
+		d.Params = e.Source.Params
+	}
+	m, err := r.tpl.Render(d)
+	if err != nil {
+		notifications.WithLabelValues(ch.Name(), resultFailed).Inc()
+		return err
+	}
+	if err := ch.Send(ctx, to, m); err != nil {
+		notifications.WithLabelValues(ch.Name(), resultFailed).Inc()
+		return err
+	}
+	notifications.WithLabelValues(ch.Name(), resultSent).Inc()
+	return nil
+}
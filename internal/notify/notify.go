@@ -0,0 +1,118 @@
+// Package notify sends notifications about events to the recipients by email and messenger bots,
+// events are routed by rules matching layout, store, key, severity and business hours of the layout,
+// recipients have quiet hours and rate limit of the messages.
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// channels of the notifications
+const (
+	ChannelEmail    string = "email"
+	ChannelTelegram string = "telegram"
+)
+
+// hours of the rule relative to business hours Open/Close of the behavior of the layout
+const (
+	HoursAny      string = ""
+	HoursBusiness string = "business"
+	HoursOff      string = "off"
+)
+
+// results of the notification for the metrics
+const (
+	resultSent    string = "sent"
+	resultFailed  string = "failed"
+	resultQuiet   string = "quiet"
+	resultLimited string = "limited"
+)
+
+var (
+	// ErrInvalid config of the notifications malformed.
+	ErrInvalid = errors.New("invalid notify config")
+)
+
+// Config settings of the notifications.
+type Config struct {
+	Email    EmailConfig    `mapstructure:"email"`
+	Telegram TelegramConfig `mapstructure:"telegram"`
+	// RateLimit max count of the messages to the recipient by channel in RatePeriod, 0 - unlimited
+	RateLimit  int           `mapstructure:"rate_limit"`
+	RatePeriod time.Duration `mapstructure:"rate_period"`
+	// Subject and Body default templates of the message, text/template with Data
+	Subject    string      `mapstructure:"subject"`
+	Body       string      `mapstructure:"body"`
+	Recipients []Recipient `mapstructure:"recipients"`
+	Rules      []Rule      `mapstructure:"rules"`
+}
+
+// Recipient addresses of the person by channels.
+type Recipient struct {
+	Name   string `mapstructure:"name"`
+	Email  string `mapstructure:"email"`
+	ChatID string `mapstructure:"chat_id"`
+	// QuietHours messages aren't sent within, e.g. 22:00-08:00, empty - never
+	QuietHours string `mapstructure:"quiet_hours"`
+	// TimeZone of the quiet hours, empty - local time zone of the service
+	TimeZone string `mapstructure:"time_zone"`
+	// RateLimit overrides Config.RateLimit if positive
+	RateLimit int `mapstructure:"rate_limit"`
+}
+
+// Rule routes matching events to the recipients, empty filters match any value.
+type Rule struct {
+	Name       string   `mapstructure:"name"`
+	Layouts    []string `mapstructure:"layouts"`
+	Stores     []string `mapstructure:"stores"`
+	Keys       []string `mapstructure:"keys"`
+	Severities []string `mapstructure:"severities"`
+	// Hours business - only within Open/Close of the layout, off - only outside, empty - any time
+	Hours      string   `mapstructure:"hours"`
+	Channels   []string `mapstructure:"channels"`
+	Recipients []string `mapstructure:"recipients"`
+	// Subject and Body override default templates if not empty
+	Subject string `mapstructure:"subject"`
+	Body    string `mapstructure:"body"`
+}
+
+// Message rendered notification.
+type Message struct {
+	Subject string
+	Body    string
+}
+
+// Channel delivers messages to the recipients.
+type Channel interface {
+	Name() string
+	// Address returns address of the recipient in the channel, empty - recipient isn't reachable
+	Address(r Recipient) string
+	Send(ctx context.Context, to string, m Message) error
+}
+
+// Channels makes channels configured in cfg.
+func Channels(cfg Config) []Channel {
+	var chs []Channel
+	if cfg.Email.Addr != "" {
+		chs = append(chs, NewEmail(cfg.Email))
+	}
+	if cfg.Telegram.Token != "" {
+		chs = append(chs, NewTelegram(cfg.Telegram))
+	}
+	return chs
+}
+
+func matchAny(allowed []string, v string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, s := range allowed {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package notify_test
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/fakenotify"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/notify"
+)
+
+// fakeRepo behaviors of the layouts: 10 is open now, 20 is closed now.
+type fakeRepo struct {
+	domain.LayoutRepo
+}
+
+func (r fakeRepo) FindBehaviorByLayoutID(ctx context.Context, layoutID string) (*domain.Behavior, error) {
+	now := time.Now().UTC()
+	clock := func(d time.Duration) string { return now.Add(d).Format("15:04:05") }
+	if layoutID == "10" {
+		return &domain.Behavior{Open: clock(-time.Hour), Close: clock(time.Hour), TimeZone: "UTC"}, nil
+	}
+	return &domain.Behavior{Open: clock(time.Hour), Close: clock(2 * time.Hour), TimeZone: "UTC"}, nil
+}
+
+type fakeRepos struct{}
+
+func (fakeRepos) RepoByID(layoutID string) (domain.LayoutRepo, bool) { return fakeRepo{}, true }
+
+func TestWindow_Contains(t *testing.T) {
+	at := func(clock string) time.Time {
+		tm, _ := time.Parse("2006-01-02 15:04", "2021-06-01 "+clock)
+		return tm
+	}
+	tests := []struct {
+		hours string
+		at    string
+		want  bool
+	}{
+		{"10:00-23:00", "12:00", true},
+		{"10:00-23:00", "23:00", false},
+		{"22:00-08:00", "23:30", true},
+		{"22:00-08:00", "07:59", true},
+		{"22:00-08:00", "12:00", false},
+		{"00:00-00:00", "12:00", true},
+	}
+	for _, tt := range tests {
+		w, err := notify.ParseWindow(tt.hours, "UTC")
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		if got := w.Contains(at(tt.at)); got != tt.want {
+			t.Errorf("%s Contains(%s) = %v, want %v", tt.hours, tt.at, got, tt.want)
+		}
+	}
+	if _, err := notify.ParseWindow("22:00", ""); err == nil {
+		t.Error("ParseWindow() without end, want error")
+	}
+	if _, err := notify.ParseWindow("22:00-08:00", "Mars/Olympus"); err == nil {
+		t.Error("ParseWindow() with unknown time zone, want error")
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	t0 := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	l := notify.NewLimiter(time.Minute)
+	for i, want := range []bool{true, true, false} {
+		if got := l.Allow("a", 2, t0.Add(time.Duration(i)*time.Second)); got != want {
+			t.Errorf("Allow() #%d = %v, want %v", i, got, want)
+		}
+	}
+	if !l.Allow("b", 2, t0) {
+		t.Error("Allow() of other key = false, want true")
+	}
+	if !l.Allow("a", 2, t0.Add(time.Minute)) {
+		t.Error("Allow() after period = false, want true")
+	}
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	ctx := context.Background()
+	fake := fakenotify.New(fakenotify.Config{Token: "secret", Username: "notify", Password: "pass"})
+	bot := httptest.NewServer(fake)
+	defer bot.Close()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	defer l.Close()
+	go func() { _ = fake.ServeSMTP(l) }()
+
+	cfg := notify.Config{
+		Email:      notify.EmailConfig{Addr: l.Addr().String(), Username: "notify", Password: "pass", From: "layoutconfig@local"},
+		Telegram:   notify.TelegramConfig{URL: bot.URL, Token: "secret"},
+		RateLimit:  2,
+		RatePeriod: time.Hour,
+		Recipients: []notify.Recipient{
+			{Name: "manager", Email: "manager@local", ChatID: "100"},
+			{Name: "sleeper", Email: "sleeper@local", QuietHours: "00:00-00:00"},
+		},
+		Rules: []notify.Rule{
+			{Name: "alarms", Severities: []string{"alarm"}, Hours: notify.HoursBusiness,
+				Recipients: []string{"manager", "sleeper"}},
+			{Name: "queue", Keys: []string{"queue.threshold.exceeded"}, Channels: []string{notify.ChannelTelegram},
+				Recipients: []string{"manager"}, Subject: "Очередь {{.Event.StoreID}}", Body: "{{.Event.Message}}"},
+		},
+	}
+	n, err := notify.New(cfg, fakeRepos{}, notify.Channels(cfg)...)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	alarm := domain.Event{ID: "1", Key: "door.open", Severity: "alarm", LayoutID: "10", StoreID: "s1",
+		Message: "Дверь открыта", EventTime: time.Now(), Source: &domain.Source{Params: []domain.Param{{Name: "door", Value: "2"}}}}
+	if err := n.Notify(ctx, alarm); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	msgs := fake.Messages()
+	if len(msgs.Mails) != 1 || msgs.Mails[0].To[0] != "manager@local" || msgs.Mails[0].Subject != "[alarm] door.open store s1" ||
+		!strings.Contains(msgs.Mails[0].Body, "Дверь открыта") || !strings.Contains(msgs.Mails[0].Body, "door: 2") {
+		t.Errorf("mails %+v, want single mail to manager, sleeper is quiet", msgs.Mails)
+	}
+	if len(msgs.Bot) != 1 || msgs.Bot[0].ChatID != "100" {
+		t.Errorf("bot messages %+v, want single message to manager", msgs.Bot)
+	}
+
+	fake.Reset()
+	closed := alarm
+	closed.LayoutID = "20"
+	queue := domain.Event{ID: "2", Key: "queue.threshold.exceeded", Severity: "warn", LayoutID: "10", StoreID: "s1", Message: "12"}
+	changed := alarm
+	changed.Change = &domain.EventTransition{Action: "acknowledge"}
+	for _, e := range []domain.Event{closed, queue, changed} {
+		if err := n.Notify(ctx, e); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	msgs = fake.Messages()
+	if len(msgs.Mails) != 0 || len(msgs.Bot) != 1 || msgs.Bot[0].Text != "Очередь s1\n\n12" {
+		t.Errorf("messages %+v, want only queue message by bot", msgs)
+	}
+
+	// the third message to the manager by bot within hour is limited
+	fake.Reset()
+	if err := n.Notify(ctx, queue); err != nil || len(fake.Messages().Bot) != 0 {
+		t.Errorf("Notify() = %v, messages %+v, want limited", err, fake.Messages())
+	}
+
+	n, err = notify.New(cfg, fakeRepos{}, notify.Channels(cfg)...)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	fake.FailBot(1)
+	if err := n.Notify(ctx, queue); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("Notify() with failed bot = %v, want status 429", err)
+	}
+}
+
+func TestNew_invalid(t *testing.T) {
+	tests := map[string]notify.Config{
+		"unknown_recipient": {Rules: []notify.Rule{{Recipients: []string{"nobody"}}}},
+		"not_configured_channel": {Recipients: []notify.Recipient{{Name: "a"}},
+			Rules: []notify.Rule{{Recipients: []string{"a"}, Channels: []string{notify.ChannelEmail}}}},
+		"bad_hours": {Recipients: []notify.Recipient{{Name: "a"}},
+			Rules: []notify.Rule{{Recipients: []string{"a"}, Hours: "night"}}},
+		"bad_quiet_hours": {Recipients: []notify.Recipient{{Name: "a", QuietHours: "late"}}},
+		"bad_template":    {Recipients: []notify.Recipient{{Name: "a"}}, Rules: []notify.Rule{{Recipients: []string{"a"}, Body: "{{.Event"}}},
+	}
+	for name, cfg := range tests {
+		if _, err := notify.New(cfg, nil); err == nil {
+			t.Errorf("%s: New() without error", name)
+		}
+	}
+}
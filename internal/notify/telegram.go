@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const defaultBotURL string = "https://api.telegram.org"
+
+// TelegramConfig settings of the bot API.
+type TelegramConfig struct {
+	// URL of the bot API, empty - https://api.telegram.org
+	URL string `mapstructure:"url"`
+	// Token of the bot, empty - telegram disabled
+	Token   string        `mapstructure:"token"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// Telegram sends messages by bot API method sendMessage.
+type Telegram struct {
+	endpoint string
+	client   *http.Client
+}
+
+type sendMessage struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+type botResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description,omitempty"`
+}
+
+// NewTelegram builder for Telegram.
+func NewTelegram(cfg TelegramConfig) *Telegram {
+	if cfg.URL == "" {
+		cfg.URL = defaultBotURL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	return &Telegram{
+		endpoint: strings.TrimSuffix(cfg.URL, "/") + "/bot" + cfg.Token + "/sendMessage",
+		client:   &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Name implements Channel.
+func (t *Telegram) Name() string { return ChannelTelegram }
+
+// Address implements Channel.
+func (t *Telegram) Address(r Recipient) string { return r.ChatID }
+
+// Send implements Channel, subject is the first line of the text.
+func (t *Telegram) Send(ctx context.Context, to string, m Message) error {
+	text := m.Body
+	if m.Subject != "" {
+		text = m.Subject + "\n\n" + m.Body
+	}
+	body, err := json.Marshal(sendMessage{ChatID: to, Text: text, DisableWebPagePreview: true})
+	if err != nil {
+		return errors.Wrap(err, "marshal message failed")
+	}
+	req, err := http.NewRequest(http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// url of the error contains token
+		if ue, ok := err.(*url.Error); ok {
+			err = ue.Err
+		}
+		return errors.Wrap(err, "bot api request failed")
+	}
+	defer resp.Body.Close()
+	res := botResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("bot api status %d, decode response error, %s", resp.StatusCode, err)
+	}
+	if !res.OK {
+		return fmt.Errorf("bot api status %d, %s", resp.StatusCode, res.Description)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+)
+
+const (
+	// DefaultSubject template of the subject if Config.Subject is empty
+	DefaultSubject string = `[{{.Event.Severity}}] {{.Event.Key}}{{if .Event.StoreID}} store {{.Event.StoreID}}{{end}}`
+	// DefaultBody template of the body if Config.Body is empty
+	DefaultBody string = `{{.Event.Message}}
+key: {{.Event.Key}}
+severity: {{.Event.Severity}}
+layout: {{.Event.LayoutID}}{{if .Event.StoreID}}
+store: {{.Event.StoreID}}{{end}}
+time: {{.Event.EventTime.Format "2006-01-02 15:04:05"}}{{range .Params}}
+{{.Name}}: {{.Value}}{{end}}`
+)
+
+// Data of the templates.
+type Data struct {
+	Event     domain.Event
+	Params    []domain.Param
+	Rule      string
+	Recipient string
+}
+
+// Templates subject and body of the message.
+type Templates struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// ParseTemplates parses subject and body, empty are replaced by defaults.
+func ParseTemplates(name, subject, body string) (*Templates, error) {
+	if subject == "" {
+		subject = DefaultSubject
+	}
+	if body == "" {
+		body = DefaultBody
+	}
+	s, err := template.New(name + ".subject").Option("missingkey=zero").Parse(subject)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalid, "subject template of %s, %s", name, err)
+	}
+	b, err := template.New(name + ".body").Option("missingkey=zero").Parse(body)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalid, "body template of %s, %s", name, err)
+	}
+	return &Templates{subject: s, body: b}, nil
+}
+
+// Render executes templates with data, subject is single line.
+func (t *Templates) Render(d Data) (Message, error) {
+	var subject, body strings.Builder
+	if err := t.subject.Execute(&subject, d); err != nil {
+		return Message{}, errors.Wrap(err, "render subject failed")
+	}
+	if err := t.body.Execute(&body, d); err != nil {
+		return Message{}, errors.Wrap(err, "render body failed")
+	}
+	return Message{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Body:    strings.TrimSpace(body.String()),
+	}, nil
+}
@@ -0,0 +1,89 @@
+package permission
+
+import (
+	"context"
+	b64 "encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/acl"
+)
+
+func anonymousRequest(t *testing.T, perm string) *http.Request {
+	t.Helper()
+	request, err := http.NewRequest("GET", "localhost", nil)
+	if err != nil {
+		t.Fatalf("make request error, %s", err)
+	}
+	if perm != "" {
+		request.Header.Add(XUserPermission, b64.StdEncoding.EncodeToString([]byte(perm)))
+	}
+	return request
+}
+
+func TestManager_getUserID_Anonymous(t *testing.T) {
+	m := NewManager(newFakeCache(), nil, DefaultAllow, time.Hour)
+	permAll := `[{"resources":["watcom.ru:data.counting:layouts:*"],"actions":["*"],"effect":"allow"}]`
+	permRead := `[{"resources":["watcom.ru:data.counting:layouts:*"],"actions":["read"],"effect":"allow"}]`
+	id1 := m.getUserID(anonymousRequest(t, permAll))
+	id2 := m.getUserID(anonymousRequest(t, permAll))
+	if id1 != id2 {
+		t.Errorf("getUserID() for same permissions = %s and %s, want equal", id1, id2)
+	}
+	if !strings.HasPrefix(id1, anonymousPrefix) {
+		t.Errorf("getUserID() = %s, want prefix %s", id1, anonymousPrefix)
+	}
+	if id3 := m.getUserID(anonymousRequest(t, permRead)); id3 == id1 {
+		t.Errorf("getUserID() for different permissions = %s, want not equal %s", id3, id1)
+	}
+	// without permissions the policy is applied, same as for explicit policy
+	policy := `[{"resources":["*:data.counting:layouts:*"],"actions":["*"],"effect":"allow","conditions":null}]`
+	if idp, idpe := m.getUserID(anonymousRequest(t, "")), m.getUserID(anonymousRequest(t, policy)); idp != idpe {
+		t.Errorf("getUserID() by policy = %s, want %s", idp, idpe)
+	}
+	r := anonymousRequest(t, permAll)
+	r.Header.Add(XUserID, "7")
+	if id := m.getUserID(r); id != "7" {
+		t.Errorf("getUserID() = %s, want 7", id)
+	}
+}
+
+func TestManager_getUser_AnonymousCachedOnce(t *testing.T) {
+	c := newFakeCache()
+	m := NewManager(c, nil, DefaultAllow, time.Hour)
+	permAll := `[{"resources":["watcom.ru:data.counting:layouts:*"],"actions":["*"],"effect":"allow"}]`
+	for i := 0; i < 3; i++ {
+		if _, err := m.getUser(anonymousRequest(t, permAll)); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	keys, _ := fakeDelCache{c}.Keys(context.Background())
+	if len(keys) != 1 {
+		t.Errorf("cached users = %v, want one anonymous user", keys)
+	}
+}
+
+func TestManager_CheckAdmin(t *testing.T) {
+	m := NewManager(newFakeCache(), nil, DefaultAllow, time.Hour)
+	tests := []struct {
+		name string
+		perm string
+		want bool
+	}{
+		{"policy", "", false},
+		{"all_layouts", `[{"resources":["watcom.ru:data.counting:layouts:*"],"actions":["*"],"effect":"allow"}]`, false},
+		{"admin", `[{"resources":["watcom.ru:data.counting:admin"],"actions":["*"],"effect":"allow"}]`, true},
+		{"admin_read_only", `[{"resources":["watcom.ru:data.counting:admin"],"actions":["read"],"effect":"allow"}]`, false},
+		{"admin_denied", `[{"resources":["watcom.ru:data.counting:admin"],"actions":["*"],"effect":"allow"},` +
+			`{"resources":["watcom.ru:data.counting:admin"],"actions":["delete"],"effect":"deny"}]`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.CheckAdmin(anonymousRequest(t, tt.perm), acl.ActionDelete); got != tt.want {
+				t.Errorf("Manager.CheckAdmin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Package disk implements cache.RepoInterface in the embedded on-disk database,
+// the cached users ACLs survive restarts of the service.
+package disk
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/boltdb"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission/cache"
+)
+
+var bucketUsers = []byte("users")
+
+// entry is stored value with expiration time.
+type entry struct {
+	ExpireAt time.Time   `json:"expire_at"`
+	User     *cache.User `json:"user"`
+}
+
+// Repo on-disk cache of the users.
+type Repo struct {
+	db     *bolt.DB
+	expire time.Duration
+}
+
+// New builder for Repo, opens or creates database file by path,
+// expire used when Add called with zero expiration.
+func New(path string, expire time.Duration) (*Repo, error) {
+	db, err := boltdb.Open(path, bucketUsers)
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{db: db, expire: expire}, nil
+}
+
+// Get returns user from the cache, if not exists or expired returns cache.ErrNotFound.
+func (r *Repo) Get(ctx context.Context, key string) (*cache.User, error) {
+	var bts []byte
+	err := r.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketUsers).Get([]byte(key))
+		if v != nil {
+			bts = append([]byte{}, v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "bolt view failed")
+	}
+	if bts == nil {
+		return nil, cache.ErrNotFound
+	}
+	e := &entry{}
+	if err := json.Unmarshal(bts, e); err != nil {
+		return nil, errors.Wrap(err, "unmarshal entry failed")
+	}
+	if time.Now().After(e.ExpireAt) {
+		if err := r.Del(ctx, key); err != nil {
+			return nil, errors.WithMessage(err, "delete expired entry failed")
+		}
+		return nil, cache.ErrNotFound
+	}
+	return e.User, nil
+}
+
+// Add puts user to the cache with specified expiration.
+func (r *Repo) Add(ctx context.Context, key string, u *cache.User, expire time.Duration) error {
+	if expire <= 0 {
+		expire = r.expire
+	}
+	bts, err := json.Marshal(entry{ExpireAt: time.Now().Add(expire), User: u})
+	if err != nil {
+		return errors.Wrap(err, "marshal entry failed")
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketUsers).Put([]byte(key), bts)
+	})
+}
+
+// Del removes user from the cache.
+func (r *Repo) Del(ctx context.Context, key string) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketUsers).Delete([]byte(key))
+	})
+}
+
+// Keys returns keys of the all cached users.
+func (r *Repo) Keys(ctx context.Context) ([]string, error) {
+	result := make([]string, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketUsers).ForEach(func(k, _ []byte) error {
+			result = append(result, string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "bolt view failed")
+	}
+	return result, nil
+}
+
+// Purge removes all expired entries, returns count of the removed.
+func (r *Repo) Purge(ctx context.Context) (int, error) {
+	now := time.Now()
+	cnt := 0
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketUsers)
+		expired := make([][]byte, 0)
+		err := b.ForEach(func(k, v []byte) error {
+			e := &entry{}
+			if err := json.Unmarshal(v, e); err != nil || now.After(e.ExpireAt) {
+				expired = append(expired, append([]byte{}, k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		cnt = len(expired)
+		return nil
+	})
+	return cnt, err
+}
+
+// Janitor periodically purges expired entries until context is done.
+func (r *Repo) Janitor(ctx context.Context, period time.Duration) {
+	t := time.NewTicker(period)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			_, _ = r.Purge(ctx)
+		}
+	}
+}
+
+// Close closes database file.
+func (r *Repo) Close() error {
+	return r.db.Close()
+}
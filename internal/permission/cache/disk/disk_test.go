@@ -0,0 +1,111 @@
+package disk_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/acl"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission/cache"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission/cache/disk"
+)
+
+func prepareRepo(t *testing.T) (*disk.Repo, string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "aclcache")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir error, %s", err)
+	}
+	path := filepath.Join(dir, "acl.db")
+	r, err := disk.New(path, time.Hour)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("disk.New error, %s", err)
+	}
+	return r, path, func() {
+		_ = r.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func testUser(id string) *cache.User {
+	u := &cache.User{ID: id, ACLs: make(map[cache.LayoutID]acl.EntityItems, 1)}
+	u.AddItems(cache.LayoutID("37664168"), true, acl.Actions{acl.ActionRead},
+		[]string{"124804116", "39700940"}, acl.EntityKindStores)
+	return u
+}
+
+func TestRepo_AddGetReopen(t *testing.T) {
+	r, path, done := prepareRepo(t)
+	defer done()
+	ctx := context.Background()
+	want := testUser("7")
+	if err := r.Add(ctx, want.ID, want, time.Minute); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	reopened, err := disk.New(path, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	defer reopened.Close()
+	got, err := reopened.Get(ctx, want.ID)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Repo.Get() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRepo_Expire(t *testing.T) {
+	r, _, done := prepareRepo(t)
+	defer done()
+	ctx := context.Background()
+	u := testUser("8")
+	if err := r.Add(ctx, u.ID, u, time.Millisecond); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	_, err := r.Get(ctx, u.ID)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Repo.Get() after expire error = %v, want %v", err, cache.ErrNotFound)
+	}
+	keys, err := r.Keys(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("Repo.Keys() = %v, want empty after expired Get", keys)
+	}
+}
+
+func TestRepo_Purge(t *testing.T) {
+	r, _, done := prepareRepo(t)
+	defer done()
+	ctx := context.Background()
+	if err := r.Add(ctx, "1", testUser("1"), time.Millisecond); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if err := r.Add(ctx, "2", testUser("2"), time.Hour); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	cnt, err := r.Purge(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if cnt != 1 {
+		t.Errorf("Repo.Purge() = %d, want 1", cnt)
+	}
+	if _, err := r.Get(ctx, "2"); err != nil {
+		t.Errorf("Repo.Get() not expired error = %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+// Package lru implements cache.RepoInterface in the process memory
+// with limited count of the users, least recently used users are evicted first.
+package lru
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission/cache"
+)
+
+var (
+	evictions = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "permission_cache_evictions_total",
+			Help: "Count of the users ACLs evicted from the memory cache by size limit",
+		},
+	)
+
+	cacheSize = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "permission_cache_size",
+			Help: "Count of the users ACLs in the memory cache",
+		},
+	)
+)
+
+// entry is cached user with expiration time.
+type entry struct {
+	key      string
+	expireAt time.Time
+	user     *cache.User
+}
+
+// Repo memory cache of the users limited by size.
+type Repo struct {
+	mu     sync.Mutex
+	size   int
+	expire time.Duration
+	ll     *list.List
+	items  map[string]*list.Element
+}
+
+// New builder for Repo, size is max count of the cached users,
+// expire used when Add called with zero expiration.
+func New(size int, expire time.Duration) *Repo {
+	return &Repo{
+		size:   size,
+		expire: expire,
+		ll:     list.New(),
+		items:  make(map[string]*list.Element, size),
+	}
+}
+
+// Get returns user from the cache, if not exists or expired returns cache.ErrNotFound.
+func (r *Repo) Get(ctx context.Context, key string) (*cache.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	el, ok := r.items[key]
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	e := el.Value.(*entry)
+	if time.Now().After(e.expireAt) {
+		r.remove(el)
+		return nil, cache.ErrNotFound
+	}
+	r.ll.MoveToFront(el)
+	return e.user, nil
+}
+
+// Add puts user to the cache with specified expiration,
+// evicts least recently used users if size exceeded.
+func (r *Repo) Add(ctx context.Context, key string, u *cache.User, expire time.Duration) error {
+	if expire <= 0 {
+		expire = r.expire
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if el, ok := r.items[key]; ok {
+		e := el.Value.(*entry)
+		e.user, e.expireAt = u, time.Now().Add(expire)
+		r.ll.MoveToFront(el)
+		return nil
+	}
+	r.items[key] = r.ll.PushFront(&entry{key: key, expireAt: time.Now().Add(expire), user: u})
+	for r.size > 0 && r.ll.Len() > r.size {
+		r.remove(r.ll.Back())
+		evictions.Inc()
+	}
+	cacheSize.Set(float64(r.ll.Len()))
+	return nil
+}
+
+// Del removes user from the cache.
+func (r *Repo) Del(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if el, ok := r.items[key]; ok {
+		r.remove(el)
+	}
+	return nil
+}
+
+// Keys returns keys of the all cached users.
+func (r *Repo) Keys(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]string, 0, len(r.items))
+	for key := range r.items {
+		result = append(result, key)
+	}
+	return result, nil
+}
+
+// Len returns count of the cached users.
+func (r *Repo) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ll.Len()
+}
+
+// remove must be called under lock.
+func (r *Repo) remove(el *list.Element) {
+	r.ll.Remove(el)
+	delete(r.items, el.Value.(*entry).key)
+	cacheSize.Set(float64(r.ll.Len()))
+}
@@ -0,0 +1,80 @@
+package lru_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/acl"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission/cache"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission/cache/lru"
+)
+
+func testUser(id string) *cache.User {
+	return &cache.User{ID: id, ACLs: make(map[cache.LayoutID]acl.EntityItems, 1)}
+}
+
+func TestRepo_Evict(t *testing.T) {
+	ctx := context.Background()
+	r := lru.New(2, time.Hour)
+	for _, id := range []string{"1", "2"} {
+		if err := r.Add(ctx, id, testUser(id), 0); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	// "1" becomes recently used, "2" must be evicted
+	if _, err := r.Get(ctx, "1"); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if err := r.Add(ctx, "3", testUser("3"), 0); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if r.Len() != 2 {
+		t.Errorf("Repo.Len() = %d, want 2", r.Len())
+	}
+	if _, err := r.Get(ctx, "2"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Repo.Get(2) error = %v, want %v", err, cache.ErrNotFound)
+	}
+	for _, id := range []string{"1", "3"} {
+		if u, err := r.Get(ctx, id); err != nil || u.ID != id {
+			t.Errorf("Repo.Get(%s) = %v, %v", id, u, err)
+		}
+	}
+}
+
+func TestRepo_Expire(t *testing.T) {
+	ctx := context.Background()
+	r := lru.New(10, time.Hour)
+	if err := r.Add(ctx, "1", testUser("1"), time.Millisecond); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := r.Get(ctx, "1"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Repo.Get() after expire error = %v, want %v", err, cache.ErrNotFound)
+	}
+	if r.Len() != 0 {
+		t.Errorf("Repo.Len() = %d, want 0 after expired Get", r.Len())
+	}
+}
+
+func TestRepo_UpdateDel(t *testing.T) {
+	ctx := context.Background()
+	r := lru.New(1, time.Hour)
+	if err := r.Add(ctx, "1", testUser("1"), 0); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if err := r.Add(ctx, "1", testUser("1"), 0); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if r.Len() != 1 {
+		t.Errorf("Repo.Len() = %d, want 1 after update", r.Len())
+	}
+	if err := r.Del(ctx, "1"); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	keys, _ := r.Keys(ctx)
+	if len(keys) != 0 {
+		t.Errorf("Repo.Keys() = %v, want empty", keys)
+	}
+}
@@ -0,0 +1,101 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/acl"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission/cache"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission/cache/redis"
+)
+
+func prepareRepo(t *testing.T) (*redis.Repo, *miniredis.Miniredis, func()) {
+	t.Helper()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("miniredis.Run error, %s", err)
+	}
+	r, err := redis.New("redis://"+mr.Addr()+"/0", time.Hour)
+	if err != nil {
+		mr.Close()
+		t.Fatalf("redis.New error, %s", err)
+	}
+	return r, mr, func() {
+		_ = r.Close()
+		mr.Close()
+	}
+}
+
+func testUser(id string) *cache.User {
+	u := &cache.User{ID: id, ACLs: make(map[cache.LayoutID]acl.EntityItems, 1)}
+	u.AddItems(cache.LayoutID("118416189"), true, acl.Actions{acl.ActionRead},
+		[]string{"82949216", "147298805"}, acl.EntityKindStores)
+	return u
+}
+
+func TestRepo_AddGet(t *testing.T) {
+	r, _, done := prepareRepo(t)
+	defer done()
+	ctx := context.Background()
+	want := testUser("7")
+	if err := r.Add(ctx, want.ID, want, time.Minute); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	got, err := r.Get(ctx, want.ID)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Repo.Get() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRepo_GetNotFound(t *testing.T) {
+	r, _, done := prepareRepo(t)
+	defer done()
+	_, err := r.Get(context.Background(), "unknown")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Repo.Get() error = %v, want %v", err, cache.ErrNotFound)
+	}
+}
+
+func TestRepo_Expire(t *testing.T) {
+	r, mr, done := prepareRepo(t)
+	defer done()
+	ctx := context.Background()
+	u := testUser("8")
+	if err := r.Add(ctx, u.ID, u, time.Minute); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	mr.FastForward(2 * time.Minute)
+	_, err := r.Get(ctx, u.ID)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Repo.Get() after expire error = %v, want %v", err, cache.ErrNotFound)
+	}
+}
+
+func TestRepo_DelKeys(t *testing.T) {
+	r, _, done := prepareRepo(t)
+	defer done()
+	ctx := context.Background()
+	for _, id := range []string{"1", "2"} {
+		if err := r.Add(ctx, id, testUser(id), 0); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	if err := r.Del(ctx, "1"); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	keys, err := r.Keys(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if !reflect.DeepEqual(keys, []string{"2"}) {
+		t.Errorf("Repo.Keys() = %v, want [2]", keys)
+	}
+}
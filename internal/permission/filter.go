@@ -0,0 +1,102 @@
+package permission
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/acl"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission/cache"
+)
+
+// StoresFilter stores allowed to the user.
+type StoresFilter struct {
+	// All user has no store level restrictions
+	All bool
+	ids map[string]struct{}
+}
+
+// Allow reports whether store allowed.
+func (f StoresFilter) Allow(storeID string) bool {
+	if f.All {
+		return true
+	}
+	_, ok := f.ids[storeID]
+	return ok
+}
+
+// Empty reports whether no one store allowed.
+func (f StoresFilter) Empty() bool {
+	return !f.All && len(f.ids) == 0
+}
+
+// List returns sorted list of the allowed stores, for All returns asterisk.
+func (f StoresFilter) List() []string {
+	if f.All {
+		return []string{"*"}
+	}
+	res := make([]string, 0, len(f.ids))
+	for id := range f.ids {
+		res = append(res, id)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// AllowedStores makes filter of the stores allowed for action in the layout,
+// rules by stores, cities, regions and countries are already resolved to stores in the user ACLs;
+// if layoutID empty or asterisk joins stores of the layouts allowed to the user, all stores are allowed
+// only for the users with permission to all layouts (*) without store level restrictions.
+func (m *Manager) AllowedStores(r *http.Request, layoutID string, action acl.Action) (StoresFilter, error) {
+	if m.c == nil {
+		return StoresFilter{}, errors.New("nil cache")
+	}
+	u, err := m.getUser(r)
+	if err != nil {
+		return StoresFilter{}, errors.Errorf("getUser error, %s", err)
+	}
+	ps := m.FromRequest(r)
+	if layoutID != "" && layoutID != "*" {
+		if !ps.CheckLayout(layoutID, action) {
+			return StoresFilter{}, nil
+		}
+		return storesFilterOf(u, layoutID, action), nil
+	}
+	f := StoresFilter{All: ps.CheckLayout("*", action), ids: make(map[string]struct{})}
+	for lid := range u.ACLs {
+		if lid == "*" || !ps.CheckLayout(string(lid), action) {
+			continue
+		}
+		lf := storesFilterOf(u, string(lid), action)
+		if lf.All {
+			// stores of the layout without restrictions are allowed only by permission to all layouts
+			continue
+		}
+		f.All = false
+		for id := range lf.ids {
+			f.ids[id] = struct{}{}
+		}
+	}
+	return f, nil
+}
+
+// storesFilterOf makes filter by the store items of the layout, no store items means no store level restrictions,
+// store rules resolved to no one store are denied (see noEntities).
+func storesFilterOf(u *cache.User, layoutID string, action acl.Action) StoresFilter {
+	list, ok := u.FilteredStores(cache.LayoutID(layoutID), acl.Actions{action}, []string{"*"})
+	f := StoresFilter{ids: make(map[string]struct{}, len(list))}
+	if !ok {
+		return f
+	}
+	for _, id := range list {
+		switch id {
+		case "*":
+			return StoresFilter{All: true}
+		case "", noEntities:
+			continue
+		}
+		f.ids[id] = struct{}{}
+	}
+	return f
+}
@@ -0,0 +1,111 @@
+package permission
+
+import (
+	"context"
+	b64 "encoding/base64"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/acl"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission/cache"
+)
+
+const (
+	filterLayoutNet1 string = "118416189"
+	filterLayoutNet2 string = "37664168"
+	// permissions
+	filterPermStore  string = `[{"resources":["watcom.ru:data.counting:layouts:118416189","watcom.ru:data.counting:stores:80079091"],"actions":["read"],"effect":"allow"}]`
+	filterPermCity   string = `[{"resources":["watcom.ru:data.counting:layouts:118416189","watcom.ru:data.counting:cities:961"],"actions":["read"],"effect":"allow"}]`
+	filterPermLayout string = `[{"resources":["watcom.ru:data.counting:layouts:118416189"],"actions":["read"],"effect":"allow"}]`
+	filterPermAll    string = `[{"resources":["watcom.ru:data.counting:layouts:*"],"actions":["read"],"effect":"allow"}]`
+)
+
+// addFilterUser puts user with ACLs as they are filled from the repo,
+// cities are already resolved to stores.
+func addFilterUser(t *testing.T, m *Manager, id string, stores []string) {
+	t.Helper()
+	u := &cache.User{ID: id, ACLs: make(map[cache.LayoutID]acl.EntityItems, 1)}
+	lid := cache.LayoutID(filterLayoutNet1)
+	u.AddItems(lid, true, acl.Actions{acl.ActionRead}, []string{filterLayoutNet1}, acl.EntityKindLayouts)
+	u.AddItems(lid, true, acl.Actions{acl.ActionRead}, stores, acl.EntityKindStores)
+	if err := m.c.Add(context.Background(), id, u, time.Hour); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+}
+
+func TestManager_AllowedStoresCached(t *testing.T) {
+	m := NewManager(newFakeCache(), nil, DefaultDeny, time.Hour)
+	addFilterUser(t, m, "byStore", []string{"80079091"})
+	addFilterUser(t, m, "byCity", []string{"147298805", "82949216"})
+	addFilterUser(t, m, "byLayout", nil)
+	// cities without stores
+	addFilterUser(t, m, "byEmptyCity", nil)
+	u, err := m.c.Get(context.Background(), "byEmptyCity")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	u.AddItems(cache.LayoutID(filterLayoutNet1), false, acl.Actions{acl.ActionRead}, []string{noEntities}, acl.EntityKindStores)
+	allUser := &cache.User{ID: "byAll", ACLs: make(map[cache.LayoutID]acl.EntityItems, 1)}
+	allUser.AddItems("*", true, acl.Actions{acl.ActionRead}, []string{"*"}, acl.EntityKindLayouts)
+	if err := m.c.Add(context.Background(), "byAll", allUser, time.Hour); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	tests := []struct {
+		name      string
+		uid       string
+		perm      string
+		layout    string
+		wantAll   bool
+		wantList  []string
+		wantAllow map[string]bool
+	}{
+		{"1.byStore_read_Net1", "byStore", filterPermStore, filterLayoutNet1, false, []string{"80079091"},
+			map[string]bool{"80079091": true, "82949216": false, "147298805": false}},
+		{"2.byCity_read_Net1", "byCity", filterPermCity, filterLayoutNet1, false, []string{"147298805", "82949216"},
+			map[string]bool{"80079091": false, "82949216": true, "147298805": true}},
+		{"3.byLayout_read_Net1", "byLayout", filterPermLayout, filterLayoutNet1, true, []string{"*"},
+			map[string]bool{"80079091": true, "82949216": true}},
+		{"4.byStore_read_Net2", "byStore", filterPermStore, filterLayoutNet2, false, []string{},
+			map[string]bool{"124804116": false}},
+		{"5.byCity_read_AnyLayout", "byCity", filterPermCity, "", false, []string{"147298805", "82949216"},
+			map[string]bool{"80079091": false, "82949216": true}},
+		// stores of the other layouts aren't allowed by the layout without restrictions
+		{"6.byLayout_read_AnyLayout", "byLayout", filterPermLayout, "*", false, []string{},
+			map[string]bool{"124804116": false, "80079091": false}},
+		{"7.byEmptyCity_read_Net1", "byEmptyCity", filterPermCity, filterLayoutNet1, false, []string{},
+			map[string]bool{"80079091": false}},
+		{"8.byEmptyCity_read_AnyLayout", "byEmptyCity", filterPermCity, "", false, []string{},
+			map[string]bool{"80079091": false}},
+		{"9.byAll_read_AnyLayout", "byAll", filterPermAll, "*", true, []string{"*"},
+			map[string]bool{"124804116": true}},
+		{"10.byStore_read_AnyLayout_notPermitted", "byStore", filterPermLayout, "", false, []string{"80079091"},
+			map[string]bool{"80079091": true, "124804116": false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("GET", "localhost", nil)
+			if err != nil {
+				t.Fatalf("make request error, %s", err)
+			}
+			request.Header.Add(XUserID, tt.uid)
+			request.Header.Add(XUserPermission, b64.StdEncoding.EncodeToString([]byte(tt.perm)))
+			got, err := m.AllowedStores(request, tt.layout, acl.ActionRead)
+			if err != nil {
+				t.Fatalf("Manager.AllowedStores() unexpected error, %s", err)
+			}
+			if got.All != tt.wantAll {
+				t.Errorf("Manager.AllowedStores().All = %v, want %v", got.All, tt.wantAll)
+			}
+			if !reflect.DeepEqual(got.List(), tt.wantList) {
+				t.Errorf("Manager.AllowedStores().List() = %v, want %v", got.List(), tt.wantList)
+			}
+			for storeID, want := range tt.wantAllow {
+				if allow := got.Allow(storeID); allow != want {
+					t.Errorf("Manager.AllowedStores().Allow(%s) = %v, want %v", storeID, allow, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package permission
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission/cache"
+)
+
+// pruneEvery count of the remembered users after which expired ids are pruned.
+const pruneEvery int = 1024
+
+// cacheDeleter optional behavior of the cache, removes cached user.
+type cacheDeleter interface {
+	Del(ctx context.Context, key string) error
+}
+
+// cacheKeysLister optional behavior of the cache, lists keys of the cached users,
+// shared caches know users cached by other replicas.
+type cacheKeysLister interface {
+	Keys(ctx context.Context) ([]string, error)
+}
+
+// InvalidateUser removes cached ACLs of the user,
+// they will be rebuilt from the repo on the next request.
+func (m *Manager) InvalidateUser(ctx context.Context, userID string) error {
+	if d, ok := m.c.(cacheDeleter); ok {
+		if err := d.Del(ctx, userID); err != nil && !errors.Is(err, cache.ErrNotFound) {
+			return errors.Wrapf(err, "delete user %s from cache failed", userID)
+		}
+		m.mu.Lock()
+		delete(m.known, userID)
+		m.mu.Unlock()
+		return nil
+	}
+	m.mu.Lock()
+	m.stale[userID] = struct{}{}
+	m.mu.Unlock()
+	return nil
+}
+
+// InvalidateLayout removes cached ACLs of the users which have rules for the layout,
+// empty or asterisk layoutID invalidates all cached users.
+// Returns count of the invalidated users.
+func (m *Manager) InvalidateLayout(ctx context.Context, layoutID string) (int, error) {
+	keys, err := m.cachedKeys(ctx)
+	if err != nil {
+		return 0, errors.WithMessage(err, "list cached users failed")
+	}
+	cnt := 0
+	for _, key := range keys {
+		if layoutID != "" && layoutID != "*" {
+			u, err := m.c.Get(ctx, key)
+			if err != nil {
+				if errors.Is(err, cache.ErrNotFound) {
+					m.forget(key)
+					continue
+				}
+				return cnt, errors.Wrapf(err, "get user %s from cache failed", key)
+			}
+			if _, ok := u.ACLs[cache.LayoutID(layoutID)]; !ok {
+				continue
+			}
+		}
+		if err := m.InvalidateUser(ctx, key); err != nil {
+			return cnt, err
+		}
+		cnt++
+	}
+	return cnt, nil
+}
+
+// cachedKeys returns keys of the cached users from the cache if it can list them,
+// otherwise keys remembered by this Manager.
+func (m *Manager) cachedKeys(ctx context.Context) ([]string, error) {
+	if l, ok := m.c.(cacheKeysLister); ok {
+		return l.Keys(ctx)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneKnown()
+	keys := make([]string, 0, len(m.known))
+	for key := range m.known {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// remember registers cached user id with expiration.
+func (m *Manager) remember(userID string, expire time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.known[userID] = time.Now().Add(expire)
+	delete(m.stale, userID)
+	if len(m.known)%pruneEvery == 0 {
+		m.pruneKnown()
+	}
+}
+
+// forget removes user id from the registered.
+func (m *Manager) forget(userID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.known, userID)
+	delete(m.stale, userID)
+}
+
+// isStale reports whether user was invalidated,
+// the mark is cleared by remember after ACLs of the user rebuilt.
+func (m *Manager) isStale(userID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.stale[userID]
+	return ok
+}
+
+// pruneKnown removes expired ids, must be called under lock.
+func (m *Manager) pruneKnown() {
+	now := time.Now()
+	for key, expireAt := range m.known {
+		if now.After(expireAt) {
+			delete(m.known, key)
+			delete(m.stale, key)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package permission
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/acl"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/permission/cache"
+)
+
+// fakeCache cache without optional behaviors.
+type fakeCache struct {
+	mu    sync.Mutex
+	users map[string]*cache.User
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{users: make(map[string]*cache.User)}
+}
+
+func (fc *fakeCache) Get(ctx context.Context, key string) (*cache.User, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	u, ok := fc.users[key]
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	return u, nil
+}
+
+func (fc *fakeCache) Add(ctx context.Context, key string, u *cache.User, expire time.Duration) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.users[key] = u
+	return nil
+}
+
+// fakeDelCache cache with delete and keys behaviors.
+type fakeDelCache struct {
+	*fakeCache
+}
+
+func (fc fakeDelCache) Del(ctx context.Context, key string) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	delete(fc.users, key)
+	return nil
+}
+
+func (fc fakeDelCache) Keys(ctx context.Context) ([]string, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	keys := make([]string, 0, len(fc.users))
+	for key := range fc.users {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func addTestUser(t *testing.T, m *Manager, id, layoutID string) {
+	t.Helper()
+	u := &cache.User{ID: id, ACLs: make(map[cache.LayoutID]acl.EntityItems, 1)}
+	u.AddItems(cache.LayoutID(layoutID), true, acl.Actions{acl.ActionRead}, []string{"1"}, acl.EntityKindStores)
+	if err := m.c.Add(context.Background(), id, u, time.Hour); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	m.remember(id, time.Hour)
+}
+
+func TestManager_InvalidateLayout_Stale(t *testing.T) {
+	m := NewManager(newFakeCache(), nil, DefaultAllow, time.Hour)
+	addTestUser(t, m, "1", "118416189")
+	addTestUser(t, m, "2", "37664168")
+	cnt, err := m.InvalidateLayout(context.Background(), "118416189")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if cnt != 1 {
+		t.Errorf("InvalidateLayout() = %d, want 1", cnt)
+	}
+	if !m.isStale("1") {
+		t.Error("user 1 expected stale")
+	}
+	if m.isStale("2") {
+		t.Error("user 2 expected not stale")
+	}
+	// rebuilt user is not stale anymore
+	m.remember("1", time.Hour)
+	if m.isStale("1") {
+		t.Error("user 1 expected not stale after rebuild")
+	}
+}
+
+func TestManager_InvalidateLayout_Delete(t *testing.T) {
+	c := fakeDelCache{newFakeCache()}
+	m := NewManager(c, nil, DefaultAllow, time.Hour)
+	addTestUser(t, m, "1", "118416189")
+	addTestUser(t, m, "2", "37664168")
+	addTestUser(t, m, "3", "118416189")
+	cnt, err := m.InvalidateLayout(context.Background(), "*")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if cnt != 3 {
+		t.Errorf("InvalidateLayout(*) = %d, want 3", cnt)
+	}
+	keys, _ := c.Keys(context.Background())
+	if len(keys) != 0 {
+		t.Errorf("expected empty cache, got %v", keys)
+	}
+}
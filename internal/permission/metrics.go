@@ -0,0 +1,37 @@
+package permission
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	userKnown     string = "known"
+	userAnonymous string = "anonymous"
+)
+
+var (
+	cacheHits = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "permission_cache_hits_total",
+			Help: "Count of the users ACLs found in the permission cache",
+		},
+		[]string{"user"},
+	)
+
+	cacheMisses = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "permission_cache_misses_total",
+			Help: "Count of the users ACLs not found in the permission cache",
+		},
+		[]string{"user"},
+	)
+
+	aclBuilds = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "permission_acl_builds_total",
+			Help: "Count of the users ACLs built from the layout repos by result",
+		},
+		[]string{"user", "result"},
+	)
+)
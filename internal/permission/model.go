@@ -4,6 +4,8 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/pkg/errors"
+
 	"git.countmax.ru/countmax/layoutconfig.api/internal/acl"
 )
 
@@ -13,6 +15,8 @@ var (
 	stRegionRE  = regexp.MustCompile(`(?m)[\*\w.]+:data.counting:regions:(\S+)`)
 	stCountryRE = regexp.MustCompile(`(?m)[\*\w.]+:data.counting:countries:(\S+)`)
 	stStoreRE   = regexp.MustCompile(`(?m)[\*\w.]+:data.counting:stores:(\S+)`)
+	adminRE     = regexp.MustCompile(`^[\*\w.]+:data.counting:admin$`)
+	resourceRE  = regexp.MustCompile(`^[\*\w.]+:data.counting:(layouts|cities|regions|countries|stores):\S+$`)
 )
 
 type Permissions []Permission
@@ -99,6 +103,21 @@ func (p Permission) getStores() (byStores, byCities, byRegion, byCountry string)
 	return
 }
 
+func (p Permission) checkAdmin() bool {
+	for _, r := range p.Resources {
+		if adminRE.MatchString(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasStoreRules reports whether permission restricts stores by stores, cities, regions or countries.
+func (p Permission) hasStoreRules() bool {
+	byStores, byCities, byRegion, byCountry := p.getStores()
+	return byStores != "" || byCities != "" || byRegion != "" || byCountry != ""
+}
+
 func (ps *Permissions) CheckLayout(layoutID string, action acl.Action) bool {
 	allow := false
 	for _, p := range *ps {
@@ -136,3 +155,56 @@ func (ps *Permissions) StoresFilters(action string) (byStores, byCities, byRegio
 	}
 	return
 }
+
+// CheckAdmin checks permission to administration of the service, it is granted only explicitly
+// by resource <namespace>:data.counting:admin, permissions to all layouts don't grant it.
+func (ps *Permissions) CheckAdmin(action acl.Action) bool {
+	allow := false
+	for _, p := range *ps {
+		if p.Actions.HasAction(action) && p.checkAdmin() {
+			allow = p.Effect.IsAllow()
+		}
+	}
+	return allow
+}
+
+// GrantsAdmin reports whether any action of the administration of the service is allowed.
+func (ps Permissions) GrantsAdmin() bool {
+	for _, p := range ps {
+		if p.checkAdmin() && p.Effect.IsAllow() {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks permissions are made of the known resources, actions and effects.
+func (ps Permissions) Validate() error {
+	if len(ps) == 0 {
+		return errors.New("permissions are empty")
+	}
+	for i, p := range ps {
+		if len(p.Resources) == 0 {
+			return errors.Errorf("permission %d has no resources", i)
+		}
+		for _, r := range p.Resources {
+			if !resourceRE.MatchString(r) && !adminRE.MatchString(r) {
+				return errors.Errorf("permission %d has unknown resource %q", i, r)
+			}
+		}
+		if len(p.Actions) == 0 {
+			return errors.Errorf("permission %d has no actions", i)
+		}
+		for _, a := range p.Actions {
+			switch a {
+			case acl.ActionCreate, acl.ActionRead, acl.ActionUpdate, acl.ActionDelete, acl.ActionAsterisk:
+			default:
+				return errors.Errorf("permission %d has unknown action %q", i, a)
+			}
+		}
+		if p.Effect != acl.EffectAllow && p.Effect != acl.EffectDeny {
+			return errors.Errorf("permission %d has unknown effect %q", i, p.Effect)
+		}
+	}
+	return nil
+}
@@ -118,3 +118,35 @@ func TestPermissions_StoresFilters(t *testing.T) {
 		})
 	}
 }
+
+func TestPermissions_Validate(t *testing.T) {
+	read := Permission{Resources: Resources{"watcom.ru:data.counting:layouts:118416189", "watcom.ru:data.counting:stores:77"},
+		Actions: acl.Actions{"read"}, Effect: "allow"}
+	admin := Permission{Resources: Resources{"watcom.ru:data.counting:admin"}, Actions: acl.Actions{"*"}, Effect: "allow"}
+	tests := []struct {
+		name      string
+		ps        Permissions
+		wantErr   bool
+		wantAdmin bool
+	}{
+		{"read_stores", Permissions{read}, false, false},
+		{"admin", Permissions{read, admin}, false, true},
+		{"denied_admin", Permissions{read, {Resources: admin.Resources, Actions: admin.Actions, Effect: "deny"}}, false, false},
+		{"default_allow", DefaultAllow, false, false},
+		{"empty", Permissions{}, true, false},
+		{"unknown_resource", Permissions{{Resources: Resources{"watcom.ru:data.counting:users:1"}, Actions: read.Actions, Effect: "allow"}}, true, false},
+		{"unknown_action", Permissions{{Resources: read.Resources, Actions: acl.Actions{"actions:read"}, Effect: "allow"}}, true, false},
+		{"unknown_effect", Permissions{{Resources: read.Resources, Actions: read.Actions, Effect: "permit"}}, true, false},
+		{"no_actions", Permissions{{Resources: read.Resources, Effect: "allow"}}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.ps.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Permissions.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := tt.ps.GrantsAdmin(); got != tt.wantAdmin {
+				t.Errorf("Permissions.GrantsAdmin() = %v, want %v", got, tt.wantAdmin)
+			}
+		})
+	}
+}
@@ -3,11 +3,13 @@ package permission
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	b64 "encoding/base64"
+	"encoding/hex"
 	"encoding/json"
-	"math/rand"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"git.countmax.ru/pkg/logging"
@@ -29,6 +31,11 @@ const (
 	storesByRegion   string        = "byRegion"
 	storesByCountry  string        = "byCountry"
 	fillStoreTimeout time.Duration = 30 * time.Second
+	// anonymousPrefix prefix of the user id for requests without X-User-ID
+	anonymousPrefix string = "anonymous:"
+	// noEntities id of the denied item of the store rules resolved to no one store,
+	// empty list of the items means no restrictions
+	noEntities string = "-"
 )
 
 var (
@@ -53,7 +60,11 @@ type Manager struct {
 	c      cache.RepoInterface
 	repoM  *connmanager.Manager
 	policy Permissions
+	pmu    sync.RWMutex // guards policy, it is replaced on config reload
 	expire time.Duration
+	mu     *sync.Mutex
+	known  map[string]time.Time // cached user ids and them expiration
+	stale  map[string]struct{}  // invalidated user ids, for caches without delete
 }
 
 // NewManager builder for.
@@ -61,25 +72,49 @@ func NewManager(c cache.RepoInterface,
 	repoM *connmanager.Manager, policy Permissions, expireCache time.Duration) *Manager {
 	return &Manager{
 		c: c, repoM: repoM, policy: policy, expire: expireCache,
+		mu:    &sync.Mutex{},
+		known: make(map[string]time.Time),
+		stale: make(map[string]struct{}),
 	}
 }
 
 // FromRequest extracts permission value from http request,
 // if permissions not passed use policy.
 func (m *Manager) FromRequest(r *http.Request) Permissions {
-	log := logging.FromContext(r.Context())
-	bts, err := b64.StdEncoding.DecodeString(r.Header.Get(XUserPermission))
+	perms, err := fromHeader(r)
 	if err != nil {
-		log.Warnf("extract permissions from request failed %s, set default policy", err)
-		return m.policy
+		logging.FromContext(r.Context()).Warnf("%s, set default policy", err)
+		return m.Policy()
 	}
-	perms := make(Permissions, 0, 2)
-	err = json.NewDecoder(bytes.NewReader(bts)).Decode(&perms)
+	return perms
+}
+
+// CheckAdmin checks permission to administration of the service by permissions passed in the request only,
+// default policy never grants it.
+func (m *Manager) CheckAdmin(r *http.Request, action acl.Action) bool {
+	perms, err := fromHeader(r)
 	if err != nil {
-		log.Warnf("decode permissions failed %s, set default policy", err)
-		return m.policy
+		logging.FromContext(r.Context()).Warnf("%s, admin access denied", err)
+		return false
 	}
-	return perms
+	return perms.CheckAdmin(action)
+}
+
+// fromHeader decodes permissions of the request.
+func fromHeader(r *http.Request) (Permissions, error) {
+	value := r.Header.Get(XUserPermission)
+	if value == "" {
+		return nil, errors.New("permissions not passed")
+	}
+	bts, err := b64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.Errorf("extract permissions from request failed %s", err)
+	}
+	perms := make(Permissions, 0, 2)
+	if err := json.NewDecoder(bytes.NewReader(bts)).Decode(&perms); err != nil {
+		return nil, errors.Errorf("decode permissions failed %s", err)
+	}
+	return perms, nil
 }
 
 // CheckLayout checks permission to Layout,
@@ -95,9 +130,18 @@ func (m *Manager) SetPolicy(p Permissions) {
 	if len(p) == 0 {
 		return
 	}
+	m.pmu.Lock()
+	defer m.pmu.Unlock()
 	m.policy = p
 }
 
+// Policy returns current default policy.
+func (m *Manager) Policy() Permissions {
+	m.pmu.RLock()
+	defer m.pmu.RUnlock()
+	return m.policy
+}
+
 // CheckStore checks access right to action
 // by exists or not in the store list.
 func (m *Manager) CheckStore(r *http.Request, layoutID, storeID string, action acl.Action) bool {
@@ -216,23 +260,28 @@ func (m *Manager) filteredEnterIDSlice(r *http.Request,
 
 func (m *Manager) getUser(r *http.Request) (*cache.User, error) {
 	uid := m.getUserID(r)
-	u, err := m.c.Get(r.Context(), uid)
-	if err != nil && !errors.Is(err, cache.ErrNotFound) {
-		return nil, errors.Errorf("get from cache error %s", err)
-	}
-	if u != nil {
-		return u, nil
+	kind := userKind(uid)
+	if !m.isStale(uid) {
+		u, err := m.c.Get(r.Context(), uid)
+		if err != nil && !errors.Is(err, cache.ErrNotFound) {
+			return nil, errors.Errorf("get from cache error %s", err)
+		}
+		if u != nil {
+			cacheHits.WithLabelValues(kind).Inc()
+			return u, nil
+		}
 	}
+	cacheMisses.WithLabelValues(kind).Inc()
 
 	ctx, cancel := context.WithTimeout(r.Context(), fillStoreTimeout)
 	defer cancel()
 
-	err = m.addUserToCache(ctx, uid, m.FromRequest(r), m.expire)
+	err := m.addUserToCache(ctx, uid, m.FromRequest(r), m.expire)
 	if err != nil {
 		return nil, errors.Errorf("fillStores error %s", err)
 	}
 
-	u, err = m.c.Get(ctx, uid)
+	u, err := m.c.Get(ctx, uid)
 	if err != nil && !errors.Is(err, cache.ErrNotFound) {
 		return nil, errors.Errorf("get from cache error %s", err)
 	}
@@ -248,11 +297,30 @@ func (m *Manager) getUserID(r *http.Request) string {
 		uid = r.Header.Get(XUserIDlow)
 	}
 	if uid == "" {
-		uid = randStringRunes(24)
+		uid = m.anonymousID(r)
 	}
 	return uid
 }
 
+// anonymousID makes stable id for the request without user id by hash of the permissions,
+// anonymous requests with same permissions share cached ACLs.
+func (m *Manager) anonymousID(r *http.Request) string {
+	bts, err := json.Marshal(m.FromRequest(r))
+	if err != nil {
+		bts = []byte(r.Header.Get(XUserPermission))
+	}
+	sum := sha256.Sum256(bts)
+	return anonymousPrefix + hex.EncodeToString(sum[:])
+}
+
+// userKind returns kind of the user for metrics.
+func userKind(userID string) string {
+	if strings.HasPrefix(userID, anonymousPrefix) {
+		return userAnonymous
+	}
+	return userKnown
+}
+
 // addUserToCache makes user, fills ACLs, puts to cache.
 func (m *Manager) addUserToCache(ctx context.Context,
 	userID string, permissions Permissions, cacheExpire time.Duration) error {
@@ -262,10 +330,17 @@ func (m *Manager) addUserToCache(ctx context.Context,
 	// fill ACLs
 	err := m.userFillACL(ctx, user, permissions)
 	if err != nil {
+		aclBuilds.WithLabelValues(userKind(userID), "error").Inc()
 		return errors.WithMessage(err, "user fill ACL error")
 	}
+	aclBuilds.WithLabelValues(userKind(userID), "ok").Inc()
 	// put to cache
-	return m.c.Add(ctx, userID, user, cacheExpire)
+	err = m.c.Add(ctx, userID, user, cacheExpire)
+	if err != nil {
+		return err
+	}
+	m.remember(userID, cacheExpire)
+	return nil
 }
 
 func (m *Manager) userFillACL(ctx context.Context, user *cache.User, permissions Permissions) error {
@@ -294,6 +369,13 @@ func (m *Manager) userFillACLByLayout(ctx context.Context,
 	if err != nil {
 		return errors.WithMessage(err, "getEntitiesFromRepo error")
 	}
+	if allow && len(storeIDs) == 0 && permission.hasStoreRules() {
+		// store rules resolved to no one store deny all stores and entrances instead of no restrictions
+		user.AddItems(cache.LayoutID(layoutID), allow, permission.Actions, layoutIDs, acl.EntityKindLayouts)
+		user.AddItems(cache.LayoutID(layoutID), false, permission.Actions, []string{noEntities}, acl.EntityKindStores)
+		user.AddItems(cache.LayoutID(layoutID), false, permission.Actions, []string{noEntities}, acl.EntityKindEnters)
+		return nil
+	}
 
 	user.AddItems(cache.LayoutID(layoutID), allow, permission.Actions, layoutIDs, acl.EntityKindLayouts)
 	user.AddItems(cache.LayoutID(layoutID), allow, permission.Actions, storeIDs, acl.EntityKindStores)
@@ -328,6 +410,9 @@ func (m *Manager) getEntitiesFromRepo(ctx context.Context,
 		return storeIDs, entranceIDs, errors.Errorf("get stores byCountry error, %s", err)
 	}
 	storeIDs = append(storeIDs, listByCountry...)
+	if len(storeIDs) == 0 && permission.hasStoreRules() {
+		return storeIDs, entranceIDs, nil
+	}
 	entranceIDs, err = m.getEntrances(ctx, layoutID, storeIDs)
 	if err != nil {
 		return storeIDs, entranceIDs, errors.Errorf("get entrances error, %s", err)
@@ -364,16 +449,3 @@ func (m *Manager) getStores(ctx context.Context, kind, list, layoutID string) ([
 	}
 	return nil, errors.Errorf("undefined kind %s", kind)
 }
-
-// helpers
-
-var letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890-+!#$%^&*()_~")
-
-func randStringRunes(n int) string {
-	b := make([]rune, n)
-	for i := range b {
-		// nolint:gosec
-		b[i] = letterRunes[rand.Intn(len(letterRunes))]
-	}
-	return string(b)
-}
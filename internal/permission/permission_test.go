@@ -38,6 +38,12 @@ import (
 	s.apiMallZoneByID
 [ ] CheckRenter
 	s.apiRenterByID
+[x] AllowedStores(r *http.Request, layoutID string, action acl.Action) (StoresFilter, error)
+	s.apiChainStoresDataQueue
+	s.apiChainZonesDataQueue
+	s.apiChainZonesStates
+	s.apiChainDeviceTracks
+	s.apiGetScreenshots
 [x] FilteredStores(r *http.Request, layoutID, action, inputList string) (filteredList string, err error)
 	s.apiChainStoresDataAttendance
 	s.apiChainStoresDataQueue
@@ -492,6 +498,61 @@ func TestManager_FilteredStores(t *testing.T) {
 	}
 }
 
+// TestManager_AllowedStores users limited by stores or cities can not read other stores.
+func TestManager_AllowedStores(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+	css := []string{testCSNets}
+	m, err := preparePM(ctx, css, permission.DefaultAllow)
+	if err != nil {
+		t.Errorf("unexpected error, %s", err)
+		return
+	}
+	//
+	tests := []struct {
+		name      string
+		perm      string
+		layout    string
+		wantAll   bool
+		wantAllow map[string]bool
+	}{
+		{"1.allowNet1Store_read_Net1", permChainAndStore82949216ReaderLDemoNet1, layoutDemoNet1, false,
+			map[string]bool{storeNet1Spb1: true, storeNet1Spb2: false, storeNet1Msk1: false}},
+		{"2.allowNet1SPBCity_read_Net1", permChainAndCitySPBReaderLDemoNet1, layoutDemoNet1, false,
+			map[string]bool{storeNet1Spb1: true, storeNet1Spb2: true, storeNet1Msk1: false, storeNet1Msk2: false}},
+		{"3.allowNet2MSKCity_read_Net2", permChainAndCityMSKReaderLDemoNet2, layoutDemoNet2, false,
+			map[string]bool{storeNet2Msk1: true, storeNet2Msk2: true, storeNet2Spb1: false}},
+		{"4.allowNet2MSKCity_read_Net1", permChainAndCityMSKReaderLDemoNet2, layoutDemoNet1, false,
+			map[string]bool{storeNet1Msk1: false, storeNet1Spb1: false}},
+		{"5.allowNet1_read_Net1", permChainReaderLDemoNet1, layoutDemoNet1, true,
+			map[string]bool{storeNet1Msk1: true, storeNet1Spb1: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("GET", "localhost", nil)
+			if err != nil {
+				t.Errorf("make request error, %s", err)
+				return
+			}
+			request.Header.Add(permission.XUserID, randStringRunes(18))
+			request.Header.Add(permission.XUserPermission, b64.StdEncoding.EncodeToString([]byte(tt.perm)))
+			got, err := m.AllowedStores(request, tt.layout, acl.ActionRead)
+			if err != nil {
+				t.Errorf("Manager.AllowedStores() unexpected error, %s", err)
+				return
+			}
+			if got.All != tt.wantAll {
+				t.Errorf("Manager.AllowedStores().All = %v, want %v", got.All, tt.wantAll)
+			}
+			for storeID, want := range tt.wantAllow {
+				if allow := got.Allow(storeID); allow != want {
+					t.Errorf("Manager.AllowedStores().Allow(%s) = %v, want %v", storeID, allow, want)
+				}
+			}
+		})
+	}
+}
+
 // enters test
 
 func TestManager_CheckEnter(t *testing.T) {
@@ -0,0 +1,187 @@
+package queuerule
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/pkg/logging"
+)
+
+const (
+	loc         string = "ru"
+	anyID       string = "*"
+	dateLayout  string = "2006-01-02"
+	fetchLimit  int64  = 999999
+	minPollTime        = 10 * time.Second
+)
+
+// Repos registered layout repos.
+type Repos interface {
+	Repos() []domain.LayoutRepo
+	Routed(layoutID string, repo domain.LayoutRepo) bool
+}
+
+// Poller reads thresholds of the behaviors and live queue data of the repos and passes them to Evaluator.
+type Poller struct {
+	repos Repos
+	ev    *Evaluator
+}
+
+// NewPoller builder for Poller.
+func NewPoller(repos Repos, sink EventSink) *Poller {
+	return &Poller{repos: repos, ev: NewEvaluator(sink)}
+}
+
+// Run restores open events and polls repos every period until ctx is done.
+func (p *Poller) Run(ctx context.Context, period time.Duration) {
+	log := logging.FromContext(ctx)
+	if period < minPollTime {
+		period = minPollTime
+	}
+	log.Debugf("start queue rules with period %v", period)
+	defer log.Debug("stop queue rules")
+	if err := p.ev.Restore(ctx); err != nil {
+		log.Errorf("queue rules restore error, %s", err)
+	}
+	t := time.NewTicker(period)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			pctx, cancel := context.WithTimeout(ctx, period)
+			p.Poll(pctx)
+			cancel()
+		}
+	}
+}
+
+// Poll evaluates thresholds of the all repos once, failed repo doesn't stop others.
+func (p *Poller) Poll(ctx context.Context) {
+	log := logging.FromContext(ctx)
+	for _, repo := range p.repos.Repos() {
+		if err := p.pollRepo(ctx, repo); err != nil {
+			log.Errorf("queue rules of %s error, %s", repo.Dest(), err)
+		}
+	}
+}
+
+func (p *Poller) pollRepo(ctx context.Context, repo domain.LayoutRepo) error {
+	log := logging.FromContext(ctx)
+	dt := time.Now().Format(dateLayout)
+	layouts, _, err := repo.FindLayouts(ctx, loc, dt, 0, fetchLimit)
+	if err != nil {
+		return errors.Wrap(err, "repo.FindLayouts failed")
+	}
+	th := NewThresholds()
+	routed := make(map[string]bool, len(layouts))
+	for _, l := range layouts {
+		if !p.repos.Routed(l.ID, repo) {
+			continue
+		}
+		routed[l.ID] = true
+		bhv, err := repo.FindBehaviorByLayoutID(ctx, l.ID)
+		if err != nil {
+			log.Warnf("repo.FindBehaviorByLayoutID(%s) error, %s", l.ID, err)
+			continue
+		}
+		if bhv != nil && bhv.BehaviorConfig != nil {
+			th.Add(l.ID, bhv.BehaviorConfig.QueueThresholds)
+		}
+	}
+	if th.Empty() {
+		return nil
+	}
+	if err := p.pollStores(ctx, repo, dt, th, routed); err != nil {
+		return err
+	}
+	return p.pollBlocks(ctx, repo, dt, th, routed)
+}
+
+// pollStores evaluates queues of the stores by store and layout thresholds.
+func (p *Poller) pollStores(ctx context.Context, repo domain.LayoutRepo, dt string, th *Thresholds,
+	routed map[string]bool) error {
+	//
+	stores, _, err := repo.FindChainStores(ctx, loc, dt, anyID, anyID, 0, fetchLimit, anyID)
+	if err != nil {
+		return errors.Wrap(err, "repo.FindChainStores failed")
+	}
+	layoutOf := make(map[string]string, len(stores))
+	for _, st := range stores {
+		if routed[st.LayoutID] {
+			layoutOf[st.StoreID] = st.LayoutID
+		}
+	}
+	storeID := ""
+	data, err := repo.FindChainStoresDataQueueNow(ctx, &storeID)
+	if err != nil {
+		return errors.Wrap(err, "repo.FindChainStoresDataQueueNow failed")
+	}
+	for _, d := range data {
+		layoutID, ok := layoutOf[d.StoreID]
+		if !ok {
+			continue
+		}
+		point, ok := lastPoint(d.Points)
+		if !ok {
+			continue
+		}
+		target := Target{LayoutID: layoutID, StoreID: d.StoreID}
+		rule, ok := th.Match(target)
+		if err := p.ev.Observe(ctx, Sample{Target: target, Time: point.Time, Value: float64(point.Value)}, rule, ok); err != nil {
+			logging.FromContext(ctx).Errorf("queue rule of %s error, %s", target.Key(), err)
+		}
+	}
+	return nil
+}
+
+// pollBlocks evaluates queues of the blocks of service channels by their thresholds.
+func (p *Poller) pollBlocks(ctx context.Context, repo domain.LayoutRepo, dt string, th *Thresholds,
+	routed map[string]bool) error {
+	//
+	log := logging.FromContext(ctx)
+	for _, zoneID := range th.Blocks() {
+		zone, err := repo.FindChainZoneByID(ctx, loc, zoneID, dt)
+		if err != nil || zone == nil || !routed[zone.LayoutID] {
+			continue
+		}
+		id := zoneID
+		data, err := repo.FindChainZonesDataQueueNow(ctx, &id)
+		if err != nil {
+			return errors.Wrapf(err, "repo.FindChainZonesDataQueueNow(%s) failed", zoneID)
+		}
+		for _, d := range data {
+			if d.ZoneID != zoneID {
+				continue
+			}
+			point, ok := lastPoint(d.Points)
+			if !ok {
+				continue
+			}
+			target := Target{LayoutID: zone.LayoutID, StoreID: zone.StoreID, ZoneID: zoneID}
+			rule, ok := th.Match(target)
+			if err := p.ev.Observe(ctx, Sample{Target: target, Time: point.Time, Value: float64(point.Value)}, rule, ok); err != nil {
+				log.Errorf("queue rule of %s error, %s", target.Key(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// lastPoint returns the latest measurement.
+func lastPoint(points domain.QueueDataPoints) (domain.QueueDataPoint, bool) {
+	if len(points) == 0 {
+		return domain.QueueDataPoint{}, false
+	}
+	last := points[0]
+	for _, pt := range points[1:] {
+		if pt.Time.After(last.Time) {
+			last = pt
+		}
+	}
+	return last, true
+}
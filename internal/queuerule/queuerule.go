@@ -0,0 +1,296 @@
+// Package queuerule evaluates queue thresholds of the behaviors by live queue data,
+// queue exceeding threshold sequence_length measurements in a row opens queue.threshold.exceeded event,
+// queue under threshold sequence_length measurements in a row resolves it.
+package queuerule
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstate"
+)
+
+const (
+	// EventKey key of the events produced by thresholds
+	EventKey string = "queue.threshold.exceeded"
+	// SourceKind kind of the source of the events
+	SourceKind string = "queue"
+	// Actor creator of the events and actor of their transitions
+	Actor string = "queuerule"
+)
+
+// scopes of the thresholds from the most specific
+const (
+	ScopeBlock  string = "block_service_channels"
+	ScopeStore  string = "store"
+	ScopeLayout string = "layout"
+)
+
+// names of the source params of the events
+const (
+	ParamTarget         string = "target"
+	ParamLayoutID       string = "layout_id"
+	ParamStoreID        string = "store_id"
+	ParamZoneID         string = "zone_id"
+	ParamScope          string = "scope"
+	ParamValue          string = "value"
+	ParamThreshold      string = "threshold"
+	ParamSequenceLength string = "sequence_length"
+)
+
+// Rule threshold of the queue length applied to the measurements.
+type Rule struct {
+	Scope          string
+	ScopeID        string
+	Threshold      float64
+	SequenceLength uint
+}
+
+// sequence returns count of the measurements in a row to change state, at least one.
+func (r Rule) sequence() uint {
+	if r.SequenceLength == 0 {
+		return 1
+	}
+	return r.SequenceLength
+}
+
+// Thresholds rules of the behaviors by scopes, thresholds without value are ignored.
+type Thresholds struct {
+	layouts map[string]Rule
+	stores  map[string]Rule
+	blocks  map[string]Rule
+}
+
+// NewThresholds builder for empty Thresholds.
+func NewThresholds() *Thresholds {
+	return &Thresholds{layouts: make(map[string]Rule), stores: make(map[string]Rule), blocks: make(map[string]Rule)}
+}
+
+// Add adds thresholds of the behavior of the layout, layout threshold without layout_id belongs to the layout.
+func (t *Thresholds) Add(layoutID string, qt *domain.QueueThresholds) {
+	if qt == nil {
+		return
+	}
+	for _, l := range qt.Layouts {
+		id := l.LayoutID
+		if id == "" {
+			id = layoutID
+		}
+		if l.Threshold > 0 {
+			t.layouts[id] = Rule{Scope: ScopeLayout, ScopeID: id, Threshold: l.Threshold, SequenceLength: l.SequenceLength}
+		}
+	}
+	for _, st := range qt.Stores {
+		if st.StoreID != "" && st.Threshold > 0 {
+			t.stores[st.StoreID] = Rule{Scope: ScopeStore, ScopeID: st.StoreID, Threshold: st.Threshold,
+				SequenceLength: st.SequenceLength}
+		}
+	}
+	for _, b := range qt.BlocksServiceChannels {
+		if b.BlockServiceChannelsID != "" && b.Threshold > 0 {
+			t.blocks[b.BlockServiceChannelsID] = Rule{Scope: ScopeBlock, ScopeID: b.BlockServiceChannelsID,
+				Threshold: b.Threshold, SequenceLength: b.SequenceLength}
+		}
+	}
+}
+
+// Empty reports whether there are no thresholds.
+func (t *Thresholds) Empty() bool {
+	return len(t.layouts) == 0 && len(t.stores) == 0 && len(t.blocks) == 0
+}
+
+// Blocks returns ids of the blocks of service channels with thresholds.
+func (t *Thresholds) Blocks() []string {
+	ids := make([]string, 0, len(t.blocks))
+	for id := range t.blocks {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Match returns the most specific rule of the target:
+// block of service channels for the zone queue, store then layout for the store queue.
+func (t *Thresholds) Match(target Target) (Rule, bool) {
+	if target.ZoneID != "" {
+		r, ok := t.blocks[target.ZoneID]
+		return r, ok
+	}
+	if r, ok := t.stores[target.StoreID]; ok {
+		return r, true
+	}
+	r, ok := t.layouts[target.LayoutID]
+	return r, ok
+}
+
+// Target queue measured as a whole: store or zone of the store.
+type Target struct {
+	LayoutID string
+	StoreID  string
+	ZoneID   string
+}
+
+// Key returns unique key of the target.
+func (t Target) Key() string {
+	if t.ZoneID != "" {
+		return "zone:" + t.ZoneID
+	}
+	return "store:" + t.StoreID
+}
+
+// Sample measurement of the queue length of the target.
+type Sample struct {
+	Target Target
+	Time   time.Time
+	Value  float64
+}
+
+// EventSink storage of the events produced by rules.
+type EventSink interface {
+	Create(ctx context.Context, e domain.Event, actor string) (domain.Event, error)
+	Transition(ctx context.Context, id string, at time.Time, t domain.EventTransition) (domain.Event, error)
+	FindCreated(ctx context.Context, q eventstate.Query) (domain.Events, error)
+}
+
+// state of the target between measurements.
+type state struct {
+	last  time.Time
+	above uint
+	below uint
+	// eventID of the open event
+	eventID string
+}
+
+// Evaluator applies rules to the measurements, not safe for concurrent use.
+type Evaluator struct {
+	sink   EventSink
+	states map[string]*state
+}
+
+// NewEvaluator builder for Evaluator.
+func NewEvaluator(sink EventSink) *Evaluator {
+	return &Evaluator{sink: sink, states: make(map[string]*state)}
+}
+
+// Restore takes not resolved events produced earlier, so they are resolved instead of duplicated.
+func (ev *Evaluator) Restore(ctx context.Context) error {
+	events, err := ev.sink.FindCreated(ctx, eventstate.Query{Key: EventKey, Kind: eventstate.KindSystem,
+		Statuses: []string{domain.EventStatusNew, domain.EventStatusAcknowledged, domain.EventStatusAssigned}})
+	if err != nil {
+		return errors.WithMessage(err, "find open events failed")
+	}
+	for _, e := range events {
+		if e.Source == nil || e.Creator != Actor {
+			continue
+		}
+		if key := e.Source.GetParamByName(ParamTarget); key != "" {
+			ev.states[key] = &state{eventID: e.ID}
+		}
+	}
+	return nil
+}
+
+// Open returns id of the open event of the target.
+func (ev *Evaluator) Open(target Target) (string, bool) {
+	st, ok := ev.states[target.Key()]
+	if !ok || st.eventID == "" {
+		return "", false
+	}
+	return st.eventID, true
+}
+
+// Observe applies rule to the sample, measurements not newer than previous one are skipped,
+// open event of the target without rule is resolved at once.
+func (ev *Evaluator) Observe(ctx context.Context, s Sample, rule Rule, ok bool) error {
+	key := s.Target.Key()
+	st, exists := ev.states[key]
+	if !exists {
+		st = &state{}
+		ev.states[key] = st
+	}
+	if !st.last.IsZero() && !s.Time.After(st.last) {
+		return nil
+	}
+	st.last = s.Time
+	if !ok {
+		if st.eventID == "" {
+			delete(ev.states, key)
+			return nil
+		}
+		return ev.resolve(ctx, st, s, "threshold removed")
+	}
+	if s.Value > rule.Threshold {
+		st.below = 0
+		st.above++
+		if st.eventID != "" || st.above < rule.sequence() {
+			return nil
+		}
+		e, err := ev.sink.Create(ctx, newEvent(s, rule), Actor)
+		if err != nil {
+			return errors.WithMessagef(err, "create event of %s failed", key)
+		}
+		st.eventID = e.ID
+		return nil
+	}
+	st.above = 0
+	if st.eventID == "" {
+		return nil
+	}
+	st.below++
+	if st.below < rule.sequence() {
+		return nil
+	}
+	return ev.resolve(ctx, st, s, fmt.Sprintf("queue %s is not above threshold %s",
+		formatFloat(s.Value), formatFloat(rule.Threshold)))
+}
+
+// resolve resolves open event of the target, event resolved by user is just forgotten.
+func (ev *Evaluator) resolve(ctx context.Context, st *state, s Sample, comment string) error {
+	_, err := ev.sink.Transition(ctx, st.eventID, time.Time{},
+		domain.EventTransition{Action: eventstate.ActionResolve, Actor: Actor, Time: s.Time, Comment: comment})
+	if err != nil && !errors.Is(err, eventstate.ErrTransition) && !errors.Is(err, eventstate.ErrNotFound) {
+		return errors.WithMessagef(err, "resolve event %s failed", st.eventID)
+	}
+	st.eventID = ""
+	st.below = 0
+	return nil
+}
+
+// newEvent makes event of the target exceeding threshold of the rule.
+func newEvent(s Sample, rule Rule) domain.Event {
+	params := []domain.Param{
+		{Name: ParamTarget, Value: s.Target.Key()},
+		{Name: ParamLayoutID, Value: s.Target.LayoutID},
+		{Name: ParamStoreID, Value: s.Target.StoreID},
+	}
+	where := "store " + s.Target.StoreID
+	if s.Target.ZoneID != "" {
+		params = append(params, domain.Param{Name: ParamZoneID, Value: s.Target.ZoneID})
+		where = "zone " + s.Target.ZoneID + " of the " + where
+	}
+	params = append(params,
+		domain.Param{Name: ParamScope, Value: rule.Scope},
+		domain.Param{Name: ParamValue, Value: formatFloat(s.Value)},
+		domain.Param{Name: ParamThreshold, Value: formatFloat(rule.Threshold)},
+		domain.Param{Name: ParamSequenceLength, Value: strconv.FormatUint(uint64(rule.sequence()), 10)},
+	)
+	return domain.Event{
+		Key:       EventKey,
+		Kind:      eventstate.KindSystem,
+		Severity:  eventstate.SeverityAlarm,
+		EventTime: s.Time,
+		LayoutID:  s.Target.LayoutID,
+		StoreID:   s.Target.StoreID,
+		Message: fmt.Sprintf("queue %s of the %s exceeds %s threshold %s %d times in a row",
+			formatFloat(s.Value), where, rule.Scope, formatFloat(rule.Threshold), rule.sequence()),
+		Source: &domain.Source{Kind: SourceKind, Params: params},
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
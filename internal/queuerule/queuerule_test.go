@@ -0,0 +1,197 @@
+package queuerule_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstate"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/eventstate/mem"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/queuerule"
+)
+
+var t0 = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+var thresholds = &domain.QueueThresholds{
+	Layouts:               []domain.QTLayout{{Threshold: 10, SequenceLength: 2}},
+	Stores:                []domain.QTStore{{StoreID: "s1", Threshold: 5, SequenceLength: 3}},
+	BlocksServiceChannels: []domain.BlocksServiceChannel{{BlockServiceChannelsID: "z1", Threshold: 3}},
+}
+
+// fakeRepo layout 10 with stores s1, s2 and block of service channels z1 in the store s1.
+type fakeRepo struct {
+	domain.LayoutRepo
+	stores domain.StoresDataQueue
+	zones  domain.ZonesDataQueue
+}
+
+func (r *fakeRepo) Dest() string { return "fake" }
+
+func (r *fakeRepo) FindLayouts(ctx context.Context, loc, dt string, offset, limit int64) (domain.Layouts, int64, error) {
+	return domain.Layouts{{ID: "10"}}, 1, nil
+}
+
+func (r *fakeRepo) FindBehaviorByLayoutID(ctx context.Context, layoutID string) (*domain.Behavior, error) {
+	return &domain.Behavior{BehaviorConfig: &domain.BehaviorConfig{QueueThresholds: thresholds}}, nil
+}
+
+func (r *fakeRepo) FindChainStores(ctx context.Context, loc, date, layoutID, crmKey string, offset, limit int64,
+	filters string) (domain.ChainStores, int64, error) {
+	return domain.ChainStores{{StoreID: "s1", LayoutID: "10"}, {StoreID: "s2", LayoutID: "10"}}, 2, nil
+}
+
+func (r *fakeRepo) FindChainStoresDataQueueNow(ctx context.Context, storeID *string) (domain.StoresDataQueue, error) {
+	return r.stores, nil
+}
+
+func (r *fakeRepo) FindChainZoneByID(ctx context.Context, loc, zoneID, dt string) (*domain.ChainZone, error) {
+	return &domain.ChainZone{ZoneID: zoneID, LayoutID: "10", StoreID: "s1"}, nil
+}
+
+func (r *fakeRepo) FindChainZonesDataQueueNow(ctx context.Context, zoneID *string) (domain.ZonesDataQueue, error) {
+	return r.zones, nil
+}
+
+type fakeRepos struct{ repo *fakeRepo }
+
+func (r fakeRepos) Repos() []domain.LayoutRepo                          { return []domain.LayoutRepo{r.repo} }
+func (r fakeRepos) Routed(layoutID string, repo domain.LayoutRepo) bool { return true }
+
+func point(min int, value int32) domain.QueueDataPoints {
+	return domain.QueueDataPoints{{Time: t0.Add(time.Duration(min) * time.Minute), Value: value}}
+}
+
+func TestThresholds_Match(t *testing.T) {
+	th := queuerule.NewThresholds()
+	th.Add("10", thresholds)
+	tests := []struct {
+		name   string
+		target queuerule.Target
+		scope  string
+		ok     bool
+	}{
+		{"block", queuerule.Target{LayoutID: "10", StoreID: "s1", ZoneID: "z1"}, queuerule.ScopeBlock, true},
+		{"zone_without_block", queuerule.Target{LayoutID: "10", StoreID: "s1", ZoneID: "z2"}, "", false},
+		{"store", queuerule.Target{LayoutID: "10", StoreID: "s1"}, queuerule.ScopeStore, true},
+		{"layout", queuerule.Target{LayoutID: "10", StoreID: "s2"}, queuerule.ScopeLayout, true},
+		{"other_layout", queuerule.Target{LayoutID: "20", StoreID: "s3"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := th.Match(tt.target)
+			if ok != tt.ok || rule.Scope != tt.scope {
+				t.Errorf("Match() = %+v, %v, want scope %q, %v", rule, ok, tt.scope, tt.ok)
+			}
+		})
+	}
+}
+
+func TestEvaluator_Observe(t *testing.T) {
+	ctx := context.Background()
+	events := eventstate.New(nil, mem.New())
+	ev := queuerule.NewEvaluator(events)
+	target := queuerule.Target{LayoutID: "10", StoreID: "s1"}
+	rule := queuerule.Rule{Scope: queuerule.ScopeStore, ScopeID: "s1", Threshold: 5, SequenceLength: 2}
+	observe := func(min int, value float64) {
+		t.Helper()
+		s := queuerule.Sample{Target: target, Time: t0.Add(time.Duration(min) * time.Minute), Value: value}
+		if err := ev.Observe(ctx, s, rule, true); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	observe(1, 6)
+	observe(2, 4)
+	observe(3, 6)
+	// repeated measurement isn't counted
+	observe(3, 6)
+	if _, ok := ev.Open(target); ok {
+		t.Fatal("event opened before sequence_length measurements in a row")
+	}
+	observe(4, 7)
+	id, ok := ev.Open(target)
+	if !ok {
+		t.Fatal("event not opened after sequence_length measurements in a row")
+	}
+	e, err := events.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if e.Key != queuerule.EventKey || e.StoreID != "s1" || !e.EventTime.Equal(t0.Add(4*time.Minute)) ||
+		e.Source.GetParamByName(queuerule.ParamValue) != "7" || e.Source.GetParamByName(queuerule.ParamScope) != queuerule.ScopeStore {
+		t.Errorf("created event %+v, source %+v", e, e.Source)
+	}
+	observe(5, 9)
+	observe(6, 5)
+	observe(7, 8)
+	observe(8, 2)
+	if _, ok := ev.Open(target); !ok {
+		t.Fatal("event resolved before sequence_length measurements in a row")
+	}
+	observe(9, 1)
+	if _, ok := ev.Open(target); ok {
+		t.Fatal("event not resolved after sequence_length measurements in a row")
+	}
+	if e, _ = events.Get(ctx, id); e.Status() != domain.EventStatusResolved {
+		t.Errorf("event status %s, want %s", e.Status(), domain.EventStatusResolved)
+	}
+	created, err := events.FindCreated(ctx, eventstate.Query{Key: queuerule.EventKey})
+	if err != nil || len(created) != 1 {
+		t.Errorf("FindCreated() = %d events, %v, want single event", len(created), err)
+	}
+}
+
+func TestEvaluator_Restore(t *testing.T) {
+	ctx := context.Background()
+	events := eventstate.New(nil, mem.New())
+	target := queuerule.Target{LayoutID: "10", StoreID: "s1"}
+	rule := queuerule.Rule{Scope: queuerule.ScopeStore, ScopeID: "s1", Threshold: 5}
+	before := queuerule.NewEvaluator(events)
+	if err := before.Observe(ctx, queuerule.Sample{Target: target, Time: t0, Value: 6}, rule, true); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	id, _ := before.Open(target)
+
+	after := queuerule.NewEvaluator(events)
+	if err := after.Restore(ctx); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if restored, ok := after.Open(target); !ok || restored != id {
+		t.Fatalf("Open() = %s, %v, want %s", restored, ok, id)
+	}
+	// threshold removed
+	if err := after.Observe(ctx, queuerule.Sample{Target: target, Time: t0.Add(time.Minute), Value: 6}, queuerule.Rule{}, false); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if e, _ := events.Get(ctx, id); e.Status() != domain.EventStatusResolved {
+		t.Errorf("event status %s, want %s", e.Status(), domain.EventStatusResolved)
+	}
+}
+
+func TestPoller_Poll(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeRepo{}
+	events := eventstate.New(nil, mem.New())
+	p := queuerule.NewPoller(fakeRepos{repo}, events)
+	for min, v := range []struct{ s1, s2, z1 int32 }{{6, 11, 4}, {6, 4, 1}, {6, 11, 1}} {
+		repo.stores = domain.StoresDataQueue{{StoreID: "s1", Points: point(min, v.s1)}, {StoreID: "s2", Points: point(min, v.s2)}}
+		repo.zones = domain.ZonesDataQueue{{ZoneID: "z1", Points: point(min, v.z1)}}
+		p.Poll(ctx)
+	}
+	open, err := events.FindCreated(ctx, eventstate.Query{Key: queuerule.EventKey, Statuses: []string{domain.EventStatusNew}})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	// s1 exceeds store threshold 3 times, s2 never exceeds layout threshold 2 times in a row
+	if len(open) != 1 || open[0].StoreID != "s1" || open[0].Source.GetParamByName(queuerule.ParamZoneID) != "" {
+		t.Errorf("open events %+v, want event of the store s1", open)
+	}
+	resolved, err := events.FindCreated(ctx, eventstate.Query{Key: queuerule.EventKey, Statuses: []string{domain.EventStatusResolved}})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	// z1 exceeds block threshold once with sequence_length 1, then resolved
+	if len(resolved) != 1 || resolved[0].Source.GetParamByName(queuerule.ParamZoneID) != "z1" {
+		t.Errorf("resolved events %+v, want event of the zone z1", resolved)
+	}
+}
@@ -0,0 +1,225 @@
+// Command gen generates decorator of the domain.LayoutRepo interface,
+// every method with context is called through Repo.invoke.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+)
+
+// passThrough methods are called directly.
+var passThrough = map[string]bool{
+	"GetSrvPortDB": true,
+	"Scope":        true,
+	"Dest":         true,
+	"Health":       true,
+	"GetRepos":     true,
+}
+
+type method struct {
+	name    string
+	params  []string
+	results []string
+}
+
+func main() {
+	src := flag.String("src", "../../domain/domain.go", "file with domain.LayoutRepo")
+	out := flag.String("out", "repo_gen.go", "output file")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, *src, nil, 0)
+	if err != nil {
+		log.Fatalf("parse %s failed, %s", *src, err)
+	}
+	ifaces := make(map[string]*ast.InterfaceType)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok {
+			if it, ok := ts.Type.(*ast.InterfaceType); ok {
+				ifaces[ts.Name.Name] = it
+			}
+		}
+		return true
+	})
+	methods := collect(ifaces, "LayoutRepo")
+	sort.Slice(methods, func(i, j int) bool { return methods[i].name < methods[j].name })
+	bts, err := format.Source(render(methods))
+	if err != nil {
+		log.Fatalf("format failed, %s", err)
+	}
+	if err := ioutil.WriteFile(*out, bts, 0644); err != nil {
+		log.Fatalf("write %s failed, %s", *out, err)
+	}
+}
+
+// collect returns methods of the interface with methods of the embedded interfaces.
+func collect(ifaces map[string]*ast.InterfaceType, name string) []method {
+	it, ok := ifaces[name]
+	if !ok {
+		log.Fatalf("interface %s not found", name)
+	}
+	res := make([]method, 0, len(it.Methods.List))
+	for _, field := range it.Methods.List {
+		ft, ok := field.Type.(*ast.FuncType)
+		if !ok {
+			res = append(res, collect(ifaces, field.Type.(*ast.Ident).Name)...)
+			continue
+		}
+		m := method{name: field.Names[0].Name}
+		for _, p := range ft.Params.List {
+			for i := 0; i < max(1, len(p.Names)); i++ {
+				m.params = append(m.params, typeString(p.Type))
+			}
+		}
+		if ft.Results != nil {
+			for _, r := range ft.Results.List {
+				for i := 0; i < max(1, len(r.Names)); i++ {
+					m.results = append(m.results, typeString(r.Type))
+				}
+			}
+		}
+		res = append(res, m)
+	}
+	return res
+}
+
+// typeString renders type, exported types of the domain package are qualified.
+func typeString(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.Ident:
+		if ast.IsExported(t.Name) {
+			return "domain." + t.Name
+		}
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + typeString(t.X)
+	case *ast.ArrayType:
+		return "[]" + typeString(t.Elt)
+	case *ast.MapType:
+		return "map[" + typeString(t.Key) + "]" + typeString(t.Value)
+	case *ast.SelectorExpr:
+		return t.X.(*ast.Ident).Name + "." + t.Sel.Name
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return "chan<- " + typeString(t.Value)
+		case ast.RECV:
+			return "<-chan " + typeString(t.Value)
+		}
+		return "chan " + typeString(t.Value)
+	}
+	log.Fatalf("unsupported type %T", e)
+	return ""
+}
+
+func render(methods []method) []byte {
+	var b bytes.Buffer
+	imports := map[string]bool{"context": true, "git.countmax.ru/countmax/layoutconfig.api/domain": true}
+	for _, m := range methods {
+		for _, t := range append(append([]string{}, m.params...), m.results...) {
+			if strings.Contains(t, "time.") {
+				imports["time"] = true
+			}
+			if strings.Contains(t, "reference.") {
+				imports["git.countmax.ru/countmax/layoutconfig.api/domain/reference"] = true
+			}
+		}
+	}
+	b.WriteString("// Code generated by repodecor/gen. DO NOT EDIT.\n\npackage repodecor\n\nimport (\n")
+	paths := make([]string, 0, len(imports))
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, std := range []bool{true, false} {
+		if !std {
+			b.WriteString("\n")
+		}
+		for _, p := range paths {
+			if !strings.Contains(p, ".") == std {
+				fmt.Fprintf(&b, "\t%q\n", p)
+			}
+		}
+	}
+	b.WriteString(")\n\nvar (\n")
+	for _, m := range methods {
+		if !passThrough[m.name] && isCtx(m) {
+			fmt.Fprintf(&b, "\tmethod%s = newMethod(%q)\n", m.name, m.name)
+		}
+	}
+	b.WriteString(")\n\n// Methods returns all decorated methods ordered by name.\nfunc Methods() []Method {\n\treturn []Method{\n")
+	for _, m := range methods {
+		if !passThrough[m.name] && isCtx(m) {
+			fmt.Fprintf(&b, "\t\tmethod%s,\n", m.name)
+		}
+	}
+	b.WriteString("\t}\n}\n")
+	for _, m := range methods {
+		renderMethod(&b, m)
+	}
+	return b.Bytes()
+}
+
+func isCtx(m method) bool {
+	return len(m.params) > 0 && m.params[0] == "context.Context"
+}
+
+func renderMethod(b *bytes.Buffer, m method) {
+	params := make([]string, len(m.params))
+	names := make([]string, len(m.params))
+	for i, t := range m.params {
+		names[i] = fmt.Sprintf("p%d", i)
+		if i == 0 && isCtx(m) {
+			names[i] = "ctx"
+		}
+		params[i] = names[i] + " " + t
+	}
+	results := strings.Join(m.results, ", ")
+	if len(m.results) > 1 {
+		results = "(" + results + ")"
+	}
+	fmt.Fprintf(b, "\n// %s decorates domain.LayoutRepo.%s\n", m.name, m.name)
+	fmt.Fprintf(b, "func (r *Repo) %s(%s) %s {\n", m.name, strings.Join(params, ", "), results)
+	if passThrough[m.name] || !isCtx(m) {
+		fmt.Fprintf(b, "\treturn r.next.%s(%s)\n}\n", m.name, strings.Join(names, ", "))
+		return
+	}
+	values := m.results[:len(m.results)-1]
+	vars := make([]string, len(values))
+	for i := range values {
+		vars[i] = fmt.Sprintf("r%d", i)
+	}
+	fmt.Fprintf(b, "\tres, err := r.invoke(ctx, method%s, []interface{}{%s}, func(ctx context.Context) ([]interface{}, error) {\n",
+		m.name, strings.Join(names[1:], ", "))
+	if len(values) == 0 {
+		fmt.Fprintf(b, "\t\treturn nil, r.next.%s(%s)\n\t})\n", m.name, strings.Join(names, ", "))
+		fmt.Fprintf(b, "\t_ = res\n\treturn err\n}\n")
+		return
+	}
+	fmt.Fprintf(b, "\t\t%s, err := r.next.%s(%s)\n", strings.Join(vars, ", "), m.name, strings.Join(names, ", "))
+	fmt.Fprintf(b, "\t\treturn []interface{}{%s}, err\n\t})\n", strings.Join(vars, ", "))
+	for i, t := range values {
+		fmt.Fprintf(b, "\tvar %s %s\n", vars[i], t)
+	}
+	fmt.Fprintf(b, "\tif len(res) == %d {\n", len(values))
+	for i, t := range values {
+		fmt.Fprintf(b, "\t\t%s, _ = res[%d].(%s)\n", vars[i], i, t)
+	}
+	fmt.Fprintf(b, "\t}\n\treturn %s, err\n}\n", strings.Join(vars, ", "))
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
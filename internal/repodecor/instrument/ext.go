@@ -0,0 +1,81 @@
+package instrument
+
+import (
+	"context"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+)
+
+// EventRepo instrumented domain.IEventRepo, methods haven't context,
+// so spans aren't linked to the request.
+type EventRepo struct {
+	next domain.IEventRepo
+	dest string
+}
+
+var _ domain.IEventRepo = (*EventRepo)(nil)
+
+// NewEventRepo builder for EventRepo, dest is name of the database for metrics.
+func NewEventRepo(next domain.IEventRepo, dest string) *EventRepo {
+	return &EventRepo{next: next, dest: dest}
+}
+
+// FindChainEvents instruments domain.IEventRepo.FindChainEvents.
+func (r *EventRepo) FindChainEvents(from, to time.Time, layoutID, storeID, key, kind, severity string,
+	limit, offset int64) (domain.Events, int64, error) {
+	_, done := observe(context.Background(), RepoEvent, "FindChainEvents", r.dest)
+	events, count, err := r.next.FindChainEvents(from, to, layoutID, storeID, key, kind, severity, limit, offset)
+	done(err)
+	return events, count, err
+}
+
+// FindConsumerChainEvents instruments domain.IEventRepo.FindConsumerChainEvents,
+// only start of the consumer is measured: duration of the method is the time to subscribe,
+// delivery of the events isn't measured.
+func (r *EventRepo) FindConsumerChainEvents(subscriberID, layoutID, storeID, key, kind, severity string,
+	from time.Time, cancel <-chan struct{}) chan domain.Event {
+	_, done := observe(context.Background(), RepoEvent, "FindConsumerChainEvents", r.dest)
+	ch := r.next.FindConsumerChainEvents(subscriberID, layoutID, storeID, key, kind, severity, from, cancel)
+	done(nil)
+	return ch
+}
+
+// ScreenRepo instrumented domain.IScreenRepo, methods haven't context,
+// so spans aren't linked to the request.
+type ScreenRepo struct {
+	next domain.IScreenRepo
+	dest string
+}
+
+var _ domain.IScreenRepo = (*ScreenRepo)(nil)
+
+// NewScreenRepo builder for ScreenRepo, dest is name of the database for metrics.
+func NewScreenRepo(next domain.IScreenRepo, dest string) *ScreenRepo {
+	return &ScreenRepo{next: next, dest: dest}
+}
+
+// FindScreens instruments domain.IScreenRepo.FindScreens.
+func (r *ScreenRepo) FindScreens(layoutID, storeID, status string, deviceID []string, from, to time.Time,
+	limit, offset int64) (domain.Screenshots, int64, error) {
+	_, done := observe(context.Background(), RepoScreen, "FindScreens", r.dest)
+	screens, count, err := r.next.FindScreens(layoutID, storeID, status, deviceID, from, to, limit, offset)
+	done(err)
+	return screens, count, err
+}
+
+// FindScreensAtTime instruments domain.IScreenRepo.FindScreensAtTime.
+func (r *ScreenRepo) FindScreensAtTime(layoutID, storeID string, deviceID []string, t time.Time) (domain.Screenshots, error) {
+	_, done := observe(context.Background(), RepoScreen, "FindScreensAtTime", r.dest)
+	screens, err := r.next.FindScreensAtTime(layoutID, storeID, deviceID, t)
+	done(err)
+	return screens, err
+}
+
+// UpdStatusManyScreens instruments domain.IScreenRepo.UpdStatusManyScreens.
+func (r *ScreenRepo) UpdStatusManyScreens(p domain.ParamsScreenUpd) (int64, error) {
+	_, done := observe(context.Background(), RepoScreen, "UpdStatusManyScreens", r.dest)
+	count, err := r.next.UpdStatusManyScreens(p)
+	done(err)
+	return count, err
+}
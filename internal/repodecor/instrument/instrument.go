@@ -0,0 +1,134 @@
+// Package instrument records latency and errors of the repos methods
+// by method and database to the prometheus and OpenTelemetry spans,
+// spans are linked to the request by its id.
+package instrument
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/repodecor"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/repodecor/resilience"
+)
+
+const tracerName = "git.countmax.ru/countmax/layoutconfig.api/internal/repodecor/instrument"
+
+// kinds of the repos.
+const (
+	RepoLayout = "layout"
+	RepoEvent  = "event"
+	RepoScreen = "screen"
+)
+
+// classes of the errors.
+const (
+	ErrClassTimeout  = "timeout"
+	ErrClassCanceled = "canceled"
+	ErrClassRejected = "rejected"
+	ErrClassNotFound = "not_found"
+	ErrClassOther    = "other"
+)
+
+var (
+	duration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "countmax_repo_duration_seconds",
+			Help:    "Histogram of the repo methods durations in seconds by method and database",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"repo", "method", "db"},
+	)
+
+	failures = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "countmax_repo_errors_total",
+			Help: "Count of the repo methods errors by method, database and class of the error",
+		},
+		[]string{"repo", "method", "db", "class"},
+	)
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns context with id of the http request, it's added to the spans.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns id of the http request from the context.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Tracer returns tracer of the package from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// ErrClass returns class of the error for metrics.
+func ErrClass(err error) string {
+	var rej *resilience.RejectedError
+	switch {
+	case errors.As(err, &rej):
+		return ErrClassRejected
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrClassCanceled
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrClassNotFound
+	}
+	return ErrClassOther
+}
+
+// observe starts span of the method, returned func records result of the method.
+func observe(ctx context.Context, repo, method, dest string) (context.Context, func(error)) {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.name", dest),
+		attribute.String("code.function", method),
+	}
+	if id := RequestID(ctx); id != "" {
+		attrs = append(attrs, attribute.String("http.request_id", id))
+	}
+	ctx, span := Tracer().Start(ctx, repo+"."+method,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	start := time.Now()
+	return ctx, func(err error) {
+		duration.WithLabelValues(repo, method, dest).Observe(time.Since(start).Seconds())
+		if err != nil {
+			class := ErrClass(err)
+			failures.WithLabelValues(repo, method, dest, class).Inc()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, class)
+		}
+		span.End()
+	}
+}
+
+// Middleware returns repodecor.Middleware instrumenting methods of the layout repo with dest.
+func Middleware(dest string) repodecor.Middleware {
+	return func(ctx context.Context, m repodecor.Method, args []interface{}, next repodecor.Invoker) ([]interface{}, error) {
+		ctx, done := observe(ctx, RepoLayout, m.Name, dest)
+		res, err := next(ctx)
+		done(err)
+		return res, err
+	}
+}
+
+// New returns repodecor.Decorator instrumenting layout repos.
+func New() repodecor.Decorator {
+	return func(repo domain.LayoutRepo) domain.LayoutRepo {
+		return repodecor.New(repo, Middleware(repo.Dest()))
+	}
+}
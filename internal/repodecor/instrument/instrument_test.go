@@ -0,0 +1,52 @@
+package instrument
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/repodecor"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/repodecor/resilience"
+)
+
+func TestErrClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("query failed, %w", context.DeadlineExceeded), ErrClassTimeout},
+		{context.Canceled, ErrClassCanceled},
+		{&resilience.RejectedError{Dest: "db"}, ErrClassRejected},
+		{sql.ErrNoRows, ErrClassNotFound},
+		{errors.New("connection refused"), ErrClassOther},
+	}
+	for _, tt := range tests {
+		if got := ErrClass(tt.err); got != tt.want {
+			t.Errorf("ErrClass(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	mw := Middleware("srv:1433/db")
+	m := repodecor.Method{Name: "FindChains"}
+	ctx := WithRequestID(context.Background(), "req-1")
+	for _, err := range []error{nil, context.DeadlineExceeded, context.DeadlineExceeded} {
+		_, _ = mw(ctx, m, nil, func(ctx context.Context) ([]interface{}, error) {
+			if id := RequestID(ctx); id != "req-1" {
+				t.Errorf("RequestID() = %s, want req-1", id)
+			}
+			return nil, err
+		})
+	}
+	if got := testutil.ToFloat64(failures.WithLabelValues(RepoLayout, "FindChains", "srv:1433/db", ErrClassTimeout)); got != 2 {
+		t.Errorf("errors counter = %v, want 2", got)
+	}
+	if got := testutil.CollectAndCount(duration); got != 1 {
+		t.Errorf("count of the duration series = %d, want 1", got)
+	}
+}
@@ -0,0 +1,71 @@
+package readcache
+
+import "reflect"
+
+// cloneResults makes deep copy of the results, handlers change returned entities
+// (SetZeroValue, IncludeStores), so cached entities must not be shared.
+func cloneResults(res []interface{}) []interface{} {
+	c := make([]interface{}, len(res))
+	for i, v := range res {
+		if v != nil {
+			c[i] = clone(reflect.ValueOf(v)).Interface()
+		}
+	}
+	return c
+}
+
+// clone copies value with pointers, slices and maps,
+// unexported fields of the structs are copied by value.
+func clone(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(clone(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(clone(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(clone(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(clone(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), clone(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := c.Field(i); f.CanSet() {
+				f.Set(clone(v.Field(i)))
+			}
+		}
+		return c
+	}
+	return v
+}
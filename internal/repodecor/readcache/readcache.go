@@ -0,0 +1,226 @@
+// Package readcache implements read-through cache of the rarely changed
+// metadata of the layout repo: references, layouts, behaviors, stores and zones.
+// Every method has own time to live and limit of the entries,
+// cached entries are dropped by the matching Add/Upd/Del/Bind methods.
+package readcache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/repodecor"
+)
+
+var (
+	requests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "countmax_repo_cache_requests_total",
+			Help: "Count of the requests to the repo cache by method and result: hit, miss or fresh (cache bypassed)",
+		},
+		[]string{"method", "result"},
+	)
+
+	evictions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "countmax_repo_cache_evictions_total",
+			Help: "Count of the repo cache entries evicted by size limit or dropped by changes",
+		},
+		[]string{"method", "reason"},
+	)
+)
+
+// MethodConfig params of the cache of the method.
+type MethodConfig struct {
+	TTL      time.Duration `mapstructure:"ttl"`  // time to live of the entry, 0 - method isn't cached
+	Size     int           `mapstructure:"size"` // max count of the entries of the method, 0 - unlimited
+	entities []string      // parts of the names of the write methods dropping the entries
+}
+
+// DefaultMethods cacheable methods with default params.
+var DefaultMethods = map[string]MethodConfig{
+	"GetReferences":          {TTL: time.Hour, Size: 16},
+	"GetRefCategories":       {TTL: time.Hour, Size: 16},
+	"GetRefPriceSegments":    {TTL: time.Hour, Size: 16},
+	"GetRefKindZones":        {TTL: time.Hour, Size: 16},
+	"GetRefKindEnters":       {TTL: time.Hour, Size: 16},
+	"FindLayouts":            {TTL: time.Minute, Size: 64, entities: []string{"Chain", "Mall"}},
+	"FindChains":             {TTL: 5 * time.Minute, Size: 256, entities: []string{"Chain"}},
+	"FindChainByID":          {TTL: 5 * time.Minute, Size: 1024, entities: []string{"Chain"}},
+	"FindMalls":              {TTL: 5 * time.Minute, Size: 256, entities: []string{"Mall"}},
+	"FindMallByID":           {TTL: 5 * time.Minute, Size: 1024, entities: []string{"Mall"}},
+	"FindBehaviors":          {TTL: 5 * time.Minute, Size: 256, entities: []string{"Behavior"}},
+	"FindBehaviorByLayoutID": {TTL: 5 * time.Minute, Size: 1024, entities: []string{"Behavior"}},
+	"FindChainStores":        {TTL: 5 * time.Minute, Size: 1024, entities: []string{"ChainStore", "EntranceStore"}},
+	"FindChainStoreByID":     {TTL: 5 * time.Minute, Size: 4096, entities: []string{"ChainStore", "EntranceStore"}},
+	"FindChainZones":         {TTL: 5 * time.Minute, Size: 1024, entities: []string{"ChainZone", "SensorZone", "EntranceZone"}},
+	"FindChainZoneByID":      {TTL: 5 * time.Minute, Size: 4096, entities: []string{"ChainZone", "SensorZone", "EntranceZone"}},
+	"FindMallZones":          {TTL: 5 * time.Minute, Size: 1024, entities: []string{"Mall"}},
+	"FindMallZoneByID":       {TTL: 5 * time.Minute, Size: 4096, entities: []string{"Mall"}},
+}
+
+// Methods returns default params of the methods overridden by cfg,
+// names of the methods in cfg are case insensitive (viper lowercases keys).
+func Methods(cfg map[string]MethodConfig) map[string]MethodConfig {
+	res := make(map[string]MethodConfig, len(DefaultMethods))
+	for name, mc := range DefaultMethods {
+		if c, ok := cfg[strings.ToLower(name)]; ok {
+			mc.TTL, mc.Size = c.TTL, c.Size
+		} else if c, ok := cfg[name]; ok {
+			mc.TTL, mc.Size = c.TTL, c.Size
+		}
+		if mc.TTL > 0 {
+			res[name] = mc
+		}
+	}
+	return res
+}
+
+// entry cached results of the method.
+type entry struct {
+	key      string
+	expireAt time.Time
+	res      []interface{}
+}
+
+// methodCache LRU entries of the one method.
+type methodCache struct {
+	name  string
+	cfg   MethodConfig
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+// Cache of the one repo.
+type Cache struct {
+	mu      sync.Mutex
+	methods map[string]*methodCache
+	now     func() time.Time
+}
+
+// NewCache builder for Cache with params of the cached methods.
+func NewCache(methods map[string]MethodConfig) *Cache {
+	c := &Cache{methods: make(map[string]*methodCache, len(methods)), now: time.Now}
+	for name, cfg := range methods {
+		c.methods[name] = &methodCache{name: name, cfg: cfg, ll: list.New(), items: make(map[string]*list.Element)}
+	}
+	return c
+}
+
+// Middleware returns repodecor.Middleware with the cache.
+func (c *Cache) Middleware() repodecor.Middleware {
+	return func(ctx context.Context, m repodecor.Method, args []interface{}, next repodecor.Invoker) ([]interface{}, error) {
+		if m.Write {
+			res, err := next(ctx)
+			if err == nil {
+				c.Invalidate(m.Name)
+			}
+			return res, err
+		}
+		mc, ok := c.methods[m.Name]
+		if !ok {
+			return next(ctx)
+		}
+		key, err := json.Marshal(args)
+		if err != nil {
+			return next(ctx)
+		}
+		result := "fresh"
+		if !repodecor.IsFresh(ctx) {
+			if res, ok := c.get(mc, string(key)); ok {
+				requests.WithLabelValues(m.Name, "hit").Inc()
+				return res, nil
+			}
+			result = "miss"
+		}
+		requests.WithLabelValues(m.Name, result).Inc()
+		res, err := next(ctx)
+		if err == nil {
+			c.put(mc, string(key), res)
+		}
+		return res, err
+	}
+}
+
+// Invalidate drops entries of the cached methods matching to the write method.
+func (c *Cache) Invalidate(writeMethod string) {
+	entity := writeMethod
+	for _, prefix := range []string{"Add", "Upd", "Del", "Bind"} {
+		entity = strings.TrimPrefix(entity, prefix)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, mc := range c.methods {
+		for _, e := range mc.cfg.entities {
+			if strings.Contains(entity, e) {
+				if n := mc.ll.Len(); n > 0 {
+					evictions.WithLabelValues(mc.name, "changed").Add(float64(n))
+				}
+				mc.ll.Init()
+				mc.items = make(map[string]*list.Element)
+				break
+			}
+		}
+	}
+}
+
+// Len returns count of the cached entries of the method.
+func (c *Cache) Len(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if mc, ok := c.methods[method]; ok {
+		return mc.ll.Len()
+	}
+	return 0
+}
+
+func (c *Cache) get(mc *methodCache, key string) ([]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := mc.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*entry)
+	if c.now().After(e.expireAt) {
+		mc.ll.Remove(el)
+		delete(mc.items, key)
+		return nil, false
+	}
+	mc.ll.MoveToFront(el)
+	return cloneResults(e.res), true
+}
+
+func (c *Cache) put(mc *methodCache, key string, res []interface{}) {
+	res = cloneResults(res)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expireAt := c.now().Add(mc.cfg.TTL)
+	if el, ok := mc.items[key]; ok {
+		e := el.Value.(*entry)
+		e.res, e.expireAt = res, expireAt
+		mc.ll.MoveToFront(el)
+		return
+	}
+	mc.items[key] = mc.ll.PushFront(&entry{key: key, expireAt: expireAt, res: res})
+	for mc.cfg.Size > 0 && mc.ll.Len() > mc.cfg.Size {
+		el := mc.ll.Back()
+		mc.ll.Remove(el)
+		delete(mc.items, el.Value.(*entry).key)
+		evictions.WithLabelValues(mc.name, "size").Inc()
+	}
+}
+
+// New returns repodecor.Decorator making Cache for every repo.
+func New(methods map[string]MethodConfig) repodecor.Decorator {
+	return func(repo domain.LayoutRepo) domain.LayoutRepo {
+		return repodecor.New(repo, NewCache(methods).Middleware())
+	}
+}
@@ -0,0 +1,120 @@
+package readcache
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/repodecor"
+)
+
+type item struct {
+	ID   string
+	Tags []string
+}
+
+var (
+	methodStores = repodecor.Method{Name: "FindChainStores"}
+	methodZones  = repodecor.Method{Name: "FindChainZones"}
+	methodAdd    = repodecor.Method{Name: "AddChainZone", Write: true}
+)
+
+// counter returns invoker counting calls of the repo.
+func counter(calls *int) repodecor.Invoker {
+	return func(ctx context.Context) ([]interface{}, error) {
+		*calls++
+		return []interface{}{[]*item{{ID: "1", Tags: []string{"a"}}}, int64(1)}, nil
+	}
+}
+
+func TestCache_ReadThrough(t *testing.T) {
+	c := NewCache(Methods(nil))
+	mw := c.Middleware()
+	ctx := context.Background()
+	var calls int
+	first, _ := mw(ctx, methodStores, []interface{}{"ru", "1"}, counter(&calls))
+	// handlers change returned entities
+	first[0].([]*item)[0].Tags[0] = "changed"
+	second, _ := mw(ctx, methodStores, []interface{}{"ru", "1"}, counter(&calls))
+	if calls != 1 {
+		t.Errorf("repo calls = %d, want 1", calls)
+	}
+	want := []interface{}{[]*item{{ID: "1", Tags: []string{"a"}}}, int64(1)}
+	if !reflect.DeepEqual(second, want) {
+		t.Errorf("cached results = %v, want %v", second, want)
+	}
+	_, _ = mw(ctx, methodStores, []interface{}{"ru", "2"}, counter(&calls))
+	if calls != 2 {
+		t.Errorf("repo calls with other args = %d, want 2", calls)
+	}
+	// not cached method
+	_, _ = mw(ctx, repodecor.Method{Name: "FindChainZonesData"}, nil, counter(&calls))
+	_, _ = mw(ctx, repodecor.Method{Name: "FindChainZonesData"}, nil, counter(&calls))
+	if calls != 4 {
+		t.Errorf("repo calls of not cached method = %d, want 4", calls)
+	}
+}
+
+func TestCache_Fresh(t *testing.T) {
+	c := NewCache(Methods(nil))
+	mw := c.Middleware()
+	var calls int
+	_, _ = mw(context.Background(), methodStores, []interface{}{"ru", "1"}, counter(&calls))
+	_, _ = mw(repodecor.WithFresh(context.Background()), methodStores, []interface{}{"ru", "1"}, counter(&calls))
+	if calls != 2 {
+		t.Errorf("repo calls with fresh context = %d, want 2", calls)
+	}
+	_, _ = mw(context.Background(), methodStores, []interface{}{"ru", "1"}, counter(&calls))
+	if calls != 2 {
+		t.Errorf("repo calls after fresh call = %d, want cached 2", calls)
+	}
+}
+
+func TestCache_ExpireSize(t *testing.T) {
+	c := NewCache(Methods(map[string]MethodConfig{"findchainstores": {TTL: time.Minute, Size: 2}}))
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	mw := c.Middleware()
+	var calls int
+	for _, id := range []string{"1", "2", "3"} {
+		_, _ = mw(context.Background(), methodStores, []interface{}{id}, counter(&calls))
+	}
+	if n := c.Len(methodStores.Name); n != 2 {
+		t.Errorf("Cache.Len() = %d, want 2", n)
+	}
+	now = now.Add(2 * time.Minute)
+	_, _ = mw(context.Background(), methodStores, []interface{}{"3"}, counter(&calls))
+	if calls != 4 {
+		t.Errorf("repo calls after expire = %d, want 4", calls)
+	}
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	c := NewCache(Methods(nil))
+	mw := c.Middleware()
+	ctx := context.Background()
+	var calls int
+	for _, m := range []repodecor.Method{methodStores, methodZones} {
+		_, _ = mw(ctx, m, nil, counter(&calls))
+	}
+	_, _ = mw(ctx, methodAdd, nil, func(ctx context.Context) ([]interface{}, error) {
+		return []interface{}{int64(1)}, nil
+	})
+	if n := c.Len(methodZones.Name); n != 0 {
+		t.Errorf("Cache.Len(%s) after %s = %d, want 0", methodZones.Name, methodAdd.Name, n)
+	}
+	if n := c.Len(methodStores.Name); n != 1 {
+		t.Errorf("Cache.Len(%s) after %s = %d, want 1", methodStores.Name, methodAdd.Name, n)
+	}
+}
+
+func TestMethods_Disable(t *testing.T) {
+	methods := Methods(map[string]MethodConfig{"getreferences": {TTL: 0}})
+	if _, ok := methods["GetReferences"]; ok {
+		t.Error("Methods() contains GetReferences with zero ttl")
+	}
+	if _, ok := methods["GetRefCategories"]; !ok {
+		t.Error("Methods() doesn't contain default GetRefCategories")
+	}
+}
@@ -0,0 +1,110 @@
+// Package repodecor contains decorator of the domain.LayoutRepo,
+// every method with context is called through the chain of middlewares,
+// so resilience, instrumentation and caching are implemented once for all methods.
+package repodecor
+
+//go:generate go run ./gen -src ../../domain/domain.go -out repo_gen.go
+
+import (
+	"context"
+	"strings"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+)
+
+// MethodClass class of the repo method by weight of the query.
+type MethodClass string
+
+const (
+	// ClassMetadata queries of the layout structure and references.
+	ClassMetadata MethodClass = "metadata"
+	// ClassData heavy queries of the counting data.
+	ClassData MethodClass = "data"
+)
+
+// Method describes method of the repo.
+type Method struct {
+	Name  string
+	Class MethodClass
+	Write bool
+}
+
+// Invoker calls method of the decorated repo, returns results without error.
+type Invoker func(ctx context.Context) ([]interface{}, error)
+
+// Middleware wraps call of the repo method, args are parameters of the method without context.
+type Middleware func(ctx context.Context, m Method, args []interface{}, next Invoker) ([]interface{}, error)
+
+type freshKey struct{}
+
+// WithFresh returns context of the calls which must not be served by the cached results,
+// results of such calls are cached again.
+func WithFresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshKey{}, true)
+}
+
+// IsFresh reports whether calls with ctx must not be served by the cached results.
+func IsFresh(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshKey{}).(bool)
+	return fresh
+}
+
+// Decorator makes decorated repo, used by connmanager for every new repo.
+type Decorator func(domain.LayoutRepo) domain.LayoutRepo
+
+var _ domain.LayoutRepo = (*Repo)(nil)
+
+// Repo decorated domain.LayoutRepo.
+type Repo struct {
+	next domain.LayoutRepo
+	mw   Middleware
+}
+
+// New decorates repo by middlewares, first middleware is outermost.
+func New(next domain.LayoutRepo, mws ...Middleware) *Repo {
+	return &Repo{next: next, mw: Chain(mws...)}
+}
+
+// Chain joins middlewares into one, first middleware is outermost.
+func Chain(mws ...Middleware) Middleware {
+	return func(ctx context.Context, m Method, args []interface{}, next Invoker) ([]interface{}, error) {
+		call := next
+		for i := len(mws) - 1; i >= 0; i-- {
+			mw, inner := mws[i], call
+			call = func(ctx context.Context) ([]interface{}, error) {
+				return mw(ctx, m, args, inner)
+			}
+		}
+		return call(ctx)
+	}
+}
+
+// Unwrap returns decorated repo.
+func (r *Repo) Unwrap() domain.LayoutRepo {
+	return r.next
+}
+
+func (r *Repo) invoke(ctx context.Context, m Method, args []interface{}, next Invoker) ([]interface{}, error) {
+	return r.mw(ctx, m, args, next)
+}
+
+// dataMarkers parts of the names of the heavy methods.
+var dataMarkers = []string{"Data", "Tracks", "States", "StateAtTime", "PredictionQueue", "Delays"}
+
+// newMethod classifies method by name.
+func newMethod(name string) Method {
+	m := Method{Name: name, Class: ClassMetadata}
+	for _, marker := range dataMarkers {
+		if strings.Contains(name, marker) {
+			m.Class = ClassData
+			break
+		}
+	}
+	for _, prefix := range []string{"Add", "Del", "Upd", "Bind"} {
+		if strings.HasPrefix(name, prefix) {
+			m.Write = true
+			break
+		}
+	}
+	return m
+}
@@ -0,0 +1,46 @@
+package repodecor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestNewMethod(t *testing.T) {
+	tests := []struct {
+		name string
+		want Method
+	}{
+		{"FindChains", Method{Name: "FindChains", Class: ClassMetadata}},
+		{"FindChainZonesData", Method{Name: "FindChainZonesData", Class: ClassData}},
+		{"FindZoneStateAtTime", Method{Name: "FindZoneStateAtTime", Class: ClassData}},
+		{"AddChainZone", Method{Name: "AddChainZone", Class: ClassMetadata, Write: true}},
+		{"BindChainSensorZone", Method{Name: "BindChainSensorZone", Class: ClassMetadata, Write: true}},
+	}
+	for _, tt := range tests {
+		if got := newMethod(tt.name); got != tt.want {
+			t.Errorf("newMethod(%s) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(ctx context.Context, m Method, args []interface{}, next Invoker) ([]interface{}, error) {
+			order = append(order, name)
+			return next(ctx)
+		}
+	}
+	res, err := Chain(mw("outer"), mw("inner"))(context.Background(), Method{}, nil,
+		func(ctx context.Context) ([]interface{}, error) {
+			order = append(order, "repo")
+			return []interface{}{1}, nil
+		})
+	if err != nil || !reflect.DeepEqual(res, []interface{}{1}) {
+		t.Fatalf("Chain() = %v, %v", res, err)
+	}
+	if want := []string{"outer", "inner", "repo"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("Chain() order = %v, want %v", order, want)
+	}
+}
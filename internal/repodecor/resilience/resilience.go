@@ -0,0 +1,252 @@
+// Package resilience implements repodecor.Middleware with circuit breaker,
+// limit of the concurrent queries and timeouts by class of the method,
+// so one slow database can't exhaust goroutines of the all requests.
+package resilience
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/repodecor"
+)
+
+// states of the breaker.
+const (
+	StateClosed   string = "closed"
+	StateOpen     string = "open"
+	StateHalfOpen string = "half-open"
+)
+
+var (
+	breakerState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "countmax_breaker_state",
+			Help: "State of the circuit breaker of the database, 0 - closed, 1 - open, 2 - half-open",
+		},
+		[]string{"db"},
+	)
+
+	rejections = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "countmax_breaker_rejections_total",
+			Help: "Count of the queries rejected without call of the database",
+		},
+		[]string{"db", "reason"},
+	)
+
+	stateValues = map[string]float64{StateClosed: 0, StateOpen: 1, StateHalfOpen: 2}
+)
+
+// Config params of the resilience.
+type Config struct {
+	MaxConcurrent   int           // max count of the concurrent queries to the database, 0 - unlimited
+	QueueWait       time.Duration // max wait of the free slot when limit exceeded
+	MetadataTimeout time.Duration // timeout of the metadata queries, 0 - without timeout
+	DataTimeout     time.Duration // timeout of the heavy data queries, 0 - without timeout
+	Failures        int           // count of the consecutive failures opening the breaker, 0 - breaker disabled
+	OpenTimeout     time.Duration // time while breaker is open before probe query
+}
+
+// RejectedError returned when query rejected without call of the database.
+type RejectedError struct {
+	Dest       string
+	Reason     string
+	RetryAfter time.Duration
+}
+
+// Error implements error interface.
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("database %s unavailable, %s, retry after %s", e.Dest, e.Reason, e.RetryAfter.Round(time.Second))
+}
+
+// reasons of the rejection.
+const (
+	reasonOpen = "circuit breaker is open"
+	reasonBusy = "too many concurrent queries"
+)
+
+// Breaker circuit breaker with limit of the concurrent queries for one database.
+type Breaker struct {
+	mu       sync.Mutex
+	dest     string
+	cfg      Config
+	sem      chan struct{}
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+	now      func() time.Time
+}
+
+// NewBreaker builder for Breaker of the database dest.
+func NewBreaker(dest string, cfg Config) *Breaker {
+	b := &Breaker{dest: dest, cfg: cfg, state: StateClosed, now: time.Now}
+	if cfg.MaxConcurrent > 0 {
+		b.sem = make(chan struct{}, cfg.MaxConcurrent)
+	}
+	breakerState.WithLabelValues(dest).Set(stateValues[StateClosed])
+	return b
+}
+
+// State returns current state of the breaker.
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Middleware returns repodecor.Middleware with the breaker.
+func (b *Breaker) Middleware() repodecor.Middleware {
+	return func(ctx context.Context, m repodecor.Method, args []interface{}, next repodecor.Invoker) ([]interface{}, error) {
+		if err := b.allow(); err != nil {
+			return b.reject(ctx, err)
+		}
+		if err := b.acquire(ctx); err != nil {
+			b.cancelProbe()
+			return b.reject(ctx, err)
+		}
+		defer b.release()
+		if timeout := b.timeout(m.Class); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		res, err := next(ctx)
+		b.done(err)
+		return res, err
+	}
+}
+
+// timeout returns timeout of the method class.
+func (b *Breaker) timeout(class repodecor.MethodClass) time.Duration {
+	if class == repodecor.ClassData {
+		return b.cfg.DataTimeout
+	}
+	return b.cfg.MetadataTimeout
+}
+
+// allow checks state of the breaker, after OpenTimeout lets one probe query.
+func (b *Breaker) allow() error {
+	if b.cfg.Failures <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case StateOpen:
+		wait := b.openedAt.Add(b.cfg.OpenTimeout).Sub(b.now())
+		if wait > 0 {
+			return &RejectedError{Dest: b.dest, Reason: reasonOpen, RetryAfter: wait}
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+	case StateHalfOpen:
+		if b.probing {
+			return &RejectedError{Dest: b.dest, Reason: reasonOpen, RetryAfter: b.cfg.OpenTimeout}
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// acquire takes slot of the concurrent queries, waits QueueWait at most.
+func (b *Breaker) acquire(ctx context.Context) error {
+	if b.sem == nil {
+		return nil
+	}
+	select {
+	case b.sem <- struct{}{}:
+		return nil
+	default:
+	}
+	timer := time.NewTimer(b.cfg.QueueWait)
+	defer timer.Stop()
+	select {
+	case b.sem <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return &RejectedError{Dest: b.dest, Reason: reasonBusy, RetryAfter: time.Second}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Breaker) release() {
+	if b.sem != nil {
+		<-b.sem
+	}
+}
+
+// cancelProbe lets next probe query if probe wasn't called.
+func (b *Breaker) cancelProbe() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// done counts result of the query.
+func (b *Breaker) done(err error) {
+	if b.cfg.Failures <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if !isFailure(err) {
+		b.failures = 0
+		b.setState(StateClosed)
+		return
+	}
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.cfg.Failures {
+		b.openedAt = b.now()
+		b.setState(StateOpen)
+	}
+}
+
+// setState must be called under lock.
+func (b *Breaker) setState(state string) {
+	if b.state == state {
+		return
+	}
+	b.state = state
+	breakerState.WithLabelValues(b.dest).Set(stateValues[state])
+}
+
+func (b *Breaker) reject(ctx context.Context, err error) ([]interface{}, error) {
+	var rej *RejectedError
+	if errors.As(err, &rej) {
+		rejections.WithLabelValues(b.dest, rej.Reason).Inc()
+		if t := trackerFrom(ctx); t != nil {
+			t.set(rej)
+		}
+	}
+	return nil, err
+}
+
+// isFailure reports whether error means unavailable database,
+// canceled by client and not found results are not failures.
+func isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, sql.ErrNoRows) {
+		return false
+	}
+	return true
+}
+
+// New returns repodecor.Decorator making Breaker for every repo by its Dest().
+func New(cfg Config) repodecor.Decorator {
+	return func(repo domain.LayoutRepo) domain.LayoutRepo {
+		return repodecor.New(repo, NewBreaker(repo.Dest(), cfg).Middleware())
+	}
+}
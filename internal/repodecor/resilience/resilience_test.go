@@ -0,0 +1,109 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/repodecor"
+)
+
+var (
+	errDB      = errors.New("connection refused")
+	methodMeta = repodecor.Method{Name: "FindChains", Class: repodecor.ClassMetadata}
+	methodData = repodecor.Method{Name: "FindChainZonesData", Class: repodecor.ClassData}
+)
+
+func call(mw repodecor.Middleware, ctx context.Context, m repodecor.Method, err error) error {
+	_, e := mw(ctx, m, nil, func(ctx context.Context) ([]interface{}, error) {
+		return nil, err
+	})
+	return e
+}
+
+func TestBreaker_OpenHalfOpen(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker("db1", Config{Failures: 2, OpenTimeout: time.Minute})
+	b.now = func() time.Time { return now }
+	mw := b.Middleware()
+	ctx, tracker := WithTracker(context.Background())
+	for i := 0; i < 2; i++ {
+		if err := call(mw, ctx, methodMeta, errDB); !errors.Is(err, errDB) {
+			t.Fatalf("call %d error = %v, want %v", i, err, errDB)
+		}
+	}
+	if b.State() != StateOpen {
+		t.Fatalf("Breaker.State() = %s, want %s", b.State(), StateOpen)
+	}
+	now = now.Add(10 * time.Second)
+	err := call(mw, ctx, methodMeta, nil)
+	var rej *RejectedError
+	if !errors.As(err, &rej) || rej.RetryAfter != 50*time.Second {
+		t.Fatalf("call of the open breaker error = %v, want rejection with retry after 50s", err)
+	}
+	if tracker.Rejected() != rej {
+		t.Errorf("Tracker.Rejected() = %v, want %v", tracker.Rejected(), rej)
+	}
+	// probe failed, breaker is open again
+	now = now.Add(time.Minute)
+	if err := call(mw, ctx, methodMeta, errDB); !errors.Is(err, errDB) {
+		t.Fatalf("probe error = %v, want %v", err, errDB)
+	}
+	if b.State() != StateOpen {
+		t.Fatalf("Breaker.State() after failed probe = %s, want %s", b.State(), StateOpen)
+	}
+	// probe succeeded, breaker is closed
+	now = now.Add(time.Minute)
+	if err := call(mw, ctx, methodMeta, nil); err != nil {
+		t.Fatalf("probe unexpected error, %s", err)
+	}
+	if b.State() != StateClosed {
+		t.Errorf("Breaker.State() after probe = %s, want %s", b.State(), StateClosed)
+	}
+}
+
+func TestBreaker_NotFailures(t *testing.T) {
+	b := NewBreaker("db2", Config{Failures: 1, OpenTimeout: time.Minute})
+	mw := b.Middleware()
+	for _, err := range []error{nil, context.Canceled} {
+		_ = call(mw, context.Background(), methodMeta, err)
+	}
+	if b.State() != StateClosed {
+		t.Errorf("Breaker.State() = %s, want %s", b.State(), StateClosed)
+	}
+}
+
+func TestBreaker_Bulkhead(t *testing.T) {
+	b := NewBreaker("db3", Config{MaxConcurrent: 1, QueueWait: 10 * time.Millisecond})
+	mw := b.Middleware()
+	started, finish := make(chan struct{}), make(chan struct{})
+	go func() {
+		_, _ = mw(context.Background(), methodData, nil, func(ctx context.Context) ([]interface{}, error) {
+			close(started)
+			<-finish
+			return nil, nil
+		})
+	}()
+	<-started
+	err := call(mw, context.Background(), methodMeta, nil)
+	close(finish)
+	var rej *RejectedError
+	if !errors.As(err, &rej) || rej.Reason != reasonBusy {
+		t.Errorf("call over limit error = %v, want rejection %s", err, reasonBusy)
+	}
+}
+
+func TestBreaker_Timeouts(t *testing.T) {
+	b := NewBreaker("db4", Config{MetadataTimeout: time.Second, DataTimeout: time.Hour})
+	mw := b.Middleware()
+	for m, want := range map[repodecor.Method]time.Duration{methodMeta: time.Second, methodData: time.Hour} {
+		_, _ = mw(context.Background(), m, nil, func(ctx context.Context) ([]interface{}, error) {
+			deadline, ok := ctx.Deadline()
+			if !ok || time.Until(deadline) > want || time.Until(deadline) < want-time.Minute/2 {
+				t.Errorf("%s deadline = %v, want about %s", m.Name, deadline, want)
+			}
+			return nil, nil
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package resilience
+
+import (
+	"context"
+	"sync"
+)
+
+type trackerKey struct{}
+
+// Tracker keeps rejection of the queries made during the request,
+// used for response 503 instead of the error of the handler.
+type Tracker struct {
+	mu  sync.Mutex
+	rej *RejectedError
+}
+
+// WithTracker returns context with the new Tracker.
+func WithTracker(ctx context.Context) (context.Context, *Tracker) {
+	t := &Tracker{}
+	return context.WithValue(ctx, trackerKey{}, t), t
+}
+
+// Rejected returns last rejection of the query or nil.
+func (t *Tracker) Rejected() *RejectedError {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rej
+}
+
+func (t *Tracker) set(rej *RejectedError) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rej = rej
+}
+
+func trackerFrom(ctx context.Context) *Tracker {
+	t, _ := ctx.Value(trackerKey{}).(*Tracker)
+	return t
+}
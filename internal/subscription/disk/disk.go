@@ -0,0 +1,121 @@
+// Package disk implements subscription.RepoInterface in the embedded on-disk database.
+package disk
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/boltdb"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/subscription"
+)
+
+var bucketSubs = []byte("subscriptions")
+
+// Repo on-disk storage of the subscriptions.
+type Repo struct {
+	db *bolt.DB
+}
+
+// New builder for Repo, opens or creates database file by path.
+func New(path string) (*Repo, error) {
+	db, err := boltdb.Open(path, bucketSubs)
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{db: db}, nil
+}
+
+// Get returns subscription by id, if not exists returns subscription.ErrNotFound.
+func (r *Repo) Get(ctx context.Context, id string) (*subscription.Subscription, error) {
+	var sub *subscription.Subscription
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		sub, err = get(tx.Bucket(bucketSubs), id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Put puts or replaces subscription.
+func (r *Repo) Put(ctx context.Context, sub *subscription.Subscription) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(bucketSubs), sub)
+	})
+}
+
+// Update changes subscription by fn in the single transaction,
+// if not exists returns subscription.ErrNotFound.
+func (r *Repo) Update(ctx context.Context, id string, fn func(sub *subscription.Subscription) error) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketSubs)
+		sub, err := get(b, id)
+		if err != nil {
+			return err
+		}
+		if err := fn(sub); err != nil {
+			return err
+		}
+		return put(b, sub)
+	})
+}
+
+// Del removes subscription, if not exists returns subscription.ErrNotFound.
+func (r *Repo) Del(ctx context.Context, id string) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketSubs)
+		if b.Get([]byte(id)) == nil {
+			return subscription.ErrNotFound
+		}
+		return b.Delete([]byte(id))
+	})
+}
+
+// List returns all subscriptions ordered by id.
+func (r *Repo) List(ctx context.Context) ([]*subscription.Subscription, error) {
+	res := make([]*subscription.Subscription, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketSubs).ForEach(func(_, v []byte) error {
+			sub := &subscription.Subscription{}
+			if err := json.Unmarshal(v, sub); err != nil {
+				return errors.Wrap(err, "unmarshal subscription failed")
+			}
+			res = append(res, sub)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Close closes database file.
+func (r *Repo) Close() error {
+	return r.db.Close()
+}
+
+func get(b *bolt.Bucket, id string) (*subscription.Subscription, error) {
+	v := b.Get([]byte(id))
+	if v == nil {
+		return nil, subscription.ErrNotFound
+	}
+	sub := &subscription.Subscription{}
+	if err := json.Unmarshal(v, sub); err != nil {
+		return nil, errors.Wrap(err, "unmarshal subscription failed")
+	}
+	return sub, nil
+}
+
+func put(b *bolt.Bucket, sub *subscription.Subscription) error {
+	bts, err := json.Marshal(sub)
+	if err != nil {
+		return errors.Wrap(err, "marshal subscription failed")
+	}
+	return b.Put([]byte(sub.ID), bts)
+}
@@ -0,0 +1,63 @@
+package disk_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/subscription"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/subscription/disk"
+)
+
+func TestRepo_AckReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "subscriptions")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir error, %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "subscriptions.db")
+	ctx := context.Background()
+	r, err := disk.New(path)
+	if err != nil {
+		t.Fatalf("disk.New error, %s", err)
+	}
+	from := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, id := range []string{"notifier", "dashboard"} {
+		if _, err := subscription.Open(ctx, r, id, "user-1", subscription.Filter{Key: "queue.threshold.exceeded"}, from); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	acked := subscription.Cursor{Time: from.Add(time.Minute), ID: "42"}
+	if err := subscription.Ack(ctx, r, "notifier", acked); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if err := r.Del(ctx, "dashboard"); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if err := r.Del(ctx, "dashboard"); !errors.Is(err, subscription.ErrNotFound) {
+		t.Errorf("Repo.Del() twice error = %v, want %v", err, subscription.ErrNotFound)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	reopened, err := disk.New(path)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	defer reopened.Close()
+	subs, err := reopened.List(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if len(subs) != 1 || subs[0].ID != "notifier" || subs[0].Resume() != acked {
+		t.Fatalf("Repo.List() = %+v, want notifier acked at %s", subs, acked)
+	}
+	err = reopened.Update(ctx, "unknown", func(sub *subscription.Subscription) error { return nil })
+	if !errors.Is(err, subscription.ErrNotFound) {
+		t.Errorf("Repo.Update() of unknown error = %v, want %v", err, subscription.ErrNotFound)
+	}
+}
@@ -0,0 +1,80 @@
+// Package mem implements subscription.RepoInterface in the process memory,
+// subscriptions are lost on restart of the service.
+package mem
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/subscription"
+)
+
+// Repo memory storage of the subscriptions.
+type Repo struct {
+	mu   sync.RWMutex
+	subs map[string]subscription.Subscription
+}
+
+// New builder for Repo.
+func New() *Repo {
+	return &Repo{subs: make(map[string]subscription.Subscription)}
+}
+
+// Get returns subscription by id, if not exists returns subscription.ErrNotFound.
+func (r *Repo) Get(ctx context.Context, id string) (*subscription.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sub, ok := r.subs[id]
+	if !ok {
+		return nil, subscription.ErrNotFound
+	}
+	return &sub, nil
+}
+
+// Put puts or replaces subscription.
+func (r *Repo) Put(ctx context.Context, sub *subscription.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs[sub.ID] = *sub
+	return nil
+}
+
+// Update changes subscription by fn, if not exists returns subscription.ErrNotFound.
+func (r *Repo) Update(ctx context.Context, id string, fn func(sub *subscription.Subscription) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sub, ok := r.subs[id]
+	if !ok {
+		return subscription.ErrNotFound
+	}
+	if err := fn(&sub); err != nil {
+		return err
+	}
+	r.subs[id] = sub
+	return nil
+}
+
+// Del removes subscription, if not exists returns subscription.ErrNotFound.
+func (r *Repo) Del(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.subs[id]; !ok {
+		return subscription.ErrNotFound
+	}
+	delete(r.subs, id)
+	return nil
+}
+
+// List returns all subscriptions ordered by id.
+func (r *Repo) List(ctx context.Context) ([]*subscription.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]*subscription.Subscription, 0, len(r.subs))
+	for id := range r.subs {
+		sub := r.subs[id]
+		res = append(res, &sub)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
@@ -0,0 +1,89 @@
+package subscription
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+)
+
+const replayPageSize int64 = 200
+
+// Replay returns events after position till time ordered by time, at most limit events,
+// truncated reports whether missed events are more than limit.
+func Replay(repo domain.IEventRepo, f Filter, after Cursor, to time.Time,
+	limit int64) (events domain.Events, truncated bool, err error) {
+	//
+	events = make(domain.Events, 0)
+	for offset := int64(0); ; offset += replayPageSize {
+		page, total, err := repo.FindChainEvents(after.Time, to, f.LayoutID, f.StoreID, f.Key, f.Kind, f.Severity,
+			replayPageSize, offset)
+		if err != nil {
+			return nil, false, errors.WithMessage(err, "find missed events failed")
+		}
+		for _, e := range page {
+			if !after.Covers(e) {
+				events = append(events, e)
+			}
+		}
+		if len(page) == 0 || offset+int64(len(page)) >= total {
+			break
+		}
+		if limit > 0 && int64(len(events)) > limit {
+			break
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return CursorOf(events[i]).Before(CursorOf(events[j])) })
+	if limit > 0 && int64(len(events)) > limit {
+		return events[:limit], true, nil
+	}
+	return events, false, nil
+}
+
+// Stream returns channel with missed events after position and then the live ones,
+// live events replayed already are skipped, channel is closed on cancel or when live events end.
+func Stream(repo domain.IEventRepo, subscriber string, f Filter, after Cursor, limit int64,
+	cancel <-chan struct{}) (<-chan domain.Event, bool, error) {
+	//
+	now := time.Now()
+	missed, truncated, err := Replay(repo, f, after, now, limit)
+	if err != nil {
+		return nil, false, err
+	}
+	live := repo.FindConsumerChainEvents(subscriber, f.LayoutID, f.StoreID, f.Key, f.Kind, f.Severity, now, cancel)
+	out := make(chan domain.Event)
+	go func() {
+		defer close(out)
+		last := after
+		for _, e := range missed {
+			select {
+			case <-cancel:
+				return
+			case out <- e:
+				last = CursorOf(e)
+			}
+		}
+		for {
+			select {
+			case <-cancel:
+				return
+			case e, more := <-live:
+				if !more {
+					return
+				}
+				// changes of the states are sent for the replayed events too
+				if e.Change == nil && last.Covers(e) {
+					continue
+				}
+				select {
+				case <-cancel:
+					return
+				case out <- e:
+				}
+			}
+		}
+	}()
+	return out, truncated, nil
+}
@@ -0,0 +1,172 @@
+// Package subscription contains durable subscriptions to the events,
+// subscription remembers filter and position of the last acknowledged event,
+// reconnected client gets missed events from the repo before the live ones.
+package subscription
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+)
+
+const (
+	cursorSeparator string = "/"
+	maxIDSize       int    = 128
+)
+
+var (
+	// ErrNotFound subscription not exists in the repo.
+	ErrNotFound = errors.New("subscription not found")
+	// ErrInvalidID id of the subscription is empty or too long.
+	ErrInvalidID = errors.New("invalid subscription id")
+)
+
+// Cursor position of the event in the stream, events are ordered by time.
+type Cursor struct {
+	Time time.Time
+	ID   string
+}
+
+// CursorOf returns position of the event.
+func CursorOf(e domain.Event) Cursor {
+	return Cursor{Time: e.EventTime, ID: e.ID}
+}
+
+// ParseCursor parses position of the event by its string form event_time/id.
+func ParseCursor(raw string) (Cursor, error) {
+	parts := strings.SplitN(raw, cursorSeparator, 2)
+	if len(parts) != 2 {
+		return Cursor{}, errors.Errorf("wrong event id %s, need event_time/id", raw)
+	}
+	t, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return Cursor{}, errors.Wrapf(err, "wrong time of the event id %s", raw)
+	}
+	return Cursor{Time: t, ID: parts[1]}, nil
+}
+
+// String returns position as event_time/id, empty for zero cursor.
+func (c Cursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	return c.Time.UTC().Format(time.RFC3339Nano) + cursorSeparator + c.ID
+}
+
+// IsZero reports whether position isn't set.
+func (c Cursor) IsZero() bool {
+	return c.Time.IsZero() && c.ID == ""
+}
+
+// Covers reports whether event is at or before the position,
+// events with the same time and other id aren't covered, they can be delivered twice.
+func (c Cursor) Covers(e domain.Event) bool {
+	return e.EventTime.Before(c.Time) || (e.EventTime.Equal(c.Time) && e.ID == c.ID)
+}
+
+// Before reports whether position is before other one.
+func (c Cursor) Before(other Cursor) bool {
+	if c.Time.Equal(other.Time) {
+		return c.ID < other.ID
+	}
+	return c.Time.Before(other.Time)
+}
+
+// Filter of the events, empty field matches any value.
+type Filter struct {
+	LayoutID string `json:"layout_id,omitempty"`
+	StoreID  string `json:"store_id,omitempty"`
+	Key      string `json:"key,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+	Severity string `json:"severity,omitempty"`
+}
+
+// Subscription named subscription of the client to the events.
+type Subscription struct {
+	ID string `json:"subscription_id"`
+	// Owner user opened the subscription, only owner and admin can use it
+	Owner  string `json:"owner,omitempty"`
+	Filter Filter `json:"filter"`
+	// From events are replayed from this time until the first acknowledgement
+	From time.Time `json:"from"`
+	// Acked position of the last acknowledged event, events after it are replayed on reconnect
+	Acked string `json:"acked,omitempty"`
+	// Delivered position of the last event sent to the client
+	Delivered string    `json:"delivered,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RepoInterface storage of the subscriptions.
+type RepoInterface interface {
+	Get(ctx context.Context, id string) (*Subscription, error)
+	Put(ctx context.Context, sub *Subscription) error
+	// Update changes subscription by fn atomically, if not exists returns ErrNotFound
+	Update(ctx context.Context, id string, fn func(sub *Subscription) error) error
+	Del(ctx context.Context, id string) error
+	List(ctx context.Context) ([]*Subscription, error)
+}
+
+// ValidateID checks id of the subscription passed by client.
+func ValidateID(id string) error {
+	if id == "" || len(id) > maxIDSize {
+		return ErrInvalidID
+	}
+	return nil
+}
+
+// Resume returns position to replay events from, acknowledged event or start time of the subscription.
+func (sub *Subscription) Resume() Cursor {
+	if c, err := ParseCursor(sub.Acked); err == nil {
+		return c
+	}
+	return Cursor{Time: sub.From}
+}
+
+// Open returns subscription by id, unknown one is created with owner, filter and start time.
+func Open(ctx context.Context, repo RepoInterface, id, owner string, f Filter, from time.Time) (*Subscription, error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+	sub, err := repo.Get(ctx, id)
+	if err == nil {
+		return sub, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, errors.WithMessage(err, "get subscription failed")
+	}
+	now := time.Now()
+	sub = &Subscription{ID: id, Owner: owner, Filter: f, From: from, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Put(ctx, sub); err != nil {
+		return nil, errors.WithMessage(err, "put subscription failed")
+	}
+	return sub, nil
+}
+
+// Ack moves acknowledged position of the subscription forward, older positions are ignored.
+func Ack(ctx context.Context, repo RepoInterface, id string, c Cursor) error {
+	return repo.Update(ctx, id, func(sub *Subscription) error {
+		if acked, err := ParseCursor(sub.Acked); err == nil && !acked.Before(c) {
+			return nil
+		}
+		sub.Acked = c.String()
+		if delivered, err := ParseCursor(sub.Delivered); err != nil || delivered.Before(c) {
+			sub.Delivered = sub.Acked
+		}
+		sub.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// Delivered remembers position of the last event sent to the client.
+func Delivered(ctx context.Context, repo RepoInterface, id string, c Cursor) error {
+	return repo.Update(ctx, id, func(sub *Subscription) error {
+		sub.Delivered = c.String()
+		sub.UpdatedAt = time.Now()
+		return nil
+	})
+}
@@ -0,0 +1,165 @@
+package subscription_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/subscription"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/subscription/mem"
+)
+
+var t0 = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func event(id int, sec int) domain.Event {
+	return domain.Event{ID: fmt.Sprint(id), EventTime: t0.Add(time.Duration(sec) * time.Second), LayoutID: "10"}
+}
+
+// fakeEventRepo stored events in descending order and live events sent after subscription.
+type fakeEventRepo struct {
+	stored domain.Events
+	live   domain.Events
+	pages  int
+}
+
+func (r *fakeEventRepo) FindChainEvents(from, to time.Time, layoutID, storeID, key, kind, severity string,
+	limit, offset int64) (domain.Events, int64, error) {
+	r.pages++
+	found := make(domain.Events, 0)
+	for i := len(r.stored) - 1; i >= 0; i-- {
+		e := r.stored[i]
+		if !e.EventTime.Before(from) && !e.EventTime.After(to) {
+			found = append(found, e)
+		}
+	}
+	total := int64(len(found))
+	if offset >= total {
+		return domain.Events{}, total, nil
+	}
+	if offset+limit < total {
+		return found[offset : offset+limit], total, nil
+	}
+	return found[offset:], total, nil
+}
+
+func (r *fakeEventRepo) FindConsumerChainEvents(subscriberID, layoutID, storeID, key, kind, severity string,
+	from time.Time, cancel <-chan struct{}) chan domain.Event {
+	ch := make(chan domain.Event, len(r.live))
+	for _, e := range r.live {
+		ch <- e
+	}
+	close(ch)
+	return ch
+}
+
+func TestCursor(t *testing.T) {
+	e := domain.Event{ID: "a/1", EventTime: time.Date(2021, 6, 1, 19, 0, 0, 5, time.FixedZone("+07", 7*3600))}
+	c, err := subscription.ParseCursor(subscription.CursorOf(e).String())
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if !c.Time.Equal(e.EventTime) || c.ID != e.ID {
+		t.Errorf("ParseCursor() = %+v, want %s/%s", c, e.EventTime, e.ID)
+	}
+	if !c.Covers(e) || c.Covers(domain.Event{ID: "a/2", EventTime: e.EventTime}) {
+		t.Error("cursor must cover only its own event at the same time")
+	}
+	for _, raw := range []string{"", "1", "2021-06-01/1"} {
+		if _, err := subscription.ParseCursor(raw); err == nil {
+			t.Errorf("ParseCursor(%q) must fail", raw)
+		}
+	}
+	if s := (subscription.Cursor{}).String(); s != "" {
+		t.Errorf("zero cursor String() = %q, want empty", s)
+	}
+}
+
+func TestOpenAck(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.New()
+	f := subscription.Filter{LayoutID: "10"}
+	if _, err := subscription.Open(ctx, repo, "", "user-1", f, t0); !errors.Is(err, subscription.ErrInvalidID) {
+		t.Errorf("Open() with empty id error = %v, want %v", err, subscription.ErrInvalidID)
+	}
+	sub, err := subscription.Open(ctx, repo, "notifier", "user-1", f, t0)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if sub.Owner != "user-1" {
+		t.Errorf("owner of the new subscription = %q, want user-1", sub.Owner)
+	}
+	if r := sub.Resume(); !r.Time.Equal(t0) || r.ID != "" {
+		t.Errorf("Resume() of the new subscription = %+v, want from %s", r, t0)
+	}
+	// filter and owner of the existing subscription aren't changed by open
+	if sub, err = subscription.Open(ctx, repo, "notifier", "user-2", subscription.Filter{}, t0.Add(time.Hour)); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if sub.Filter != f || !sub.From.Equal(t0) || sub.Owner != "user-1" {
+		t.Errorf("reopened subscription = %+v, want stored one", sub)
+	}
+
+	c2, c1 := subscription.CursorOf(event(2, 2)), subscription.CursorOf(event(1, 1))
+	for _, c := range []subscription.Cursor{c2, c1} {
+		if err := subscription.Ack(ctx, repo, "notifier", c); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	if sub, err = repo.Get(ctx, "notifier"); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if sub.Acked != c2.String() || sub.Delivered != c2.String() || sub.Resume() != c2 {
+		t.Errorf("subscription after ack = %+v, want acked and delivered %s", sub, c2)
+	}
+	if err := subscription.Ack(ctx, repo, "unknown", c1); !errors.Is(err, subscription.ErrNotFound) {
+		t.Errorf("Ack() of unknown subscription error = %v, want %v", err, subscription.ErrNotFound)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	stored := make(domain.Events, 0)
+	for i := 0; i < 450; i++ {
+		stored = append(stored, event(i, i))
+	}
+	repo := &fakeEventRepo{stored: stored}
+	after := subscription.CursorOf(stored[9])
+	events, truncated, err := subscription.Replay(repo, subscription.Filter{}, after, t0.Add(time.Hour), 0)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if len(events) != 440 || truncated || events[0].ID != "10" || events[439].ID != "449" {
+		t.Fatalf("Replay() = %d events from %s, truncated %v, want 440 from 10", len(events), events[0].ID, truncated)
+	}
+	if repo.pages != 3 {
+		t.Errorf("requested %d pages, want 3", repo.pages)
+	}
+	if _, truncated, _ = subscription.Replay(repo, subscription.Filter{}, after, t0.Add(time.Hour), 100); !truncated {
+		t.Error("Replay() must be truncated by limit")
+	}
+}
+
+func TestStream(t *testing.T) {
+	change := event(1, 1)
+	change.Change = &domain.EventTransition{Action: "acknowledge", Actor: "op", Time: t0}
+	repo := &fakeEventRepo{
+		stored: domain.Events{event(1, 1), event(2, 2), event(3, 3)},
+		// live subscription repeats replayed event, change of the state of the old event isn't skipped
+		live: domain.Events{event(3, 3), change, event(4, 4)},
+	}
+	cancel := make(chan struct{})
+	defer close(cancel)
+	ch, _, err := subscription.Stream(repo, "notifier", subscription.Filter{}, subscription.CursorOf(event(1, 1)), 0, cancel)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	got := ""
+	for e := range ch {
+		got += e.ID
+	}
+	if got != "2314" {
+		t.Errorf("Stream() events = %s, want 2314", got)
+	}
+}
@@ -0,0 +1,195 @@
+// Package disk implements webhook.RepoInterface in the embedded on-disk database.
+package disk
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/boltdb"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/webhook"
+)
+
+var (
+	bucketHooks      = []byte("webhooks")
+	bucketDeliveries = []byte("deliveries")
+)
+
+// Repo on-disk storage of the webhooks.
+type Repo struct {
+	db *bolt.DB
+}
+
+// New builder for Repo, opens or creates database file by path.
+func New(path string) (*Repo, error) {
+	db, err := boltdb.Open(path, bucketHooks, bucketDeliveries)
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{db: db}, nil
+}
+
+// GetHook returns webhook by id, if not exists returns webhook.ErrNotFound.
+func (r *Repo) GetHook(ctx context.Context, id string) (*webhook.Hook, error) {
+	h := &webhook.Hook{}
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return get(tx.Bucket(bucketHooks), id, h)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// PutHook puts or replaces webhook.
+func (r *Repo) PutHook(ctx context.Context, h *webhook.Hook) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(bucketHooks), h.ID, h)
+	})
+}
+
+// DelHook removes webhook with its deliveries, if not exists returns webhook.ErrNotFound.
+func (r *Repo) DelHook(ctx context.Context, id string) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketHooks)
+		if b.Get([]byte(id)) == nil {
+			return webhook.ErrNotFound
+		}
+		if err := b.Delete([]byte(id)); err != nil {
+			return err
+		}
+		return deleteDeliveries(tx.Bucket(bucketDeliveries), func(d *webhook.Delivery) bool { return d.HookID == id })
+	})
+}
+
+// ListHooks returns webhooks of the layout ordered by id, empty layoutID - all webhooks.
+func (r *Repo) ListHooks(ctx context.Context, layoutID string) ([]*webhook.Hook, error) {
+	res := make([]*webhook.Hook, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketHooks).ForEach(func(_, v []byte) error {
+			h := &webhook.Hook{}
+			if err := json.Unmarshal(v, h); err != nil {
+				return errors.Wrap(err, "unmarshal webhook failed")
+			}
+			if layoutID == "" || h.LayoutID == layoutID {
+				res = append(res, h)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetDelivery returns delivery by id, if not exists returns webhook.ErrNotFound.
+func (r *Repo) GetDelivery(ctx context.Context, id string) (*webhook.Delivery, error) {
+	d := &webhook.Delivery{}
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return get(tx.Bucket(bucketDeliveries), id, d)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// PutDelivery puts or replaces delivery.
+func (r *Repo) PutDelivery(ctx context.Context, d *webhook.Delivery) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(bucketDeliveries), d.ID, d)
+	})
+}
+
+// ListDeliveries returns deliveries matching query, the newest first.
+func (r *Repo) ListDeliveries(ctx context.Context, q webhook.DeliveryQuery) ([]*webhook.Delivery, error) {
+	res := make([]*webhook.Delivery, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketDeliveries).ForEach(func(_, v []byte) error {
+			d := &webhook.Delivery{}
+			if err := json.Unmarshal(v, d); err != nil {
+				return errors.Wrap(err, "unmarshal delivery failed")
+			}
+			if q.Match(*d) {
+				res = append(res, d)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	webhook.SortDeliveries(res)
+	return res, nil
+}
+
+// DelDeliveries removes delivered and dead deliveries updated before, returns count of removed.
+func (r *Repo) DelDeliveries(ctx context.Context, before time.Time) (int, error) {
+	n := 0
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		return deleteDeliveries(tx.Bucket(bucketDeliveries), func(d *webhook.Delivery) bool {
+			if d.Finished() && d.UpdatedAt.Before(before) {
+				n++
+				return true
+			}
+			return false
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Close closes database file.
+func (r *Repo) Close() error {
+	return r.db.Close()
+}
+
+// deleteDeliveries removes deliveries matching fn, keys are collected first
+// because bucket can't be changed while iterating.
+func deleteDeliveries(b *bolt.Bucket, fn func(d *webhook.Delivery) bool) error {
+	keys := make([][]byte, 0)
+	err := b.ForEach(func(k, v []byte) error {
+		d := &webhook.Delivery{}
+		if err := json.Unmarshal(v, d); err != nil {
+			return errors.Wrap(err, "unmarshal delivery failed")
+		}
+		if fn(d) {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func get(b *bolt.Bucket, id string, v interface{}) error {
+	bts := b.Get([]byte(id))
+	if bts == nil {
+		return webhook.ErrNotFound
+	}
+	if err := json.Unmarshal(bts, v); err != nil {
+		return errors.Wrap(err, "unmarshal failed")
+	}
+	return nil
+}
+
+func put(b *bolt.Bucket, id string, v interface{}) error {
+	bts, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "marshal failed")
+	}
+	return b.Put([]byte(id), bts)
+}
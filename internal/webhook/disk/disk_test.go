@@ -0,0 +1,82 @@
+package disk_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/webhook"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/webhook/disk"
+)
+
+func TestRepo_HooksDeliveries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhooks")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir error, %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "webhooks.db")
+	ctx := context.Background()
+	r, err := disk.New(path)
+	if err != nil {
+		t.Fatalf("disk.New error, %s", err)
+	}
+	t0 := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, h := range []*webhook.Hook{
+		{ID: "h1", LayoutID: "10", URL: "https://bi.example.com/hook", IsActive: true},
+		{ID: "h2", LayoutID: "20", URL: "https://store.example.com/hook", IsActive: true},
+	} {
+		if err := r.PutHook(ctx, h); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	for i, d := range []*webhook.Delivery{
+		{ID: "d1", HookID: "h1", Status: webhook.StatusDelivered, CreatedAt: t0, UpdatedAt: t0},
+		{ID: "d2", HookID: "h1", Status: webhook.StatusDead, CreatedAt: t0.Add(time.Minute), UpdatedAt: t0.Add(time.Hour)},
+		{ID: "d3", HookID: "h1", Status: webhook.StatusRetrying, CreatedAt: t0.Add(2 * time.Minute), UpdatedAt: t0},
+		{ID: "d4", HookID: "h2", Status: webhook.StatusPending, CreatedAt: t0, UpdatedAt: t0},
+	} {
+		if err := r.PutDelivery(ctx, d); err != nil {
+			t.Fatalf("unexpected error of %d, %s", i, err)
+		}
+	}
+	n, err := r.DelDeliveries(ctx, t0.Add(time.Minute))
+	if err != nil || n != 1 {
+		t.Fatalf("DelDeliveries() = %d, %v, want 1 old delivered", n, err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	reopened, err := disk.New(path)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	defer reopened.Close()
+	ds, err := reopened.ListDeliveries(ctx, webhook.DeliveryQuery{HookID: "h1"})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if len(ds) != 2 || ds[0].ID != "d3" || ds[1].ID != "d2" {
+		t.Errorf("ListDeliveries() = %+v, want d3, d2", ds)
+	}
+	hooks, err := reopened.ListHooks(ctx, "10")
+	if err != nil || len(hooks) != 1 || hooks[0].ID != "h1" {
+		t.Errorf("ListHooks() = %+v, %v, want h1", hooks, err)
+	}
+	if err := reopened.DelHook(ctx, "h1"); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if _, err := reopened.GetDelivery(ctx, "d2"); !errors.Is(err, webhook.ErrNotFound) {
+		t.Errorf("GetDelivery() of the deleted webhook error = %v, want %v", err, webhook.ErrNotFound)
+	}
+	if err := reopened.DelHook(ctx, "h1"); !errors.Is(err, webhook.ErrNotFound) {
+		t.Errorf("DelHook() twice error = %v, want %v", err, webhook.ErrNotFound)
+	}
+	if ds, _ := reopened.ListDeliveries(ctx, webhook.DeliveryQuery{}); len(ds) != 1 || ds[0].ID != "d4" {
+		t.Errorf("ListDeliveries() = %+v, want d4", ds)
+	}
+}
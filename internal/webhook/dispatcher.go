@@ -0,0 +1,318 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/pkg/logging"
+)
+
+const (
+	userAgent       string = "layoutconfig.api"
+	maxErrorBody    int64  = 512
+	defaultWorkers  int    = 1
+	defaultAttempts int    = 1
+)
+
+// Config settings of the deliveries.
+type Config struct {
+	Workers     int
+	QueueSize   int
+	MaxAttempts int
+	// Backoff delay after the first failed attempt, doubled after each next one up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Timeout    time.Duration
+	// Sweep period of the retries lost by the full queue and removing old deliveries
+	Sweep time.Duration
+	// Retention of the delivered and dead deliveries, zero - kept forever
+	Retention time.Duration
+	// AllowedHosts are delivered to even if resolved to the private, loopback or link-local address
+	AllowedHosts []string
+}
+
+// Dispatcher publishes messages to the matching webhooks and delivers them by workers.
+type Dispatcher struct {
+	store  RepoInterface
+	cfg    Config
+	client *http.Client
+	guard  *Guard
+	queue  chan string
+	mu     sync.Mutex
+	// queued ids of the deliveries in the queue or in progress
+	queued map[string]bool
+}
+
+// NewDispatcher builder for Dispatcher.
+func NewDispatcher(store RepoInterface, cfg Config) *Dispatcher {
+	if cfg.Workers < defaultWorkers {
+		cfg.Workers = defaultWorkers
+	}
+	if cfg.MaxAttempts < defaultAttempts {
+		cfg.MaxAttempts = defaultAttempts
+	}
+	if cfg.MaxBackoff < cfg.Backoff {
+		cfg.MaxBackoff = cfg.Backoff
+	}
+	guard := NewGuard(cfg.AllowedHosts)
+	return &Dispatcher{
+		store: store,
+		cfg:   cfg,
+		client: &http.Client{Timeout: cfg.Timeout, Transport: &http.Transport{
+			DialContext:         guard.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			IdleConnTimeout:     90 * time.Second,
+			MaxIdleConnsPerHost: cfg.Workers,
+		}},
+		guard:  guard,
+		queue:  make(chan string, cfg.QueueSize),
+		queued: make(map[string]bool),
+	}
+}
+
+// CheckURL rejects url of the webhook resolved to the not allowed address.
+func (d *Dispatcher) CheckURL(ctx context.Context, rawURL string) error {
+	return d.guard.Check(ctx, rawURL)
+}
+
+// Run starts workers and sweeper, not finished deliveries are resumed, blocks until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	log := logging.FromContext(ctx)
+	log.Debugf("start webhooks dispatcher with %d workers", d.cfg.Workers)
+	defer log.Debug("stop webhooks dispatcher")
+	var wg sync.WaitGroup
+	for i := 0; i < d.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.worker(ctx)
+		}()
+	}
+	d.sweep(ctx)
+	if d.cfg.Sweep > 0 {
+		t := time.NewTicker(d.cfg.Sweep)
+		defer t.Stop()
+	LOOP:
+		for {
+			select {
+			case <-ctx.Done():
+				break LOOP
+			case <-t.C:
+				d.sweep(ctx)
+			}
+		}
+	}
+	<-ctx.Done()
+	wg.Wait()
+}
+
+// Publish adds deliveries of the message to the matching webhooks of the layout.
+func (d *Dispatcher) Publish(ctx context.Context, m Message) error {
+	if m.LayoutID == "" {
+		return nil
+	}
+	hooks, err := d.store.ListHooks(ctx, m.LayoutID)
+	if err != nil {
+		return errors.WithMessage(err, "list webhooks failed")
+	}
+	now := time.Now()
+	for _, h := range hooks {
+		if !h.Match(m) {
+			continue
+		}
+		id, err := randomHex(idBytes)
+		if err != nil {
+			return errors.WithMessage(err, "generate id failed")
+		}
+		dl := &Delivery{ID: id, HookID: h.ID, Message: m, Status: StatusPending, CreatedAt: now, UpdatedAt: now, NextAt: now}
+		if err := d.store.PutDelivery(ctx, dl); err != nil {
+			return errors.WithMessagef(err, "put delivery to %s failed", h.ID)
+		}
+		d.enqueue(dl.ID)
+	}
+	return nil
+}
+
+// Redeliver resets attempts of the dead or delivered delivery and sends it again.
+func (d *Dispatcher) Redeliver(ctx context.Context, id string) (*Delivery, error) {
+	dl, err := d.store.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	dl.Status = StatusPending
+	dl.Attempts = 0
+	dl.LastCode = 0
+	dl.LastError = ""
+	dl.UpdatedAt = now
+	dl.NextAt = now
+	if err := d.store.PutDelivery(ctx, dl); err != nil {
+		return nil, err
+	}
+	d.enqueue(dl.ID)
+	return dl, nil
+}
+
+// enqueue adds delivery to the queue without waiting, delivery of the full queue is taken by sweeper.
+func (d *Dispatcher) enqueue(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.queued[id] {
+		return
+	}
+	select {
+	case d.queue <- id:
+		d.queued[id] = true
+	default:
+	}
+}
+
+func (d *Dispatcher) done(id string) {
+	d.mu.Lock()
+	delete(d.queued, id)
+	d.mu.Unlock()
+}
+
+func (d *Dispatcher) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-d.queue:
+			retry, err := d.attempt(ctx, id)
+			if err != nil {
+				logging.FromContext(ctx).Errorf("webhook delivery %s error, %s", id, err)
+			}
+			d.done(id)
+			if retry > 0 {
+				time.AfterFunc(retry, func() { d.enqueue(id) })
+			}
+		}
+	}
+}
+
+// attempt sends delivery once, returns delay of the next attempt after failure.
+func (d *Dispatcher) attempt(ctx context.Context, id string) (time.Duration, error) {
+	dl, err := d.store.GetDelivery(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	if dl.Finished() || dl.NextAt.After(now) {
+		return 0, nil
+	}
+	h, err := d.store.GetHook(ctx, dl.HookID)
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	dl.Attempts++
+	if h.IsActive {
+		dl.LastCode, err = d.send(ctx, h, dl)
+	} else {
+		dl.LastCode, err = 0, errors.New("webhook is not active")
+	}
+	dl.UpdatedAt = time.Now()
+	retry := time.Duration(0)
+	switch {
+	case err == nil:
+		dl.Status = StatusDelivered
+		dl.LastError = ""
+		dl.NextAt = time.Time{}
+	case dl.Attempts >= d.cfg.MaxAttempts:
+		dl.Status = StatusDead
+		dl.LastError = err.Error()
+		dl.NextAt = time.Time{}
+	default:
+		dl.Status = StatusRetrying
+		dl.LastError = err.Error()
+		retry = d.backoff(dl.Attempts)
+		dl.NextAt = dl.UpdatedAt.Add(retry)
+	}
+	if err := d.store.PutDelivery(ctx, dl); err != nil {
+		return 0, err
+	}
+	return retry, nil
+}
+
+// send posts signed message, returns status code of the response.
+func (d *Dispatcher) send(ctx context.Context, h *Hook, dl *Delivery) (int, error) {
+	body, err := json.Marshal(dl.Message)
+	if err != nil {
+		return 0, errors.Wrap(err, "marshal message failed")
+	}
+	req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	ts := time.Now().Unix()
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderID, dl.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(h.Secret, ts, body))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return resp.StatusCode, fmt.Errorf("status %d %s", resp.StatusCode, bytes.TrimSpace(msg))
+}
+
+// backoff returns delay after the failed attempt.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.Backoff
+	for i := 1; i < attempts && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.cfg.MaxBackoff {
+		delay = d.cfg.MaxBackoff
+	}
+	return delay
+}
+
+// sweep enqueues due deliveries and removes old finished ones.
+func (d *Dispatcher) sweep(ctx context.Context) {
+	log := logging.FromContext(ctx)
+	due, err := d.store.ListDeliveries(ctx, DeliveryQuery{Statuses: []string{StatusPending, StatusRetrying}})
+	if err != nil {
+		log.Errorf("list webhook deliveries error, %s", err)
+	}
+	now := time.Now()
+	// the oldest first
+	for i := len(due) - 1; i >= 0; i-- {
+		if !due[i].NextAt.After(now) {
+			d.enqueue(due[i].ID)
+		}
+	}
+	if d.cfg.Retention <= 0 {
+		return
+	}
+	n, err := d.store.DelDeliveries(ctx, now.Add(-d.cfg.Retention))
+	if err != nil {
+		log.Errorf("remove webhook deliveries error, %s", err)
+	} else if n > 0 {
+		log.Debugf("removed %d webhook deliveries older than %v", n, d.cfg.Retention)
+	}
+}
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrForbiddenHost url of the webhook resolves to not public address.
+var ErrForbiddenHost = errors.New("forbidden webhook host")
+
+const dialTimeout time.Duration = 30 * time.Second
+
+// notPublic networks of the private, shared, "this host" and unique local addresses,
+// loopback, link-local, multicast and unspecified are checked by net.IP methods.
+var notPublic = mustCIDRs("0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "172.16.0.0/12",
+	"192.168.0.0/16", "198.18.0.0/15", "fc00::/7")
+
+// Guard rejects urls and connections to the not public addresses, except allowed hosts.
+type Guard struct {
+	allowed map[string]bool
+}
+
+// NewGuard builder for Guard, hosts are names or ip addresses without port.
+func NewGuard(allowedHosts []string) *Guard {
+	g := &Guard{allowed: make(map[string]bool, len(allowedHosts))}
+	for _, h := range allowedHosts {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			g.allowed[h] = true
+		}
+	}
+	return g
+}
+
+// Check rejects url which host is not allowed and resolves to the not public address,
+// unresolved host is accepted, connection to it is checked on delivery.
+func (g *Guard) Check(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errors.Wrapf(ErrInvalid, "url %q is malformed", rawURL)
+	}
+	host := strings.ToLower(u.Hostname())
+	if g.allowed[host] {
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, a := range addrs {
+		if !publicIP(a.IP) {
+			return errors.Wrapf(ErrForbiddenHost, "host %s resolves to %s", host, a.IP)
+		}
+	}
+	return nil
+}
+
+// DialContext connects to the allowed hosts or to the public addresses only,
+// address is checked after resolve, so redirects and rebinding of the dns are covered.
+func (g *Guard) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout, KeepAlive: dialTimeout}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if !g.allowed[strings.ToLower(host)] {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			ip, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicIP(net.ParseIP(ip)) {
+				return errors.Wrapf(ErrForbiddenHost, "host %s resolves to %s", host, ip)
+			}
+			return nil
+		}
+	}
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// publicIP reports whether ip is global unicast address.
+func publicIP(ip net.IP) bool {
+	if ip == nil || !ip.IsGlobalUnicast() || ip.IsLoopback() {
+		return false
+	}
+	for _, n := range notPublic {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
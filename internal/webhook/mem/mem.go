@@ -0,0 +1,122 @@
+// Package mem implements webhook.RepoInterface in the process memory,
+// webhooks and deliveries are lost on restart of the service.
+package mem
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/internal/webhook"
+)
+
+// Repo memory storage of the webhooks.
+type Repo struct {
+	mu         sync.RWMutex
+	hooks      map[string]webhook.Hook
+	deliveries map[string]webhook.Delivery
+}
+
+// New builder for Repo.
+func New() *Repo {
+	return &Repo{hooks: make(map[string]webhook.Hook), deliveries: make(map[string]webhook.Delivery)}
+}
+
+// GetHook returns webhook by id, if not exists returns webhook.ErrNotFound.
+func (r *Repo) GetHook(ctx context.Context, id string) (*webhook.Hook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	h, ok := r.hooks[id]
+	if !ok {
+		return nil, webhook.ErrNotFound
+	}
+	return &h, nil
+}
+
+// PutHook puts or replaces webhook.
+func (r *Repo) PutHook(ctx context.Context, h *webhook.Hook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks[h.ID] = *h
+	return nil
+}
+
+// DelHook removes webhook with its deliveries, if not exists returns webhook.ErrNotFound.
+func (r *Repo) DelHook(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.hooks[id]; !ok {
+		return webhook.ErrNotFound
+	}
+	delete(r.hooks, id)
+	for did, d := range r.deliveries {
+		if d.HookID == id {
+			delete(r.deliveries, did)
+		}
+	}
+	return nil
+}
+
+// ListHooks returns webhooks of the layout ordered by id, empty layoutID - all webhooks.
+func (r *Repo) ListHooks(ctx context.Context, layoutID string) ([]*webhook.Hook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]*webhook.Hook, 0)
+	for id := range r.hooks {
+		h := r.hooks[id]
+		if layoutID == "" || h.LayoutID == layoutID {
+			res = append(res, &h)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+// GetDelivery returns delivery by id, if not exists returns webhook.ErrNotFound.
+func (r *Repo) GetDelivery(ctx context.Context, id string) (*webhook.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.deliveries[id]
+	if !ok {
+		return nil, webhook.ErrNotFound
+	}
+	return &d, nil
+}
+
+// PutDelivery puts or replaces delivery.
+func (r *Repo) PutDelivery(ctx context.Context, d *webhook.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[d.ID] = *d
+	return nil
+}
+
+// ListDeliveries returns deliveries matching query, the newest first.
+func (r *Repo) ListDeliveries(ctx context.Context, q webhook.DeliveryQuery) ([]*webhook.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]*webhook.Delivery, 0)
+	for id := range r.deliveries {
+		d := r.deliveries[id]
+		if q.Match(d) {
+			res = append(res, &d)
+		}
+	}
+	webhook.SortDeliveries(res)
+	return res, nil
+}
+
+// DelDeliveries removes delivered and dead deliveries updated before, returns count of removed.
+func (r *Repo) DelDeliveries(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for id, d := range r.deliveries {
+		if d.Finished() && d.UpdatedAt.Before(before) {
+			delete(r.deliveries, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,262 @@
+// Package webhook delivers changes of the layouts and events to the http endpoints of the subscribers,
+// payloads are signed by HMAC-SHA256 with secret of the webhook, failed deliveries are retried with backoff
+// and moved to the dead letters after the last attempt.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+)
+
+// kinds of the messages, event for the events and entities of the layout for the changes
+const (
+	KindEvent    string = "event"
+	KindChain    string = "chain"
+	KindStore    string = "store"
+	KindEntrance string = "entrance"
+	KindZone     string = "zone"
+	KindDevice   string = "device"
+	KindSensor   string = "sensor"
+	KindBinding  string = "binding"
+	KindBehavior string = "behavior"
+)
+
+// actions of the changes
+const (
+	ActionCreate string = "create"
+	ActionUpdate string = "update"
+	ActionDelete string = "delete"
+)
+
+// statuses of the deliveries
+const (
+	StatusPending   string = "pending"
+	StatusRetrying  string = "retrying"
+	StatusDelivered string = "delivered"
+	StatusDead      string = "dead"
+)
+
+// headers of the delivery request
+const (
+	HeaderID        string = "X-Webhook-ID"
+	HeaderTimestamp string = "X-Webhook-Timestamp"
+	HeaderSignature string = "X-Webhook-Signature"
+	signaturePrefix string = "sha256="
+)
+
+const (
+	idBytes     int = 12
+	secretBytes int = 24
+)
+
+var (
+	// ErrNotFound webhook or delivery not exists.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalid webhook malformed.
+	ErrInvalid = errors.New("invalid webhook")
+)
+
+var kinds = []string{KindEvent, KindChain, KindStore, KindEntrance, KindZone, KindDevice, KindSensor,
+	KindBinding, KindBehavior}
+
+// Hook webhook of the layout, empty filters match any value,
+// keys and severities filter only events.
+type Hook struct {
+	ID         string     `json:"webhook_id"`
+	LayoutID   string     `json:"layout_id"`
+	URL        string     `json:"url"`
+	Secret     string     `json:"secret,omitempty"`
+	Kinds      []string   `json:"kinds,omitempty"`
+	Keys       []string   `json:"keys,omitempty"`
+	Severities []string   `json:"severities,omitempty"`
+	IsActive   bool       `json:"is_active"`
+	Creator    string     `json:"creator,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ModifiedAt *time.Time `json:"modified_at,omitempty"`
+}
+
+// Message payload of the delivery: change of the entity of the layout or event.
+type Message struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	LayoutID string    `json:"layout_id"`
+	Kind     string    `json:"kind"`
+	// Action create, update, delete of the entity, action of the lifecycle for the event
+	Action   string `json:"action,omitempty"`
+	EntityID string `json:"entity_id,omitempty"`
+	// Data entity of the request for the change
+	Data  json.RawMessage `json:"data,omitempty"`
+	Event *domain.Event   `json:"event,omitempty"`
+}
+
+// Delivery message sent to the webhook with result of the attempts.
+type Delivery struct {
+	ID        string    `json:"delivery_id"`
+	HookID    string    `json:"webhook_id"`
+	Message   Message   `json:"message"`
+	Status    string    `json:"status"`
+	Attempts  int       `json:"attempts"`
+	LastCode  int       `json:"last_code,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	NextAt    time.Time `json:"next_at,omitempty"`
+}
+
+// DeliveryQuery filter of the deliveries, empty fields match any value.
+type DeliveryQuery struct {
+	HookID   string
+	Statuses []string
+}
+
+// RepoInterface storage of the webhooks and their deliveries.
+type RepoInterface interface {
+	GetHook(ctx context.Context, id string) (*Hook, error)
+	PutHook(ctx context.Context, h *Hook) error
+	// DelHook removes webhook with its deliveries
+	DelHook(ctx context.Context, id string) error
+	// ListHooks returns webhooks of the layout ordered by id, empty layoutID - all webhooks
+	ListHooks(ctx context.Context, layoutID string) ([]*Hook, error)
+	GetDelivery(ctx context.Context, id string) (*Delivery, error)
+	PutDelivery(ctx context.Context, d *Delivery) error
+	// ListDeliveries returns deliveries matching query, the newest first
+	ListDeliveries(ctx context.Context, q DeliveryQuery) ([]*Delivery, error)
+	// DelDeliveries removes delivered and dead deliveries updated before, returns count of removed
+	DelDeliveries(ctx context.Context, before time.Time) (int, error)
+}
+
+// NewHook makes active webhook with generated id, secret is generated if empty.
+func NewHook(h Hook, creator string, now time.Time) (*Hook, error) {
+	if err := Validate(h); err != nil {
+		return nil, err
+	}
+	id, err := randomHex(idBytes)
+	if err != nil {
+		return nil, errors.WithMessage(err, "generate id failed")
+	}
+	if h.Secret == "" {
+		if h.Secret, err = randomHex(secretBytes); err != nil {
+			return nil, errors.WithMessage(err, "generate secret failed")
+		}
+	}
+	h.ID = id
+	h.IsActive = true
+	h.Creator = creator
+	h.CreatedAt = now
+	h.ModifiedAt = nil
+	return &h, nil
+}
+
+// Validate checks layout, url and kinds of the webhook.
+func Validate(h Hook) error {
+	if h.LayoutID == "" {
+		return errors.Wrap(ErrInvalid, "layout_id is empty")
+	}
+	u, err := url.Parse(h.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Wrapf(ErrInvalid, "url %q must be absolute http or https url", h.URL)
+	}
+	for _, k := range h.Kinds {
+		if !contains(kinds, k) {
+			return errors.Wrapf(ErrInvalid, "unknown kind %s, allowed %v", k, kinds)
+		}
+	}
+	return nil
+}
+
+// Public returns webhook without secret.
+func (h Hook) Public() Hook {
+	h.Secret = ""
+	return h
+}
+
+// Match reports whether message is delivered to the webhook.
+func (h Hook) Match(m Message) bool {
+	if !h.IsActive || h.LayoutID != m.LayoutID || !matchAny(h.Kinds, m.Kind) {
+		return false
+	}
+	if m.Event == nil {
+		return true
+	}
+	return matchAny(h.Keys, m.Event.Key) && matchAny(h.Severities, m.Event.Severity)
+}
+
+// NewMessage makes message with generated id.
+func NewMessage(m Message, now time.Time) (Message, error) {
+	id, err := randomHex(idBytes)
+	if err != nil {
+		return m, errors.WithMessage(err, "generate id failed")
+	}
+	m.ID = id
+	if m.Time.IsZero() {
+		m.Time = now
+	}
+	return m, nil
+}
+
+// Sign returns signature of the payload sent at timestamp, value of the header X-Webhook-Signature.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature matches the payload, for the receivers of the webhooks.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Finished reports whether delivery won't be attempted anymore.
+func (d Delivery) Finished() bool {
+	return d.Status == StatusDelivered || d.Status == StatusDead
+}
+
+// Match reports whether delivery matches query.
+func (q DeliveryQuery) Match(d Delivery) bool {
+	return (q.HookID == "" || q.HookID == d.HookID) && matchAny(q.Statuses, d.Status)
+}
+
+// SortDeliveries sorts deliveries by created_at descending, the newest first.
+func SortDeliveries(ds []*Delivery) {
+	sort.SliceStable(ds, func(i, j int) bool {
+		if ds[i].CreatedAt.Equal(ds[j].CreatedAt) {
+			return ds[i].ID > ds[j].ID
+		}
+		return ds[i].CreatedAt.After(ds[j].CreatedAt)
+	})
+}
+
+func matchAny(allowed []string, v string) bool {
+	return len(allowed) == 0 || contains(allowed, v)
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,221 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"git.countmax.ru/countmax/layoutconfig.api/domain"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/webhook"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/webhook/mem"
+)
+
+func TestHook_Match(t *testing.T) {
+	h := webhook.Hook{LayoutID: "10", IsActive: true, Kinds: []string{webhook.KindStore, webhook.KindEvent},
+		Keys: []string{"queue.threshold.exceeded"}, Severities: []string{"alarm"}}
+	alarm := &domain.Event{Key: "queue.threshold.exceeded", Severity: "alarm"}
+	tests := []struct {
+		name string
+		m    webhook.Message
+		want bool
+	}{
+		{"store", webhook.Message{LayoutID: "10", Kind: webhook.KindStore, Action: webhook.ActionCreate}, true},
+		{"zone", webhook.Message{LayoutID: "10", Kind: webhook.KindZone, Action: webhook.ActionCreate}, false},
+		{"other_layout", webhook.Message{LayoutID: "20", Kind: webhook.KindStore}, false},
+		{"event", webhook.Message{LayoutID: "10", Kind: webhook.KindEvent, Event: alarm}, true},
+		{"event_info", webhook.Message{LayoutID: "10", Kind: webhook.KindEvent,
+			Event: &domain.Event{Key: "queue.threshold.exceeded", Severity: "info"}}, false},
+		{"event_other_key", webhook.Message{LayoutID: "10", Kind: webhook.KindEvent,
+			Event: &domain.Event{Key: "door.open", Severity: "alarm"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.Match(tt.m); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	h.IsActive = false
+	if h.Match(tests[0].m) {
+		t.Error("not active webhook matched")
+	}
+}
+
+func TestNewHook(t *testing.T) {
+	now := time.Now()
+	for _, h := range []webhook.Hook{
+		{URL: "https://bi.example.com/hook"},
+		{LayoutID: "10", URL: "bi.example.com/hook"},
+		{LayoutID: "10", URL: "https://bi.example.com/hook", Kinds: []string{"screenshot"}},
+	} {
+		if _, err := webhook.NewHook(h, "op", now); !errors.Is(err, webhook.ErrInvalid) {
+			t.Errorf("NewHook(%+v) error = %v, want %v", h, err, webhook.ErrInvalid)
+		}
+	}
+	h, err := webhook.NewHook(webhook.Hook{LayoutID: "10", URL: "https://bi.example.com/hook"}, "op", now)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if h.ID == "" || h.Secret == "" || !h.IsActive || h.Creator != "op" || h.Public().Secret != "" {
+		t.Errorf("NewHook() = %+v, want active webhook with id and secret", h)
+	}
+}
+
+// receiver endpoint of the webhook failing first fails requests.
+type receiver struct {
+	secret string
+	mu     sync.Mutex
+	fails  int
+	got    []webhook.Message
+	bad    int
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	ts, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if !webhook.Verify(rc.secret, ts, body, r.Header.Get(webhook.HeaderSignature)) {
+		rc.bad++
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if rc.fails > 0 {
+		rc.fails--
+		http.Error(w, "try later", http.StatusServiceUnavailable)
+		return
+	}
+	m := webhook.Message{}
+	_ = json.Unmarshal(body, &m)
+	rc.got = append(rc.got, m)
+}
+
+func (rc *receiver) received() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.got)
+}
+
+// waitStatus waits status of the single delivery of the webhook.
+func waitStatus(t *testing.T, store webhook.RepoInterface, hookID, status string) *webhook.Delivery {
+	t.Helper()
+	for i := 0; i < 200; i++ {
+		ds, err := store.ListDeliveries(context.Background(), webhook.DeliveryQuery{HookID: hookID})
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		if len(ds) == 1 && ds[0].Status == status {
+			return ds[0]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("delivery of %s not in status %s", hookID, status)
+	return nil
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rc := &receiver{secret: "s3cret", fails: 2}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	store := mem.New()
+	hook, err := webhook.NewHook(webhook.Hook{LayoutID: "10", URL: srv.URL, Secret: rc.secret,
+		Kinds: []string{webhook.KindStore}}, "op", time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	other, _ := webhook.NewHook(webhook.Hook{LayoutID: "20", URL: srv.URL}, "op", time.Now())
+	for _, h := range []*webhook.Hook{hook, other} {
+		if err := store.PutHook(ctx, h); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	d := webhook.NewDispatcher(store, webhook.Config{Workers: 2, QueueSize: 10, MaxAttempts: 3,
+		Backoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond, Timeout: time.Second,
+		AllowedHosts: []string{"127.0.0.1"}})
+	go d.Run(ctx)
+
+	m, _ := webhook.NewMessage(webhook.Message{LayoutID: "10", Kind: webhook.KindStore, Action: webhook.ActionCreate,
+		EntityID: "s1", Data: json.RawMessage(`{"store_id":"s1"}`)}, time.Now())
+	if err := d.Publish(ctx, m); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	dl := waitStatus(t, store, hook.ID, webhook.StatusDelivered)
+	if dl.Attempts != 3 || dl.LastCode != http.StatusOK || rc.received() != 1 || rc.got[0].EntityID != "s1" {
+		t.Errorf("delivery %+v, received %d, want delivered by third attempt", dl, rc.received())
+	}
+	if ds, _ := store.ListDeliveries(ctx, webhook.DeliveryQuery{HookID: other.ID}); len(ds) != 0 {
+		t.Errorf("webhook of the other layout got %d deliveries", len(ds))
+	}
+
+	// attempts are exhausted, delivery goes to the dead letters
+	if err := store.DelHook(ctx, hook.ID); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	hook.ID = "failing"
+	if err := store.PutHook(ctx, hook); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	rc.mu.Lock()
+	rc.fails = 3
+	rc.mu.Unlock()
+	if err := d.Publish(ctx, m); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	dead := waitStatus(t, store, hook.ID, webhook.StatusDead)
+	if dead.Attempts != 3 || dead.LastCode != http.StatusServiceUnavailable || dead.LastError == "" {
+		t.Errorf("dead delivery %+v, want 3 failed attempts", dead)
+	}
+	if _, err := d.Redeliver(ctx, dead.ID); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	waitStatus(t, store, hook.ID, webhook.StatusDelivered)
+	if rc.received() != 2 || rc.bad != 0 {
+		t.Errorf("received %d with %d bad signatures, want 2 valid", rc.received(), rc.bad)
+	}
+}
+
+func TestDispatcher_forbiddenHost(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	store := mem.New()
+	hook, _ := webhook.NewHook(webhook.Hook{LayoutID: "10", URL: srv.URL}, "op", time.Now())
+	if err := store.PutHook(ctx, hook); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	d := webhook.NewDispatcher(store, webhook.Config{QueueSize: 10, Timeout: time.Second})
+	if err := d.CheckURL(ctx, srv.URL); !errors.Is(err, webhook.ErrForbiddenHost) {
+		t.Errorf("CheckURL(%s) error = %v, want %v", srv.URL, err, webhook.ErrForbiddenHost)
+	}
+	for _, u := range []string{"http://10.1.2.3/hook", "http://169.254.169.254/latest", "http://[::1]:80/",
+		"http://[fd00::1]/", "http://0.0.0.0/"} {
+		if err := d.CheckURL(ctx, u); !errors.Is(err, webhook.ErrForbiddenHost) {
+			t.Errorf("CheckURL(%s) error = %v, want %v", u, err, webhook.ErrForbiddenHost)
+		}
+	}
+	if err := d.CheckURL(ctx, "http://8.8.8.8/hook"); err != nil {
+		t.Errorf("CheckURL of the public address error = %v, want nil", err)
+	}
+	go d.Run(ctx)
+
+	m, _ := webhook.NewMessage(webhook.Message{LayoutID: "10", Kind: webhook.KindStore, Action: webhook.ActionCreate,
+		EntityID: "s1"}, time.Now())
+	if err := d.Publish(ctx, m); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	dead := waitStatus(t, store, hook.ID, webhook.StatusDead)
+	if rc.received() != 0 || dead.LastError == "" {
+		t.Errorf("delivery %+v, received %d, want not connected to the loopback", dead, rc.received())
+	}
+}
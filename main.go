@@ -1,7 +1,12 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"git.countmax.ru/countmax/layoutconfig.api/infra"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/fakecommonapi"
+	"git.countmax.ru/countmax/layoutconfig.api/internal/fakenotify"
 	"github.com/sethvargo/go-signalcontext"
 )
 
@@ -23,6 +28,22 @@ var (
 func main() {
 	ctx, cancel := signalcontext.OnInterrupt()
 	defer cancel()
+	if len(os.Args) > 1 && os.Args[1] == fakecommonapi.Command {
+		if err := fakecommonapi.Main(ctx, os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s failed, %s\n", fakecommonapi.Command, err)
+			cancel()
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == fakenotify.Command {
+		if err := fakenotify.Main(ctx, os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s failed, %s\n", fakenotify.Command, err)
+			cancel()
+			os.Exit(1)
+		}
+		return
+	}
 	serv := infra.NewServer(ctx, version, build, githash)
 	serv.Run()
 	<-ctx.Done()